@@ -13,6 +13,7 @@ type ArrayLiteral struct {
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Span() token.Span     { return al.Token.Span }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 	elements := []string{}
@@ -0,0 +1,29 @@
+package ast
+
+import (
+	"bytes"
+	"zetsu/token"
+)
+
+// AssignExpression stores a new value in an existing binding, it
+// evaluates to the value that was assigned. Operator is either =
+// or one of the compound forms such as +=
+type AssignExpression struct {
+	Token    token.Token // = token or a compound assignment token
+	Name     *Identifier
+	Operator string
+	Value    Expression
+}
+
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) Span() token.Span {
+	return joinSpans(ae.Token.Span, ae.Name, ae.Value)
+}
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString(ae.Name.String())
+	out.WriteString(" " + ae.Operator + " ")
+	out.WriteString(ae.Value.String())
+	return out.String()
+}
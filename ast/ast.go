@@ -2,11 +2,16 @@ package ast
 
 import (
 	"bytes"
+	"reflect"
+	"zetsu/token"
 )
 
 type Node interface {
 	TokenLiteral() string
 	String() string
+	// Span is the stretch of source the node was parsed from, it is
+	// used to point diagnostics at the offending code
+	Span() token.Span
 }
 
 type Statement interface {
@@ -30,6 +35,15 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Span() token.Span {
+	if len(p.Statements) == 0 {
+		return token.Span{}
+	}
+	first := p.Statements[0].Span()
+	last := p.Statements[len(p.Statements)-1].Span()
+	return token.Span{File: first.File, Start: first.Start, End: last.End}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -39,3 +53,32 @@ func (p *Program) String() string {
 
 	return out.String()
 }
+
+// joinSpans widens span so that it also covers every child node
+// that carries a valid position
+func joinSpans(span token.Span, children ...Node) token.Span {
+	for _, child := range children {
+		if child == nil || reflect.ValueOf(child).IsNil() {
+			continue
+		}
+		cs := child.Span()
+		if !cs.Start.IsValid() {
+			continue
+		}
+		if !span.Start.IsValid() || cs.Start.Offset < span.Start.Offset {
+			span.Start = cs.Start
+		}
+		if !span.End.IsValid() || cs.End.Offset > span.End.Offset {
+			span.End = cs.End
+		}
+	}
+	return span
+}
+
+func expressionNodes(exps []Expression) []Node {
+	nodes := make([]Node, len(exps))
+	for i, e := range exps {
+		nodes[i] = e
+	}
+	return nodes
+}
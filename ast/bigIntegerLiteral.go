@@ -0,0 +1,17 @@
+package ast
+
+import (
+	"math/big"
+	"zetsu/token"
+)
+
+// BigIntegerLiteral is an integer literal too large for an int64
+type BigIntegerLiteral struct {
+	Token token.Token
+	Value *big.Int
+}
+
+func (bl *BigIntegerLiteral) expressionNode()      {}
+func (bl *BigIntegerLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BigIntegerLiteral) Span() token.Span     { return bl.Token.Span }
+func (bl *BigIntegerLiteral) String() string       { return bl.Token.Literal }
@@ -12,6 +12,7 @@ type BlockStatement struct {
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Span() token.Span     { return bs.Token.Span }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -11,4 +11,5 @@ type Boolean struct {
 
 func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Span() token.Span     { return b.Token.Span }
 func (b *Boolean) String() string       { return b.Token.Literal }
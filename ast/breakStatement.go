@@ -0,0 +1,12 @@
+package ast
+
+import "zetsu/token"
+
+type BreakStatement struct {
+	Token token.Token // BREAK token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Span() token.Span     { return bs.Token.Span }
+func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
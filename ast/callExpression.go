@@ -14,6 +14,10 @@ type CallExpression struct {
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Span() token.Span {
+	children := append([]Node{ce.Function}, expressionNodes(ce.Arguments)...)
+	return joinSpans(ce.Token.Span, children...)
+}
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 	args := []string{}
@@ -0,0 +1,12 @@
+package ast
+
+import "zetsu/token"
+
+type ContinueStatement struct {
+	Token token.Token // CONTINUE token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Span() token.Span     { return cs.Token.Span }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
@@ -0,0 +1,33 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+	"zetsu/token"
+)
+
+// EnumStatement declares an enum type, `enum Status { Pending, Done }`,
+// and binds it to Name like a const statement would
+type EnumStatement struct {
+	Token    token.Token // ENUM token
+	Name     *Identifier
+	Variants []*Identifier
+}
+
+func (es *EnumStatement) statementNode()       {}
+func (es *EnumStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *EnumStatement) Span() token.Span     { return joinSpans(es.Token.Span, es.Name) }
+func (es *EnumStatement) String() string {
+	var out bytes.Buffer
+	variants := []string{}
+	for _, v := range es.Variants {
+		variants = append(variants, v.String())
+	}
+
+	out.WriteString("enum ")
+	out.WriteString(es.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(variants, ", "))
+	out.WriteString(" }")
+	return out.String()
+}
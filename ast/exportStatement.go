@@ -0,0 +1,15 @@
+package ast
+
+import "zetsu/token"
+
+// ExportStatement makes the binding of a top level let or const statement
+// visible to the modules importing the file it is declared in
+type ExportStatement struct {
+	Token     token.Token // EXPORT token
+	Statement *LetStatement
+}
+
+func (es *ExportStatement) statementNode()       {}
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExportStatement) Span() token.Span     { return es.Token.Span }
+func (es *ExportStatement) String() string       { return "export " + es.Statement.String() }
@@ -0,0 +1,28 @@
+package ast
+
+import (
+	"bytes"
+	"zetsu/token"
+)
+
+// FieldAssignExpression stores a value into a field of a struct, just
+// like AssignExpression it evaluates to that value
+type FieldAssignExpression struct {
+	Token    token.Token // = token or a compound assignment token
+	Target   *MemberExpression
+	Operator string
+	Value    Expression
+}
+
+func (fa *FieldAssignExpression) expressionNode()      {}
+func (fa *FieldAssignExpression) TokenLiteral() string { return fa.Token.Literal }
+func (fa *FieldAssignExpression) Span() token.Span {
+	return joinSpans(fa.Token.Span, fa.Target, fa.Value)
+}
+func (fa *FieldAssignExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString(fa.Target.String())
+	out.WriteString(" " + fa.Operator + " ")
+	out.WriteString(fa.Value.String())
+	return out.String()
+}
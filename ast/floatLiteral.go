@@ -0,0 +1,13 @@
+package ast
+
+import "zetsu/token"
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Span() token.Span     { return fl.Token.Span }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
@@ -0,0 +1,29 @@
+package ast
+
+import (
+	"bytes"
+	"zetsu/token"
+)
+
+// ForInStatement walks over the elements of an array, the
+// characters of a string or the keys of a hash
+type ForInStatement struct {
+	Token    token.Token // FOR token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fi *ForInStatement) statementNode()       {}
+func (fi *ForInStatement) TokenLiteral() string { return fi.Token.Literal }
+func (fi *ForInStatement) Span() token.Span     { return fi.Token.Span }
+func (fi *ForInStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("for ")
+	out.WriteString(fi.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fi.Iterable.String())
+	out.WriteString(" ")
+	out.WriteString(fi.Body.String())
+	return out.String()
+}
@@ -0,0 +1,40 @@
+package ast
+
+import (
+	"bytes"
+	"zetsu/token"
+)
+
+// ForStatement is the C style for loop, every clause of its
+// header is optional
+type ForStatement struct {
+	Token     token.Token // FOR token
+	Init      Statement
+	Condition Expression
+	Post      Expression
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Span() token.Span     { return fs.Token.Span }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("for (")
+	if fs.Init != nil {
+		out.WriteString(fs.Init.String())
+	} else {
+		out.WriteString(";")
+	}
+	out.WriteString(" ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Post != nil {
+		out.WriteString(fs.Post.String())
+	}
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+	return out.String()
+}
@@ -10,21 +10,50 @@ import (
 type FunctionLiteral struct {
 	Token      token.Token
 	Parameters []*Identifier
-	Body       *BlockStatement
-	Name       string
+	// Defaults holds the default value of each parameter, it is nil or
+	// lines up with Parameters and has nil entries for required ones
+	Defaults []Expression
+	// Rest collects the arguments left over after Parameters, it is nil
+	// unless the literal ends with ...name
+	Rest *Identifier
+	Body *BlockStatement
+	Name string
+	// Generator is set for `fn*` literals, calling one returns a
+	// generator that runs the body up to each yield
+	Generator bool
+}
+
+// Default returns the default value of the i-th parameter, nil when
+// the parameter is required
+func (fl *FunctionLiteral) Default(i int) Expression {
+	if i >= len(fl.Defaults) {
+		return nil
+	}
+	return fl.Defaults[i]
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Span() token.Span     { return fl.Token.Span }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 	params := []string{}
 
-	for _, p := range fl.Parameters {
+	for i, p := range fl.Parameters {
+		if def := fl.Default(i); def != nil {
+			params = append(params, p.String()+" = "+def.String())
+			continue
+		}
 		params = append(params, p.String())
 	}
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
+	}
 
 	out.WriteString(fl.TokenLiteral())
+	if fl.Generator {
+		out.WriteString("*")
+	}
 	if fl.Name != "" {
 		out.WriteString(fmt.Sprintf("<%s>", fl.Name))
 	}
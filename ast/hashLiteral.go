@@ -13,6 +13,7 @@ type HashLiteral struct {
 
 func (hl *HashLiteral) expressionNode()      {}
 func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Span() token.Span     { return hl.Token.Span }
 
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
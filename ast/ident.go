@@ -9,4 +9,5 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Span() token.Span     { return i.Token.Span }
 func (i *Identifier) String() string       { return i.Value }
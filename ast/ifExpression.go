@@ -14,6 +14,7 @@ type IfExpression struct {
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Span() token.Span     { return ie.Token.Span }
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("if")
@@ -0,0 +1,22 @@
+package ast
+
+import (
+	"strconv"
+	"zetsu/token"
+)
+
+// ImportStatement brings the exports of another .zeta file into
+// scope under Alias, `import "lib/strings.zeta" as s;`. Path is the
+// path as written, relative to the importing file
+type ImportStatement struct {
+	Token token.Token // IMPORT token
+	Path  string
+	Alias *Identifier
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) Span() token.Span     { return joinSpans(is.Token.Span, is.Alias) }
+func (is *ImportStatement) String() string {
+	return "import " + strconv.Quote(is.Path) + " as " + is.Alias.String() + ";"
+}
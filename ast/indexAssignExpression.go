@@ -0,0 +1,28 @@
+package ast
+
+import (
+	"bytes"
+	"zetsu/token"
+)
+
+// IndexAssignExpression stores a value into an element of an array
+// or a hash, just like AssignExpression it evaluates to that value
+type IndexAssignExpression struct {
+	Token    token.Token // = token or a compound assignment token
+	Target   *IndexExpression
+	Operator string
+	Value    Expression
+}
+
+func (ia *IndexAssignExpression) expressionNode()      {}
+func (ia *IndexAssignExpression) TokenLiteral() string { return ia.Token.Literal }
+func (ia *IndexAssignExpression) Span() token.Span {
+	return joinSpans(ia.Token.Span, ia.Target, ia.Value)
+}
+func (ia *IndexAssignExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString(ia.Target.String())
+	out.WriteString(" " + ia.Operator + " ")
+	out.WriteString(ia.Value.String())
+	return out.String()
+}
@@ -13,6 +13,9 @@ type IndexExpression struct {
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Span() token.Span {
+	return joinSpans(ie.Token.Span, ie.Left, ie.Index)
+}
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 
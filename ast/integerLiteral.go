@@ -9,4 +9,5 @@ type IntegerLiteral struct {
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Span() token.Span     { return il.Token.Span }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
@@ -0,0 +1,35 @@
+package ast
+
+import (
+	"bytes"
+	"zetsu/token"
+)
+
+// InterpolatedString is a string such as "total: ${count * price}",
+// Parts holds its literal pieces as StringLiterals in between the
+// interpolated expressions, in source order
+type InterpolatedString struct {
+	Token token.Token
+	Parts []Expression
+}
+
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) Span() token.Span     { return is.Token.Span }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("\"")
+	for _, part := range is.Parts {
+		if str, ok := part.(*StringLiteral); ok {
+			out.WriteString(str.Value)
+			continue
+		}
+		out.WriteString("${")
+		out.WriteString(part.String())
+		out.WriteString("}")
+	}
+	out.WriteString("\"")
+
+	return out.String()
+}
@@ -13,6 +13,7 @@ type LetStatement struct {
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Span() token.Span     { return ls.Token.Span }
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(ls.TokenLiteral() + " ")
@@ -24,3 +25,7 @@ func (ls *LetStatement) String() string {
 	out.WriteString(";")
 	return out.String()
 }
+
+// IsConst reports whether the statement declares a constant,
+// `const x = 1`, which can't be assigned to or defined again
+func (ls *LetStatement) IsConst() bool { return ls.Token.Type == token.CONST }
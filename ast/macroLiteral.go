@@ -14,6 +14,7 @@ type MacroLiteral struct {
 
 func (ml *MacroLiteral) expressionNode()      {}
 func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MacroLiteral) Span() token.Span     { return ml.Token.Span }
 func (ml *MacroLiteral) String() string {
 	var out bytes.Buffer
 
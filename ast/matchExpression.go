@@ -0,0 +1,47 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+	"zetsu/token"
+)
+
+// MatchExpression evaluates to the body of the first arm whose pattern
+// fits Subject, or to null when none of them does
+type MatchExpression struct {
+	Token   token.Token // MATCH token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+// MatchArm pairs a pattern with the code it runs. Patterns are written
+// as expressions: literals compare equal, identifiers bind the value
+// (_ binds nothing) and array and hash literals take the value apart
+type MatchArm struct {
+	Pattern Expression
+	Body    *BlockStatement
+}
+
+// IsCatchAll reports whether the arm matches any value
+func (ma *MatchArm) IsCatchAll() bool {
+	_, ok := ma.Pattern.(*Identifier)
+	return ok
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) Span() token.Span     { return joinSpans(me.Token.Span, me.Subject) }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+	arms := []string{}
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.Pattern.String()+" => "+arm.Body.String())
+	}
+
+	out.WriteString("match (")
+	out.WriteString(me.Subject.String())
+	out.WriteString(") { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+	return out.String()
+}
@@ -0,0 +1,21 @@
+package ast
+
+import "zetsu/token"
+
+// MemberExpression reaches a name through the dot operator, such as
+// an export of an imported module, `s.trim`, or a field of a struct,
+// `p.x`
+type MemberExpression struct {
+	Token  token.Token // DOT token
+	Object Expression
+	Member *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) Span() token.Span {
+	return joinSpans(me.Token.Span, me.Object, me.Member)
+}
+func (me *MemberExpression) String() string {
+	return me.Object.String() + "." + me.Member.String()
+}
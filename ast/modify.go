@@ -18,6 +18,17 @@ func Modify(node Node, modifier ModifierFunc) Node {
 	case *IndexExpression:
 		node.Left, _ = Modify(node.Left, modifier).(Expression)
 		node.Index, _ = Modify(node.Index, modifier).(Expression)
+	case *SliceExpression:
+		node.Left, _ = Modify(node.Left, modifier).(Expression)
+		if node.Start != nil {
+			node.Start, _ = Modify(node.Start, modifier).(Expression)
+		}
+		if node.End != nil {
+			node.End, _ = Modify(node.End, modifier).(Expression)
+		}
+	case *RangeExpression:
+		node.Start, _ = Modify(node.Start, modifier).(Expression)
+		node.End, _ = Modify(node.End, modifier).(Expression)
 	case *IfExpression:
 		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
 		node.Consequence, _ = Modify(node.Consequence, modifier).(*BlockStatement)
@@ -32,15 +43,80 @@ func Modify(node Node, modifier ModifierFunc) Node {
 		node.ReturnValue, _ = Modify(node.ReturnValue, modifier).(Expression)
 	case *LetStatement:
 		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *ThrowStatement:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *YieldExpression:
+		if node.Value != nil {
+			node.Value, _ = Modify(node.Value, modifier).(Expression)
+		}
+	case *SpawnExpression:
+		node.Call, _ = Modify(node.Call, modifier).(Expression)
+	case *TryExpression:
+		node.Block, _ = Modify(node.Block, modifier).(*BlockStatement)
+		if node.Catch != nil {
+			node.Catch, _ = Modify(node.Catch, modifier).(*BlockStatement)
+		}
+		if node.Finally != nil {
+			node.Finally, _ = Modify(node.Finally, modifier).(*BlockStatement)
+		}
+	case *ExportStatement:
+		node.Statement, _ = Modify(node.Statement, modifier).(*LetStatement)
+	case *MemberExpression:
+		node.Object, _ = Modify(node.Object, modifier).(Expression)
+	case *AssignExpression:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *MatchExpression:
+		node.Subject, _ = Modify(node.Subject, modifier).(Expression)
+		for _, arm := range node.Arms {
+			arm.Body, _ = Modify(arm.Body, modifier).(*BlockStatement)
+		}
+	case *StructLiteral:
+		for i := range node.Values {
+			node.Values[i], _ = Modify(node.Values[i], modifier).(Expression)
+		}
+	case *FieldAssignExpression:
+		node.Target, _ = Modify(node.Target, modifier).(*MemberExpression)
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *IndexAssignExpression:
+		node.Target, _ = Modify(node.Target, modifier).(*IndexExpression)
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *WhileStatement:
+		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *ForStatement:
+		if node.Init != nil {
+			node.Init, _ = Modify(node.Init, modifier).(Statement)
+		}
+		if node.Condition != nil {
+			node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		}
+		if node.Post != nil {
+			node.Post, _ = Modify(node.Post, modifier).(Expression)
+		}
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *ForInStatement:
+		node.Iterable, _ = Modify(node.Iterable, modifier).(Expression)
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
 	case *FunctionLiteral:
 		for i, _ := range node.Parameters {
 			node.Parameters[i], _ = Modify(node.Parameters[i], modifier).(*Identifier)
 		}
+		for i, def := range node.Defaults {
+			if def != nil {
+				node.Defaults[i], _ = Modify(def, modifier).(Expression)
+			}
+		}
 		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *NamedArgument:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
 	case *ArrayLiteral:
 		for i, _ := range node.Elements {
 			node.Elements[i], _ = Modify(node.Elements[i], modifier).(Expression)
 		}
+	case *InterpolatedString:
+		for i := range node.Parts {
+			node.Parts[i], _ = Modify(node.Parts[i], modifier).(Expression)
+		}
 	case *HashLiteral:
 		newPairs := make(map[Expression]Expression)
 		for key, val := range node.Pairs {
@@ -0,0 +1,16 @@
+package ast
+
+import "zetsu/token"
+
+// NamedArgument is a call argument passed by parameter name, as in
+// f(x, b: 3), it only appears in CallExpression.Arguments
+type NamedArgument struct {
+	Token token.Token // the parameter name
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode()      {}
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Literal }
+func (na *NamedArgument) Span() token.Span     { return joinSpans(na.Token.Span, na.Value) }
+func (na *NamedArgument) String() string       { return na.Name.String() + ": " + na.Value.String() }
@@ -0,0 +1,20 @@
+package ast
+
+import "zetsu/token"
+
+// RangeExpression is `start..end`, the integers from Start up to, but
+// not including, End
+type RangeExpression struct {
+	Token token.Token // .. token
+	Start Expression
+	End   Expression
+}
+
+func (re *RangeExpression) expressionNode()      {}
+func (re *RangeExpression) TokenLiteral() string { return re.Token.Literal }
+func (re *RangeExpression) Span() token.Span {
+	return joinSpans(re.Token.Span, re.Start, re.End)
+}
+func (re *RangeExpression) String() string {
+	return "(" + re.Start.String() + ".." + re.End.String() + ")"
+}
@@ -12,6 +12,7 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Span() token.Span     { return rs.Token.Span }
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -0,0 +1,38 @@
+package ast
+
+import (
+	"bytes"
+	"zetsu/token"
+)
+
+// SliceExpression is `a[start:end]`, Start and End are nil when they
+// are left out
+type SliceExpression struct {
+	Token token.Token // [ token
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) Span() token.Span {
+	return joinSpans(se.Token.Span, se.Left, se.Start, se.End)
+}
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
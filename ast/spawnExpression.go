@@ -0,0 +1,16 @@
+package ast
+
+import "zetsu/token"
+
+// SpawnExpression runs Call as a task of its own. Call is usually a
+// call expression, any other expression is a function that is called
+// without arguments
+type SpawnExpression struct {
+	Token token.Token // SPAWN token
+	Call  Expression
+}
+
+func (se *SpawnExpression) expressionNode()      {}
+func (se *SpawnExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpawnExpression) Span() token.Span     { return joinSpans(se.Token.Span, se.Call) }
+func (se *SpawnExpression) String() string       { return "(spawn " + se.Call.String() + ")" }
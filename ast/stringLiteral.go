@@ -11,4 +11,5 @@ type StringLiteral struct {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Span() token.Span     { return sl.Token.Span }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
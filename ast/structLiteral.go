@@ -0,0 +1,33 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+	"zetsu/token"
+)
+
+// StructLiteral builds a value of a struct type, `Point{x: 1, y: 2}`.
+// Fields and Values line up, in the order they were written
+type StructLiteral struct {
+	Token  token.Token // { token
+	Struct Expression
+	Fields []*Identifier
+	Values []Expression
+}
+
+func (sl *StructLiteral) expressionNode()      {}
+func (sl *StructLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StructLiteral) Span() token.Span     { return joinSpans(sl.Token.Span, sl.Struct) }
+func (sl *StructLiteral) String() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for i, f := range sl.Fields {
+		fields = append(fields, f.String()+": "+sl.Values[i].String())
+	}
+
+	out.WriteString(sl.Struct.String())
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+	return out.String()
+}
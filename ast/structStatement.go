@@ -0,0 +1,33 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+	"zetsu/token"
+)
+
+// StructStatement declares a struct type, `struct Point { x, y }`,
+// and binds it to Name like a let statement would
+type StructStatement struct {
+	Token  token.Token // STRUCT token
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *StructStatement) Span() token.Span     { return joinSpans(ss.Token.Span, ss.Name) }
+func (ss *StructStatement) String() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for _, f := range ss.Fields {
+		fields = append(fields, f.String())
+	}
+
+	out.WriteString("struct ")
+	out.WriteString(ss.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(" }")
+	return out.String()
+}
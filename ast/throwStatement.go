@@ -0,0 +1,21 @@
+package ast
+
+import "zetsu/token"
+
+// ThrowStatement raises Value as an error, it unwinds to the
+// closest enclosing try
+type ThrowStatement struct {
+	Token token.Token // THROW token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) Span() token.Span     { return joinSpans(ts.Token.Span, ts.Value) }
+func (ts *ThrowStatement) String() string {
+	out := "throw"
+	if ts.Value != nil {
+		out += " " + ts.Value.String()
+	}
+	return out + ";"
+}
@@ -0,0 +1,39 @@
+package ast
+
+import (
+	"bytes"
+	"zetsu/token"
+)
+
+// TryExpression evaluates to the value of Block, or to the value of
+// Catch when Block raised an error, which is bound to Param. Finally
+// runs on the way out either way, its value is dropped. Catch and
+// Finally are nil when left out, but never both
+type TryExpression struct {
+	Token   token.Token // TRY token
+	Block   *BlockStatement
+	Param   *Identifier
+	Catch   *BlockStatement
+	Finally *BlockStatement
+}
+
+func (te *TryExpression) expressionNode()      {}
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TryExpression) Span() token.Span     { return te.Token.Span }
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("try ")
+	out.WriteString(te.Block.String())
+	if te.Catch != nil {
+		out.WriteString(" catch ")
+		if te.Param != nil {
+			out.WriteString("(" + te.Param.String() + ") ")
+		}
+		out.WriteString(te.Catch.String())
+	}
+	if te.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(te.Finally.String())
+	}
+	return out.String()
+}
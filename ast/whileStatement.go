@@ -0,0 +1,24 @@
+package ast
+
+import (
+	"bytes"
+	"zetsu/token"
+)
+
+type WhileStatement struct {
+	Token     token.Token // WHILE token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) Span() token.Span     { return ws.Token.Span }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("while")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+	return out.String()
+}
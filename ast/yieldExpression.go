@@ -0,0 +1,21 @@
+package ast
+
+import "zetsu/token"
+
+// YieldExpression suspends the generator running it and hands Value
+// to whoever resumed it. It evaluates to the value the generator is
+// resumed with, a bare `yield` yields null
+type YieldExpression struct {
+	Token token.Token // YIELD token
+	Value Expression
+}
+
+func (ye *YieldExpression) expressionNode()      {}
+func (ye *YieldExpression) TokenLiteral() string { return ye.Token.Literal }
+func (ye *YieldExpression) Span() token.Span     { return joinSpans(ye.Token.Span, ye.Value) }
+func (ye *YieldExpression) String() string {
+	if ye.Value == nil {
+		return "(yield)"
+	}
+	return "(yield " + ye.Value.String() + ")"
+}
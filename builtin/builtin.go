@@ -24,6 +24,14 @@ var Builtins = []struct {
 	{"rest", &BuiltIn{Rest}},
 	{"push", &BuiltIn{Push}},
 	{"pop", &BuiltIn{Pop}},
+	{"range", &BuiltIn{Range}},
+	{"next", &BuiltIn{Next}},
+	{"channel", &BuiltIn{Channel}},
+	{"send", &BuiltIn{Send}},
+	{"receive", &BuiltIn{Receive}},
+	{"close", &BuiltIn{Close}},
+	{"select", &BuiltIn{Select}},
+	{"wait", &BuiltIn{Wait}},
 }
 
 func GetBuiltinByName(name string) *BuiltIn {
@@ -0,0 +1,22 @@
+package builtin
+
+import "zetsu/object"
+
+// Channel makes a channel, `channel()` is unbuffered and `channel(n)`
+// buffers n values
+func Channel(args ...object.Object) object.Object {
+	if len(args) > 1 {
+		return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+	if len(args) == 0 {
+		return object.NewChannel(0)
+	}
+	size, ok := args[0].(*object.Integer)
+	if !ok {
+		return newError("argument to `channel` must be INTEGER, got %s", args[0].Type())
+	}
+	if size.Value < 0 {
+		return newError("channel size cannot be negative")
+	}
+	return object.NewChannel(int(size.Value))
+}
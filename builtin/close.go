@@ -0,0 +1,18 @@
+package builtin
+
+import "zetsu/object"
+
+// Close closes a channel, no more values can be sent on it
+func Close(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	ch, ok := args[0].(*object.Channel)
+	if !ok {
+		return newError("argument to `close` must be CHANNEL, got %s", args[0].Type())
+	}
+	if err := ch.Close(); err != nil {
+		return newError("%s", err)
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package builtin
 
-import "zetsu/object"
+import (
+	"unicode/utf8"
+	"zetsu/object"
+)
 
 func Len(args ...object.Object) object.Object {
 	if len(args) != 1 {
@@ -10,7 +13,9 @@ func Len(args ...object.Object) object.Object {
 	case *object.Array:
 		return &object.Integer{Value: int64(len(arg.Elements))}
 	case *object.String:
-		return &object.Integer{Value: int64(len(arg.Value))}
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+	case *object.Range:
+		return &object.Integer{Value: arg.Len()}
 	default:
 		return newError("argument to `len` not supported, got %s", args[0].Type())
 	}
@@ -0,0 +1,13 @@
+package builtin
+
+import "zetsu/object"
+
+// Next resumes a generator, `next(gen)` or `next(gen, value)`. The vm
+// resumes generators itself since it has to run their frames, so the
+// calls that get here are the ones next doesn't support
+func Next(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	return newError("argument to `next` must be GENERATOR, got %s", args[0].Type())
+}
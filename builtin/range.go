@@ -0,0 +1,27 @@
+package builtin
+
+import "zetsu/object"
+
+// Range builds a range, range(end) counts from 0, range(start, end)
+// and range(start, end, step) work like start..end counting by step
+func Range(args ...object.Object) object.Object {
+	start, step := object.Object(&object.Integer{Value: 0}), object.Object(&object.Integer{Value: 1})
+	var end object.Object
+
+	switch len(args) {
+	case 1:
+		end = args[0]
+	case 2:
+		start, end = args[0], args[1]
+	case 3:
+		start, end, step = args[0], args[1], args[2]
+	default:
+		return newError("wrong number of arguments. got=%d, want=1 to 3", len(args))
+	}
+
+	rng, err := object.NewRange(start, end, step)
+	if err != nil {
+		return newError("%s", err)
+	}
+	return rng
+}
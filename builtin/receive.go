@@ -0,0 +1,23 @@
+package builtin
+
+import "zetsu/object"
+
+// Receive waits for a value sent on a channel, it evaluates to null
+// once the channel is closed and drained
+func Receive(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	ch, ok := args[0].(*object.Channel)
+	if !ok {
+		return newError("argument to `receive` must be CHANNEL, got %s", args[0].Type())
+	}
+	obj, ok, err := ch.Receive()
+	if err != nil {
+		return newError("%s", err)
+	}
+	if !ok {
+		return nil
+	}
+	return obj
+}
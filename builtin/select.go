@@ -0,0 +1,40 @@
+package builtin
+
+import (
+	"zetsu/global"
+	"zetsu/object"
+)
+
+// Select receives from whichever of an array of channels is ready
+// first, it evaluates to [index, value] where value is null when the
+// channel at index was closed
+func Select(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `select` must be ARRAY, got %s", args[0].Type())
+	}
+	if len(arr.Elements) == 0 {
+		return newError("select needs at least one channel")
+	}
+
+	chans := make([]*object.Channel, len(arr.Elements))
+	for i, el := range arr.Elements {
+		ch, ok := el.(*object.Channel)
+		if !ok {
+			return newError("select can only wait on CHANNEL, got %s", el.Type())
+		}
+		chans[i] = ch
+	}
+
+	index, obj, ok, err := object.Select(chans)
+	if err != nil {
+		return newError("%s", err)
+	}
+	if !ok {
+		obj = global.Null
+	}
+	return &object.Array{Elements: []object.Object{&object.Integer{Value: int64(index)}, obj}}
+}
@@ -0,0 +1,19 @@
+package builtin
+
+import "zetsu/object"
+
+// Send sends a value on a channel, `send(ch, value)` waits until the
+// value is received or buffered
+func Send(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	ch, ok := args[0].(*object.Channel)
+	if !ok {
+		return newError("argument to `send` must be CHANNEL, got %s", args[0].Type())
+	}
+	if err := ch.Send(args[1]); err != nil {
+		return newError("%s", err)
+	}
+	return nil
+}
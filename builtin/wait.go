@@ -0,0 +1,20 @@
+package builtin
+
+import "zetsu/object"
+
+// Wait blocks until a spawned task is done and evaluates to what the
+// spawned call returned, a task that failed raises its error again
+func Wait(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	task, ok := args[0].(*object.Task)
+	if !ok {
+		return newError("argument to `wait` must be TASK, got %s", args[0].Type())
+	}
+	result, err := task.Wait()
+	if err != nil {
+		return newError("%s", err)
+	}
+	return result
+}
@@ -33,17 +33,25 @@ func CompileCode(src, goos, goarch string, release bool) {
 	}
 	dstpath := strings.TrimSuffix(srcpath, global.ZetsuSourceCodeFileExtention)
 
-	if err, errtype, errors := generator.Generate(srcpath, dstpath, goos, goarch, release); err != nil {
+	err, errtype, errors := generator.Generate(srcpath, dstpath, goos, goarch, release)
+	if err != nil {
 		switch errtype {
 		case errrs.ERROR:
 			fmt.Println(err)
 		case errrs.PARSER_ERROR:
-			errrs.PrintParseErrors(os.Stdout, errors)
+			errrs.PrintParseErrors(os.Stdout, loadSource(src, srcpath), errors)
+		case errrs.IMPORT_ERROR:
+			errrs.PrintImportError(os.Stdout, loadSource(src, srcpath), err)
+		case errrs.MACRO_ERROR:
+			errrs.PrintMacroError(os.Stdout, loadSource(src, srcpath), err)
 		case errrs.COMPILER_ERROR:
-			errrs.PrintCompilerError(os.Stdout, err.Error())
+			errrs.PrintCompilerError(os.Stdout, loadSource(src, srcpath), err)
 		}
 		return
 	}
+	if len(errors) > 0 {
+		errrs.PrintWarnings(os.Stdout, loadSource(src, srcpath), errors)
+	}
 
 	fmt.Println("Compiled in:", time.Since(start))
 }
@@ -60,7 +68,19 @@ func RunCode(src string) {
 		case errrs.ERROR:
 			fmt.Println(err)
 		case errrs.VM_ERROR:
-			errrs.PrintMachineError(os.Stdout, err.Error())
+			srcpath = strings.TrimSuffix(srcpath, global.ZetsuByteCodeCompiledFileExtension) + global.ZetsuSourceCodeFileExtention
+			name := strings.TrimSuffix(src, global.ZetsuByteCodeCompiledFileExtension) + global.ZetsuSourceCodeFileExtention
+			errrs.PrintMachineError(os.Stdout, loadSource(name, srcpath), err)
 		}
 	}
 }
+
+// loadSource reads the source code diagnostics are printed against,
+// when it can't be read only the locations are reported
+func loadSource(name, path string) errrs.Source {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errrs.Source{Name: name}
+	}
+	return errrs.Source{Name: name, Text: string(data)}
+}
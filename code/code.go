@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"math"
+	"sort"
 	"zetsu/security"
+	"zetsu/token"
 )
 
 type Instructions []byte
@@ -41,6 +44,48 @@ const (
 	OpClosure
 	OpGetFree
 	OpCurrentClosure
+	OpGetIter
+	OpIterNext
+	OpSetFree
+	OpSetIndex
+	OpMod
+	OpGreaterEqual
+	OpConcat
+	OpTry
+	OpEndTry
+	OpThrow
+	OpStruct
+	OpGetField
+	OpSetField
+	OpMatchValue
+	OpMatchArray
+	OpMatchHash
+	OpMatchKey
+	OpCallNamed
+	OpSkipDefault
+	OpTailCall
+	OpSlice
+	OpRange
+	OpYield
+	OpSpawn
+	OpCaptureLocal
+	OpCaptureFree
+	OpCloseUpvalues
+	// OpNewCell, OpGetCell and OpSetCell work on the globals that hold
+	// a cell, see compiler.CellScope. OpNewCell puts a fresh one there
+	OpNewCell
+	OpGetCell
+	OpSetCell
+	// OpWide makes every operand of the instruction that follows it
+	// WideWidth bytes wide, Make adds it when an operand doesn't fit
+	OpWide
+)
+
+// WideWidth is the width of the operands of an instruction prefixed by
+// OpWide, MaxOperand is the largest operand it can hold
+const (
+	WideWidth  = 4
+	MaxOperand = math.MaxUint32
 )
 
 type Definition struct {
@@ -79,6 +124,37 @@ var definitions = map[Opcode]*Definition{
 	OpClosure:        {"OpClosure", []int{2, 1}},
 	OpGetFree:        {"OpGetFree", []int{1}},
 	OpCurrentClosure: {"OpCurrentClosure", []int{}},
+	OpGetIter:        {"OpGetIter", []int{}},
+	OpIterNext:       {"OpIterNext", []int{2}},
+	OpSetFree:        {"OpSetFree", []int{1}},
+	OpSetIndex:       {"OpSetIndex", []int{1}},
+	OpMod:            {"OpMod", []int{}},
+	OpGreaterEqual:   {"OpGreaterEqual", []int{}},
+	OpConcat:         {"OpConcat", []int{2}},
+	OpTry:            {"OpTry", []int{2}},
+	OpEndTry:         {"OpEndTry", []int{}},
+	OpThrow:          {"OpThrow", []int{}},
+	OpStruct:         {"OpStruct", []int{2}},
+	OpGetField:       {"OpGetField", []int{}},
+	OpSetField:       {"OpSetField", []int{1}},
+	OpMatchValue:     {"OpMatchValue", []int{}},
+	OpMatchArray:     {"OpMatchArray", []int{2}},
+	OpMatchHash:      {"OpMatchHash", []int{}},
+	OpMatchKey:       {"OpMatchKey", []int{}},
+	OpCallNamed:      {"OpCallNamed", []int{1, 2}},
+	OpSkipDefault:    {"OpSkipDefault", []int{1, 2}},
+	OpTailCall:       {"OpTailCall", []int{1}},
+	OpSlice:          {"OpSlice", []int{}},
+	OpRange:          {"OpRange", []int{}},
+	OpYield:          {"OpYield", []int{}},
+	OpSpawn:          {"OpSpawn", []int{1}},
+	OpCaptureLocal:   {"OpCaptureLocal", []int{1}},
+	OpCaptureFree:    {"OpCaptureFree", []int{1}},
+	OpCloseUpvalues:  {"OpCloseUpvalues", []int{1}},
+	OpNewCell:        {"OpNewCell", []int{2}},
+	OpGetCell:        {"OpGetCell", []int{2}},
+	OpSetCell:        {"OpSetCell", []int{2}},
+	OpWide:           {"OpWide", []int{}},
 }
 
 func Lookup(op byte) (*Definition, error) {
@@ -89,30 +165,42 @@ func Lookup(op byte) (*Definition, error) {
 	return def, nil
 }
 
+// Make encodes an instruction, when an operand doesn't fit the width
+// op defines for it the instruction is prefixed by OpWide. Operands
+// past MaxOperand can't be encoded, the compiler refuses them
 func Make(op Opcode, operands ...int) []byte {
 	def, ok := definitions[op]
 	if !ok {
 		return []byte{}
 	}
 
-	instLen := 1
+	widths := def.OperandWidths
+	prefix := 0
+	if !fits(widths, operands) {
+		widths = wideWidths(def)
+		prefix = 1
+	}
 
-	for _, w := range def.OperandWidths {
+	instLen := prefix + 1
+	for _, w := range widths {
 		instLen += w
 	}
 
 	inst := make([]byte, instLen)
-	inst[0] = byte(op)
+	inst[0] = byte(OpWide)
+	inst[prefix] = byte(op)
 
-	offset := 1
+	offset := prefix + 1
 
 	for i, o := range operands {
-		width := def.OperandWidths[i]
+		width := widths[i]
 		switch width {
 		case 1:
 			inst[offset] = byte(o)
 		case 2:
 			binary.BigEndian.PutUint16(inst[offset:], uint16(o))
+		case 4:
+			binary.BigEndian.PutUint32(inst[offset:], uint32(o))
 		}
 		offset += width
 	}
@@ -120,19 +208,79 @@ func Make(op Opcode, operands ...int) []byte {
 	return inst
 }
 
+func fits(widths []int, operands []int) bool {
+	for i, o := range operands {
+		if o < 0 || o >= 1<<(8*widths[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func wideWidths(def *Definition) []int {
+	widths := make([]int, len(def.OperandWidths))
+	for i := range widths {
+		widths[i] = WideWidth
+	}
+	return widths
+}
+
+// Decode reads the instruction at the start of ins, OpWide prefixed
+// ones included. It returns the opcode, the operands and the length of
+// the whole instruction
+func Decode(ins Instructions) (Opcode, []int, int) {
+	def, err := Lookup(ins[0])
+	if err != nil {
+		return Opcode(ins[0]), nil, 1
+	}
+	if Opcode(ins[0]) != OpWide {
+		operands, read := ReadOperands(def, ins[1:])
+		return Opcode(ins[0]), operands, 1 + read
+	}
+
+	def, err = Lookup(ins[1])
+	if err != nil {
+		return Opcode(ins[1]), nil, 2
+	}
+	operands, read := ReadOperands(&Definition{Name: def.Name, OperandWidths: wideWidths(def)}, ins[2:])
+	return Opcode(ins[1]), operands, 2 + read
+}
+
+// jumpOperands holds the index of the operand of each jump that is the
+// position it jumps to
+var jumpOperands = map[Opcode]int{
+	OpJump:        0,
+	OpJumpFalse:   0,
+	OpTry:         0,
+	OpIterNext:    0,
+	OpSkipDefault: 1,
+}
+
+// JumpOperand returns the index of the operand of op that holds the
+// position it jumps to, ok is false when op doesn't jump
+func JumpOperand(op Opcode) (index int, ok bool) {
+	index, ok = jumpOperands[op]
+	return index, ok
+}
+
 // String function is only used for internal code/compiler testing purposes
 func (ins Instructions) String() string {
 	var out bytes.Buffer
 	i := 0
 	for i < len(ins) {
-		def, err := Lookup(ins[i])
+		op, operands, read := Decode(ins[i:])
+		def, err := Lookup(byte(op))
 		if err != nil {
 			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i += read
 			continue
 		}
-		operands, read := ReadOperands(def, ins[i+1:])
-		fmt.Fprintf(&out, "%04d %s\n", i, ins.fmtInstruction(def, operands))
-		i += 1 + read
+		prefix := ""
+		if Opcode(ins[i]) == OpWide {
+			prefix = "OpWide "
+		}
+		fmt.Fprintf(&out, "%04d %s%s\n", i, prefix, ins.fmtInstruction(def, operands))
+		i += read
 	}
 
 	return out.String()
@@ -164,9 +312,11 @@ func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
 	for i, width := range def.OperandWidths {
 		switch width {
 		case 1:
-			operands[i] = int(uint8(ins[0]))
+			operands[i] = int(uint8(ins[offset]))
 		case 2:
-			operands[i] = int(binary.BigEndian.Uint16(ins))
+			operands[i] = int(binary.BigEndian.Uint16(ins[offset:]))
+		case 4:
+			operands[i] = int(binary.BigEndian.Uint32(ins[offset:]))
 		}
 		offset += width
 	}
@@ -175,14 +325,31 @@ func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
 }
 
 func ReadUint16(ins Instructions, length int) uint16 {
-	ins = security.XOR(ins, length)
-	index := binary.BigEndian.Uint16(ins)
-	ins = security.XOR(ins, length)
-	return index
+	return uint16(security.XOROne(ins[0], length))<<8 | uint16(security.XOROne(ins[1], length))
+}
+func ReadUint32(ins Instructions, length int) uint32 {
+	return uint32(ReadUint16(ins, length))<<16 | uint32(ReadUint16(ins[2:], length))
 }
 func ReadUint8(ins Instructions, length int) uint8 {
-	ins[0] = security.XOROne(ins[0], length)
-	index := uint8(ins[0])
-	ins[0] = security.XOROne(ins[0], length)
-	return index
+	return uint8(security.XOROne(ins[0], length))
+}
+
+// SourceMark records that the instructions starting at Offset were
+// compiled from the source code covered by Span
+type SourceMark struct {
+	Offset int
+	Span   token.Span
+}
+
+// SourceMap maps instruction offsets back to source code, marks are
+// kept in ascending Offset order
+type SourceMap []SourceMark
+
+// Lookup returns the span of the instruction found at offset
+func (sm SourceMap) Lookup(offset int) (token.Span, bool) {
+	i := sort.Search(len(sm), func(i int) bool { return sm[i].Offset > offset })
+	if i == 0 {
+		return token.Span{}, false
+	}
+	return sm[i-1].Span, true
 }
@@ -1,8 +1,10 @@
 package code
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
+	"zetsu/security"
 )
 
 func TestMake(t *testing.T) {
@@ -15,6 +17,13 @@ func TestMake(t *testing.T) {
 		{OpAdd, []int{}, []byte{byte(OpAdd)}},
 		{OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
 		{OpClosure, []int{65534, 255}, []byte{byte(OpClosure), 255, 254, 255}},
+		{OpConcat, []int{3}, []byte{byte(OpConcat), 0, 3}},
+		{OpTry, []int{300}, []byte{byte(OpTry), 1, 44}},
+		{OpThrow, []int{}, []byte{byte(OpThrow)}},
+		{OpCallNamed, []int{3, 258}, []byte{byte(OpCallNamed), 3, 1, 2}},
+		{OpGetLocal, []int{256}, []byte{byte(OpWide), byte(OpGetLocal), 0, 0, 1, 0}},
+		{OpConstant, []int{65536}, []byte{byte(OpWide), byte(OpConstant), 0, 1, 0, 0}},
+		{OpClosure, []int{3, 300}, []byte{byte(OpWide), byte(OpClosure), 0, 0, 0, 3, 0, 0, 1, 44}},
 	}
 
 	for _, tt := range tests {
@@ -38,6 +47,8 @@ func TestInstructionsString(t *testing.T) {
 		Make(OpConstant, 2),
 		Make(OpConstant, 65535),
 		Make(OpClosure, 65535, 255),
+		Make(OpJump, 70000),
+		Make(OpAdd),
 	}
 
 	ex1 := "0000 OpAdd\n"
@@ -45,8 +56,10 @@ func TestInstructionsString(t *testing.T) {
 	ex3 := "0003 OpConstant 2\n"
 	ex4 := "0006 OpConstant 65535\n"
 	ex5 := "0009 OpClosure 65535 255\n"
+	ex6 := "0013 OpWide OpJump 70000\n"
+	ex7 := "0019 OpAdd\n"
 
-	expected := ex1 + ex2 + ex3 + ex4 + ex5
+	expected := ex1 + ex2 + ex3 + ex4 + ex5 + ex6 + ex7
 
 	concatted := Instructions{}
 
@@ -61,6 +74,21 @@ func TestInstructionsString(t *testing.T) {
 	}
 }
 
+func TestReadUint(t *testing.T) {
+	ins := security.XOR(Instructions{1, 2, 3}, 7)
+	want := append(Instructions{}, ins...)
+
+	if got := ReadUint16(ins, 7); got != 258 {
+		t.Errorf("ReadUint16 wrong. want = %d, got = %d", 258, got)
+	}
+	if got := ReadUint8(ins[2:], 7); got != 3 {
+		t.Errorf("ReadUint8 wrong. want = %d, got = %d", 3, got)
+	}
+	if !bytes.Equal(ins, want) {
+		t.Errorf("reading operands changed the instructions. want = %v, got = %v", want, ins)
+	}
+}
+
 func TestReadOperands(t *testing.T) {
 	tests := []struct {
 		op        Opcode
@@ -92,3 +120,32 @@ func TestReadOperands(t *testing.T) {
 		}
 	}
 }
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		length   int
+	}{
+		{OpAdd, []int{}, 1},
+		{OpClosure, []int{65535, 3}, 4},
+		{OpSkipDefault, []int{2, 300}, 4},
+		{OpSkipDefault, []int{2, 70000}, 10},
+		{OpCall, []int{256}, 6},
+	}
+
+	for _, tt := range tests {
+		op, operands, length := Decode(Make(tt.op, tt.operands...))
+		if op != tt.op {
+			t.Errorf("wrong opcode. want=%d, got=%d", tt.op, op)
+		}
+		if length != tt.length {
+			t.Errorf("wrong length for %v. want=%d, got=%d", tt.operands, tt.length, length)
+		}
+		for i, want := range tt.operands {
+			if operands[i] != want {
+				t.Errorf("operand wrong. want=%d, got=%d, number=%d", want, operands[i], i)
+			}
+		}
+	}
+}
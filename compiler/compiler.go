@@ -6,19 +6,38 @@ import (
 	"zetsu/ast"
 	"zetsu/builtin"
 	"zetsu/code"
+	"zetsu/errrs"
 	"zetsu/object"
+	"zetsu/token"
 )
 
+// compoundOperators maps compound assignments to the opcode that
+// combines the current value with the assigned one
+var compoundOperators = map[string]code.Opcode{
+	"+=": code.OpAdd,
+	"-=": code.OpSub,
+	"*=": code.OpMul,
+	"/=": code.OpDiv,
+	"%=": code.OpMod,
+}
+
 type Compiler struct {
 	constants   []object.Object
 	symbolTable *SymbolTable
 	scopes      []CompilationScope
 	scopeIndex  int
+	span        token.Span                   // source of the node being compiled
+	modules     map[string]map[string]Symbol // exports of compiled modules by path
+	imports     map[string]string            // see Module.Imports
+	exports     map[string]Symbol            // exports of the module being compiled
+	warnings    []*errrs.Diagnostic
+	err         error // see checkOperands
 }
 
 type ByteCode struct {
 	Instructions code.Instructions
 	Constants    []object.Object
+	SourceMap    code.SourceMap
 }
 
 type EmittedInstruction struct {
@@ -30,6 +49,19 @@ type CompilationScope struct {
 	instructions    code.Instructions
 	lastInstruction EmittedInstruction
 	prevInstruction EmittedInstruction
+	sourceMap       code.SourceMap
+	loops           []*Loop
+	tries           []*ast.BlockStatement // finally blocks of the tries being compiled, nil when absent
+	generator       bool                  // compiling the body of a fn*, where yield is allowed
+	farJumps        map[int]int           // targets of the jumps widenJumps has to widen, by position
+}
+
+// Loop collects the jumps emitted by break and continue, they are
+// patched once the loop has been compiled and its bounds are known
+type Loop struct {
+	breaks    []int
+	continues []int
+	tries     int // number of tries around the loop
 }
 
 func New() *Compiler {
@@ -49,9 +81,19 @@ func New() *Compiler {
 		symbolTable: table,
 		scopes:      []CompilationScope{mainScope},
 		scopeIndex:  0,
+		modules:     map[string]map[string]Symbol{},
+		exports:     map[string]Symbol{},
 	}
 }
 
+// Warnings lists what looked suspicious in the code compiled so far,
+// none of it stops the program from running
+func (c *Compiler) Warnings() []*errrs.Diagnostic { return c.warnings }
+
+func (c *Compiler) warnf(span token.Span, format string, a ...interface{}) {
+	c.warnings = append(c.warnings, errrs.Errorf(span, format, a...))
+}
+
 func NewWithState(st *SymbolTable, constants []object.Object) *Compiler {
 	compiler := New()
 	compiler.symbolTable = st
@@ -60,12 +102,23 @@ func NewWithState(st *SymbolTable, constants []object.Object) *Compiler {
 }
 
 func (c *Compiler) Compile(node ast.Node) error {
+	if node != nil {
+		if span := node.Span(); span.Start.IsValid() {
+			outer := c.span
+			c.span = span
+			defer func() { c.span = outer }()
+		}
+	}
+
 	switch node := node.(type) {
 	case *ast.Program:
 		for _, s := range node.Statements {
 			if err := c.Compile(s); err != nil {
 				return err
 			}
+			if c.err != nil {
+				return c.err
+			}
 		}
 	case *ast.BlockStatement:
 		for _, s := range node.Statements {
@@ -88,17 +141,24 @@ func (c *Compiler) Compile(node ast.Node) error {
 		case "!":
 			c.emit(code.OpBang)
 		default:
-			return fmt.Errorf("unknown operator %s", node.Operator)
+			return errrs.Errorf(node.Span(), "unknown operator %s", node.Operator)
 		}
 	case *ast.InfixExpression:
-		if node.Operator == "<" {
+		switch node.Operator {
+		case "&&", "||":
+			return c.compileLogical(node)
+		case "<", "<=":
 			if err := c.Compile(node.Right); err != nil {
 				return err
 			}
 			if err := c.Compile(node.Left); err != nil {
 				return err
 			}
-			c.emit(code.OpGreater)
+			if node.Operator == "<" {
+				c.emit(code.OpGreater)
+			} else {
+				c.emit(code.OpGreaterEqual)
+			}
 			return nil
 		}
 		if err := c.Compile(node.Left); err != nil {
@@ -116,14 +176,18 @@ func (c *Compiler) Compile(node ast.Node) error {
 			c.emit(code.OpMul)
 		case "/":
 			c.emit(code.OpDiv)
+		case "%":
+			c.emit(code.OpMod)
 		case ">":
 			c.emit(code.OpGreater)
+		case ">=":
+			c.emit(code.OpGreaterEqual)
 		case "==":
 			c.emit(code.OpEqual)
 		case "!=":
 			c.emit(code.OpUnEqual)
 		default:
-			return fmt.Errorf("unknown operator %s", node.Operator)
+			return errrs.Errorf(node.Span(), "unknown operator %s", node.Operator)
 		}
 	case *ast.IfExpression:
 		if err := c.Compile(node.Condition); err != nil {
@@ -139,6 +203,8 @@ func (c *Compiler) Compile(node ast.Node) error {
 
 		if c.lastInstructionIs(code.OpPop) {
 			c.removeLastPop()
+		} else {
+			c.emit(code.OpNull)
 		}
 
 		// emit bogus jump location
@@ -156,11 +222,117 @@ func (c *Compiler) Compile(node ast.Node) error {
 
 			if c.lastInstructionIs(code.OpPop) {
 				c.removeLastPop()
+			} else {
+				c.emit(code.OpNull)
 			}
 		}
 
 		afterAlternativePosition := len(c.currentInstructions())
 		c.changeOperand(jumpPos, afterAlternativePosition)
+	case *ast.WhileStatement:
+		loopStart := len(c.currentInstructions())
+		if err := c.Compile(node.Condition); err != nil {
+			return err
+		}
+		exitPos := c.emit(code.OpJumpFalse, 9999)
+
+		base := c.symbolTable.numDefinitions
+		loop, err := c.compileLoopBody(node.Body)
+		if err != nil {
+			return err
+		}
+		continuePos := c.closeIteration(base, loopStart)
+		c.emit(code.OpJump, loopStart)
+
+		afterLoopPosition := len(c.currentInstructions())
+		c.changeOperand(exitPos, afterLoopPosition)
+		c.patchLoop(loop, continuePos, afterLoopPosition)
+	case *ast.ForStatement:
+		if node.Init != nil {
+			if err := c.Compile(node.Init); err != nil {
+				return err
+			}
+		}
+
+		loopStart := len(c.currentInstructions())
+		exitPos := -1
+		if node.Condition != nil {
+			if err := c.Compile(node.Condition); err != nil {
+				return err
+			}
+			exitPos = c.emit(code.OpJumpFalse, 9999)
+		}
+
+		base := c.symbolTable.numDefinitions
+		loop, err := c.compileLoopBody(node.Body)
+		if err != nil {
+			return err
+		}
+
+		postPosition := c.closeIteration(base, len(c.currentInstructions()))
+		if node.Post != nil {
+			if err := c.Compile(node.Post); err != nil {
+				return err
+			}
+			c.emit(code.OpPop)
+		}
+		c.emit(code.OpJump, loopStart)
+
+		afterLoopPosition := len(c.currentInstructions())
+		if exitPos != -1 {
+			c.changeOperand(exitPos, afterLoopPosition)
+		}
+		c.patchLoop(loop, postPosition, afterLoopPosition)
+	case *ast.ForInStatement:
+		if err := c.Compile(node.Iterable); err != nil {
+			return err
+		}
+		c.emit(code.OpGetIter)
+
+		// the iterator lives in a slot of its own, a name that can
+		// never be written in source keeps it out of reach
+		iterator := c.symbolTable.Define(fmt.Sprintf("for-in@%d", len(c.currentInstructions())))
+		c.storeSymbol(iterator)
+
+		loopStart := len(c.currentInstructions())
+		c.loadSymbol(iterator)
+		nextPos := c.emit(code.OpIterNext, 9999)
+		base := c.symbolTable.numDefinitions
+		variable, restore, err := c.defineBlock(node.Variable)
+		if err != nil {
+			return err
+		}
+		c.bindSymbol(variable)
+
+		loop, err := c.compileLoopBody(node.Body)
+		restore()
+		if err != nil {
+			return err
+		}
+		continuePos := c.closeIteration(base, loopStart)
+		c.emit(code.OpJump, loopStart)
+
+		afterLoopPosition := len(c.currentInstructions())
+		c.changeOperand(nextPos, afterLoopPosition)
+		c.patchLoop(loop, continuePos, afterLoopPosition)
+	case *ast.BreakStatement:
+		loop := c.currentLoop()
+		if loop == nil {
+			return errrs.Errorf(node.Span(), "break outside of loop")
+		}
+		if err := c.leaveTries(loop.tries); err != nil {
+			return err
+		}
+		loop.breaks = append(loop.breaks, c.emit(code.OpJump, 9999))
+	case *ast.ContinueStatement:
+		loop := c.currentLoop()
+		if loop == nil {
+			return errrs.Errorf(node.Span(), "continue outside of loop")
+		}
+		if err := c.leaveTries(loop.tries); err != nil {
+			return err
+		}
+		loop.continues = append(loop.continues, c.emit(code.OpJump, 9999))
 	case *ast.IndexExpression:
 		if err := c.Compile(node.Left); err != nil {
 			return err
@@ -169,12 +341,47 @@ func (c *Compiler) Compile(node ast.Node) error {
 			return err
 		}
 		c.emit(code.OpIndex)
+	case *ast.SliceExpression:
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		// a bound that was left out is passed as null
+		for _, bound := range []ast.Expression{node.Start, node.End} {
+			if bound == nil {
+				c.emit(code.OpNull)
+				continue
+			}
+			if err := c.Compile(bound); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpSlice)
+	case *ast.RangeExpression:
+		if err := c.Compile(node.Start); err != nil {
+			return err
+		}
+		if err := c.Compile(node.End); err != nil {
+			return err
+		}
+		c.emit(code.OpRange)
 	case *ast.IntegerLiteral:
 		integer := &object.Integer{Value: node.Value}
 		c.emit(code.OpConstant, c.addConstant(integer))
+	case *ast.BigIntegerLiteral:
+		c.emit(code.OpConstant, c.addConstant(&object.BigInteger{Value: node.Value}))
+	case *ast.FloatLiteral:
+		float := &object.Float{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(float))
 	case *ast.StringLiteral:
 		str := &object.String{Value: node.Value}
 		c.emit(code.OpConstant, c.addConstant(str))
+	case *ast.InterpolatedString:
+		for _, part := range node.Parts {
+			if err := c.Compile(part); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpConcat, len(node.Parts))
 	case *ast.Boolean:
 		if node.Value {
 			c.emit(code.OpTrue)
@@ -206,31 +413,198 @@ func (c *Compiler) Compile(node ast.Node) error {
 
 		c.emit(code.OpHash, len(node.Pairs)*2)
 
+	case *ast.StructStatement:
+		seen := make(map[string]bool)
+		fields := make([]string, len(node.Fields))
+		for i, field := range node.Fields {
+			if seen[field.Value] {
+				return errrs.Errorf(field.Span(), "duplicate field %s in struct %s", field.Value, node.Name.Value)
+			}
+			seen[field.Value] = true
+			fields[i] = field.Value
+		}
+		symbol, err := c.define(node.Name)
+		if err != nil {
+			return err
+		}
+		structType := &object.StructType{Name: node.Name.Value, Fields: fields}
+		c.emit(code.OpConstant, c.addConstant(structType))
+		c.storeSymbol(symbol)
+
+	case *ast.EnumStatement:
+		return c.compileEnum(node)
+
+	case *ast.StructLiteral:
+		if err := c.Compile(node.Struct); err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		for i, field := range node.Fields {
+			if seen[field.Value] {
+				return errrs.Errorf(field.Span(), "duplicate field %s", field.Value)
+			}
+			seen[field.Value] = true
+			c.emit(code.OpConstant, c.addConstant(&object.String{Value: field.Value}))
+			if err := c.Compile(node.Values[i]); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpStruct, len(node.Fields)*2)
+
 	case *ast.LetStatement:
-		symbol := c.symbolTable.Define(node.Name.Value)
+		if node.IsConst() {
+			return c.compileConst(node)
+		}
+		symbol, err := c.define(node.Name)
+		if err != nil {
+			return err
+		}
 		if err := c.Compile(node.Value); err != nil {
 			return err
 		}
-		if symbol.Scope == GlobalScope {
-			c.emit(code.OpSetGlobal, symbol.Index)
+		c.storeSymbol(symbol)
+
+	case *ast.ExportStatement:
+		if c.scopeIndex != 0 {
+			return errrs.Errorf(node.Span(), "export must be at the top level of a module")
+		}
+		if err := c.Compile(node.Statement); err != nil {
+			return err
+		}
+		name := node.Statement.Name.Value
+		symbol, _ := c.symbolTable.Resolve(name)
+		c.exports[name] = symbol
+
+	case *ast.ImportStatement:
+		if c.scopeIndex != 0 {
+			return errrs.Errorf(node.Span(), "import must be at the top level of a module")
+		}
+		exports, ok := c.modules[c.imports[node.Path]]
+		if !ok {
+			return errrs.Errorf(node.Span(), "module %q was not resolved", node.Path)
+		}
+		c.symbolTable.DefineNamespace(node.Alias.Value, exports)
+
+	case *ast.MemberExpression:
+		if c.isNamespace(node.Object) {
+			return c.compileExport(node)
+		}
+		if enum, ok := c.enumType(node.Object); ok {
+			return c.compileVariant(enum, node)
+		}
+		if err := c.Compile(node.Object); err != nil {
+			return err
+		}
+		c.emit(code.OpConstant, c.addConstant(&object.String{Value: node.Member.Value}))
+		c.emit(code.OpGetField)
+
+	case *ast.AssignExpression:
+		symbol, ok := c.symbolTable.Resolve(node.Name.Value)
+		if !ok {
+			return errrs.Errorf(node.Name.Span(), "undefined variable: %s", node.Name.Value)
+		}
+		switch symbol.Scope {
+		case BuiltinScope:
+			return errrs.Errorf(node.Name.Span(), "cannot assign to builtin %s", node.Name.Value)
+		case NamespaceScope:
+			return errrs.Errorf(node.Name.Span(), "cannot assign to module %s", node.Name.Value)
+		case FunctionScope:
+			return errrs.Errorf(node.Name.Span(), "cannot assign to %s", node.Name.Value)
+		}
+		if symbol.Constant {
+			return errrs.Errorf(node.Name.Span(), "cannot assign to constant %s", node.Name.Value)
+		}
+
+		if node.Operator == "=" {
+			if err := c.Compile(node.Value); err != nil {
+				return err
+			}
 		} else {
-			c.emit(code.OpSetLocal, symbol.Index)
+			op, ok := compoundOperators[node.Operator]
+			if !ok {
+				return errrs.Errorf(node.Span(), "unknown operator %s", node.Operator)
+			}
+			c.loadSymbol(symbol)
+			if err := c.Compile(node.Value); err != nil {
+				return err
+			}
+			c.emit(op)
+		}
+		c.storeSymbol(symbol)
+		c.loadSymbol(symbol)
+
+	case *ast.FieldAssignExpression:
+		// OpSetField takes a compound operator the way OpSetIndex does
+		var op code.Opcode
+		if node.Operator != "=" {
+			compound, ok := compoundOperators[node.Operator]
+			if !ok {
+				return errrs.Errorf(node.Span(), "unknown operator %s", node.Operator)
+			}
+			op = compound
+		}
+		if c.isNamespace(node.Target.Object) {
+			return errrs.Errorf(node.Span(), "cannot assign to %s, exports of a module are read only", node.Target.String())
+		}
+		if err := c.Compile(node.Target.Object); err != nil {
+			return err
+		}
+		c.emit(code.OpConstant, c.addConstant(&object.String{Value: node.Target.Member.Value}))
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.emit(code.OpSetField, int(op))
+
+	case *ast.IndexAssignExpression:
+		// OpSetIndex carries the arithmetic of a compound assignment
+		// as its operand, the vm applies it to the current element.
+		// Plain assignments leave the operand at 0
+		var op code.Opcode
+		if node.Operator != "=" {
+			compound, ok := compoundOperators[node.Operator]
+			if !ok {
+				return errrs.Errorf(node.Span(), "unknown operator %s", node.Operator)
+			}
+			op = compound
+		}
+		if err := c.Compile(node.Target.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Target.Index); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Value); err != nil {
+			return err
 		}
+		c.emit(code.OpSetIndex, int(op))
 
 	case *ast.Identifier:
 		symbol, ok := c.symbolTable.Resolve(node.Value)
 		if !ok {
-			return fmt.Errorf("undefined variable: %s", node.Value)
+			return errrs.Errorf(node.Span(), "undefined variable: %s", node.Value)
+		}
+		if symbol.Scope == NamespaceScope {
+			return errrs.Errorf(node.Span(), "%s is a module, use %s.name to reach its exports", node.Value, node.Value)
 		}
 		c.loadSymbol(symbol)
 
 	case *ast.FunctionLiteral:
 		c.enterScope()
+		c.scopes[c.scopeIndex].generator = node.Generator
 		if node.Name != "" {
 			c.symbolTable.DefineFunctionName(node.Name)
 		}
-		for _, param := range node.Parameters {
+		params := make([]string, len(node.Parameters))
+		for i, param := range node.Parameters {
 			c.symbolTable.Define(param.Value)
+			params[i] = param.Value
+		}
+		if node.Rest != nil {
+			c.symbolTable.Define(node.Rest.Value)
+		}
+		numDefaults, err := c.compileDefaults(node)
+		if err != nil {
+			return err
 		}
 		if err := c.Compile(node.Body); err != nil {
 			return err
@@ -241,47 +615,124 @@ func (c *Compiler) Compile(node ast.Node) error {
 		if !c.lastInstructionIs(code.OpReturnValue) {
 			c.emit(code.OpReturn)
 		}
+		c.widenJumps()
+		// a generator has to keep its frame to be resumed in it
+		if !node.Generator {
+			markTailCalls(c.currentInstructions())
+		}
 
 		freeSymbols := c.symbolTable.FreeSymbols
 		numLocals := c.symbolTable.numDefinitions
+		sourceMap := c.scopes[c.scopeIndex].sourceMap
 		insts := c.leaveScope()
 
 		for _, sym := range freeSymbols {
-			c.loadSymbol(sym)
+			c.captureSymbol(sym)
 		}
 
 		compiledFun := &object.CompiledFunction{
+			Name:         node.Name,
 			Instructions: insts,
 			NumLocals:    numLocals,
 			NumParams:    len(node.Parameters),
+			Params:       params,
+			NumDefaults:  numDefaults,
+			Rest:         node.Rest != nil,
+			Generator:    node.Generator,
+			SourceMap:    sourceMap,
 		}
 
 		fnIndex := c.addConstant(compiledFun)
 		c.emit(code.OpClosure, fnIndex, len(freeSymbols))
+	case *ast.MacroLiteral:
+		// macros are expanded away before compiling, see
+		// evaluator.DefineMacros, only top level lets can define them
+		return errrs.Errorf(node.Span(), "macro must be defined by a top level let statement")
 	case *ast.ReturnStatement:
 		if err := c.Compile(node.ReturnValue); err != nil {
 			return err
 		}
+		if err := c.leaveTries(0); err != nil {
+			return err
+		}
 		c.emit(code.OpReturnValue)
+	case *ast.YieldExpression:
+		if !c.scopes[c.scopeIndex].generator {
+			return errrs.Errorf(node.Span(), "yield outside of a generator function")
+		}
+		if node.Value != nil {
+			if err := c.Compile(node.Value); err != nil {
+				return err
+			}
+		} else {
+			c.emit(code.OpNull)
+		}
+		c.emit(code.OpYield)
+	case *ast.SpawnExpression:
+		return c.compileSpawn(node)
+	case *ast.ThrowStatement:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.emit(code.OpThrow)
+	case *ast.TryExpression:
+		return c.compileTry(node)
+	case *ast.MatchExpression:
+		return c.compileMatch(node)
 	case *ast.CallExpression:
 		if err := c.Compile(node.Function); err != nil {
 			return err
 		}
+		names := []object.Object{}
 		for _, arg := range node.Arguments {
+			if named, ok := arg.(*ast.NamedArgument); ok {
+				names = append(names, &object.String{Value: named.Name.Value})
+				arg = named.Value
+			}
 			if err := c.Compile(arg); err != nil {
 				return err
 			}
 		}
-		c.emit(code.OpCall, len(node.Arguments))
+		if len(names) == 0 {
+			c.emit(code.OpCall, len(node.Arguments))
+			return nil
+		}
+		namesIndex := c.addConstant(&object.Array{Elements: names})
+		c.emit(code.OpCallNamed, len(node.Arguments), namesIndex)
 	}
 
 	return nil
 }
 
+// compileDefaults emits the prologue that gives parameters left without
+// an argument their default value. The vm leaves the local of such a
+// parameter empty and OpSkipDefault jumps over the default when it isn't
+func (c *Compiler) compileDefaults(node *ast.FunctionLiteral) (int, error) {
+	numDefaults := 0
+	for i, param := range node.Parameters {
+		def := node.Default(i)
+		if def == nil {
+			continue
+		}
+		numDefaults++
+
+		sym, _ := c.symbolTable.Resolve(param.Value)
+		skipPos := c.emit(code.OpSkipDefault, sym.Index, 9999)
+		if err := c.Compile(def); err != nil {
+			return 0, err
+		}
+		c.emit(code.OpSetLocal, sym.Index)
+		c.changeOperand(skipPos, len(c.currentInstructions()))
+	}
+	return numDefaults, nil
+}
+
 func (c *Compiler) ByteCode() *ByteCode {
+	c.widenJumps()
 	return &ByteCode{
 		Instructions: c.currentInstructions(),
 		Constants:    c.constants,
+		SourceMap:    c.scopes[c.scopeIndex].sourceMap,
 	}
 }
 
@@ -291,6 +742,7 @@ func (c *Compiler) addConstant(obj object.Object) int {
 }
 
 func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	c.checkOperands(op, operands)
 	ins := code.Make(op, operands...)
 	pos := c.addInstruction(ins)
 	c.setLastInstruction(op, pos)
@@ -300,9 +752,33 @@ func (c *Compiler) emit(op code.Opcode, operands ...int) int {
 func (c *Compiler) addInstruction(ins []byte) int {
 	posNewInstruction := len(c.currentInstructions())
 	c.scopes[c.scopeIndex].instructions = append(c.currentInstructions(), ins...)
+	c.markSource(posNewInstruction)
 	return posNewInstruction
 }
 
+// markSource remembers that the instruction at pos belongs to the
+// node currently being compiled
+func (c *Compiler) markSource(pos int) {
+	if !c.span.Start.IsValid() {
+		return
+	}
+	scope := &c.scopes[c.scopeIndex]
+	if n := len(scope.sourceMap); n > 0 && scope.sourceMap[n-1].Span == c.span {
+		return
+	}
+	scope.sourceMap = append(scope.sourceMap, code.SourceMark{Offset: pos, Span: c.span})
+}
+
+// trimSourceMap drops the marks of instructions that were removed
+func (c *Compiler) trimSourceMap() {
+	scope := &c.scopes[c.scopeIndex]
+	n := len(scope.sourceMap)
+	for n > 0 && scope.sourceMap[n-1].Offset >= len(scope.instructions) {
+		n--
+	}
+	scope.sourceMap = scope.sourceMap[:n]
+}
+
 func (c *Compiler) setLastInstruction(op code.Opcode, pos int) {
 	prev := c.scopes[c.scopeIndex].lastInstruction
 	last := EmittedInstruction{Opcode: op, Position: pos}
@@ -319,6 +795,7 @@ func (c *Compiler) lastInstructionIs(op code.Opcode) bool {
 func (c *Compiler) removeLastPop() {
 	c.scopes[c.scopeIndex].instructions = c.currentInstructions()[:c.scopes[c.scopeIndex].lastInstruction.Position]
 	c.scopes[c.scopeIndex].lastInstruction = c.scopes[c.scopeIndex].prevInstruction
+	c.trimSourceMap()
 }
 func (c *Compiler) replaceLastPopWithReturn() {
 	lastPos := c.scopes[c.scopeIndex].lastInstruction.Position
@@ -332,9 +809,23 @@ func (c *Compiler) replaceInstruction(pos int, newInstruction []byte) {
 	}
 }
 
+// changeOperand points the jump at pos to operand. Jumps are emitted
+// before their target is known, a target that doesn't fit the operand
+// the jump got is kept aside until widenJumps makes room for it
 func (c *Compiler) changeOperand(pos int, operand int) {
-	op := code.Opcode(c.currentInstructions()[pos])
-	newInstruction := code.Make(op, operand)
+	op, operands, read := code.Decode(c.currentInstructions()[pos:])
+	index, _ := code.JumpOperand(op)
+	operands[index] = operand
+
+	newInstruction := code.Make(op, operands...)
+	if len(newInstruction) != read {
+		scope := &c.scopes[c.scopeIndex]
+		if scope.farJumps == nil {
+			scope.farJumps = make(map[int]int)
+		}
+		scope.farJumps[pos] = operand
+		return
+	}
 	c.replaceInstruction(pos, newInstruction)
 }
 
@@ -363,7 +854,252 @@ func (c *Compiler) leaveScope() code.Instructions {
 	return instructions
 }
 
+// compileLogical compiles && and || so that the right operand is only
+// evaluated when the left one does not decide the result, both
+// operators always produce a boolean
+func (c *Compiler) compileLogical(node *ast.InfixExpression) error {
+	if err := c.Compile(node.Left); err != nil {
+		return err
+	}
+	leftJump := c.emit(code.OpJumpFalse, 9999)
+
+	// a truthy left operand settles ||, a falsy one falls through
+	// to the right operand
+	shortCircuit := -1
+	if node.Operator == "||" {
+		c.emit(code.OpTrue)
+		shortCircuit = c.emit(code.OpJump, 9999)
+		c.changeOperand(leftJump, len(c.currentInstructions()))
+	}
+
+	if err := c.Compile(node.Right); err != nil {
+		return err
+	}
+	rightJump := c.emit(code.OpJumpFalse, 9999)
+	c.emit(code.OpTrue)
+	endJump := c.emit(code.OpJump, 9999)
+
+	falsePosition := len(c.currentInstructions())
+	c.emit(code.OpFalse)
+	afterPosition := len(c.currentInstructions())
+
+	c.changeOperand(rightJump, falsePosition)
+	c.changeOperand(endJump, afterPosition)
+	if shortCircuit != -1 {
+		c.changeOperand(shortCircuit, afterPosition)
+	} else {
+		c.changeOperand(leftJump, falsePosition)
+	}
+	return nil
+}
+
+// compileTry lays a try expression out as
+//
+//	OpTry handler, block, OpEndTry, finally, OpJump done
+//	handler: catch, OpJump done
+//	rethrow: finally, OpThrow
+//	done:
+//
+// the vm pushes the error before jumping to the handler. With a
+// finally block the catch gets a try of its own that leads to rethrow,
+// which is also the handler when there's no catch
+func (c *Compiler) compileTry(node *ast.TryExpression) error {
+	handlerPos := c.emit(code.OpTry, 9999)
+	if err := c.compileTryBlock(node.Block, node.Finally); err != nil {
+		return err
+	}
+	jumps := []int{c.emit(code.OpJump, 9999)}
+	c.changeOperand(handlerPos, len(c.currentInstructions()))
+
+	if node.Catch != nil {
+		restore := func() {}
+		if node.Param != nil {
+			param, restoreParam, err := c.defineBlock(node.Param)
+			if err != nil {
+				return err
+			}
+			restore = restoreParam
+			c.bindSymbol(param)
+		} else {
+			c.emit(code.OpPop)
+		}
+
+		if node.Finally == nil {
+			err := c.compileValueBlock(node.Catch)
+			restore()
+			if err != nil {
+				return err
+			}
+		} else {
+			rethrowPos := c.emit(code.OpTry, 9999)
+			err := c.compileTryBlock(node.Catch, node.Finally)
+			restore()
+			if err != nil {
+				return err
+			}
+			jumps = append(jumps, c.emit(code.OpJump, 9999))
+			c.changeOperand(rethrowPos, len(c.currentInstructions()))
+		}
+	}
+
+	if node.Finally != nil {
+		// the error waits in a slot of its own while finally runs
+		pending := c.symbolTable.Define(fmt.Sprintf("try@%d", len(c.currentInstructions())))
+		c.storeSymbol(pending)
+		if err := c.Compile(node.Finally); err != nil {
+			return err
+		}
+		c.loadSymbol(pending)
+		c.emit(code.OpThrow)
+	}
+
+	afterTryPosition := len(c.currentInstructions())
+	for _, pos := range jumps {
+		c.changeOperand(pos, afterTryPosition)
+	}
+	return nil
+}
+
+// compileTryBlock compiles the guarded part of a try, leaving its
+// value on the stack, then drops the handler and runs finally
+func (c *Compiler) compileTryBlock(block, finally *ast.BlockStatement) error {
+	scope := c.scopeIndex
+	c.scopes[scope].tries = append(c.scopes[scope].tries, finally)
+	err := c.compileValueBlock(block)
+	c.scopes[scope].tries = c.scopes[scope].tries[:len(c.scopes[scope].tries)-1]
+	if err != nil {
+		return err
+	}
+
+	c.emit(code.OpEndTry)
+	if finally != nil {
+		return c.Compile(finally)
+	}
+	return nil
+}
+
+// compileValueBlock compiles block so that it leaves its value on the
+// stack, null when it doesn't end with an expression
+func (c *Compiler) compileValueBlock(block *ast.BlockStatement) error {
+	start := len(c.currentInstructions())
+	if err := c.Compile(block); err != nil {
+		return err
+	}
+	if len(c.currentInstructions()) > start && c.lastInstructionIs(code.OpPop) {
+		c.removeLastPop()
+	} else {
+		c.emit(code.OpNull)
+	}
+	return nil
+}
+
+// leaveTries is used by break, continue and return to jump out of the
+// tries they are in, down to depth. It drops their handlers and runs
+// their finally blocks, innermost first
+func (c *Compiler) leaveTries(depth int) error {
+	scope := c.scopeIndex
+	tries := c.scopes[scope].tries
+	for i := len(tries) - 1; i >= depth; i-- {
+		c.emit(code.OpEndTry)
+		if tries[i] == nil {
+			continue
+		}
+		// a jump out of the finally block itself leaves the try for good
+		c.scopes[scope].tries = tries[:i]
+		err := c.Compile(tries[i])
+		c.scopes[scope].tries = tries
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) storeSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(code.OpSetGlobal, s.Index)
+	case LocalScope:
+		c.emit(code.OpSetLocal, s.Index)
+	case FreeScope:
+		c.emit(code.OpSetFree, s.Index)
+	case CellScope:
+		c.emit(code.OpSetCell, s.Index)
+	}
+}
+
+// bindSymbol stores the value a block binds s to, s gets a cell of its
+// own when it lives in one so closures from earlier runs keep theirs
+func (c *Compiler) bindSymbol(s Symbol) {
+	if s.Scope == CellScope {
+		c.emit(code.OpNewCell, s.Index)
+		return
+	}
+	c.storeSymbol(s)
+}
+
+// captureSymbol pushes what a closure captures for s. Locals and
+// free variables are captured as upvalues, so the closure shares them
+// with the function it is created in
+func (c *Compiler) captureSymbol(s Symbol) {
+	switch s.Scope {
+	case LocalScope:
+		c.emit(code.OpCaptureLocal, s.Index)
+	case FreeScope:
+		c.emit(code.OpCaptureFree, s.Index)
+	case CellScope:
+		// the global holds the cell itself
+		c.emit(code.OpGetGlobal, s.Index)
+	default:
+		c.loadSymbol(s)
+	}
+}
+
+// closeIteration closes the upvalues of the locals a loop body defined
+// from base on once an iteration is done, so closures created in the
+// loop each keep the variables of their own iteration. It returns where
+// continue has to jump to, next when there is nothing to close
+func (c *Compiler) closeIteration(base, next int) int {
+	if c.symbolTable.Outer == nil || !c.symbolTable.CapturesFrom(base) {
+		return next
+	}
+	return c.emit(code.OpCloseUpvalues, base)
+}
+
+// compileLoopBody compiles body with a fresh loop on the loop stack,
+// the returned loop holds the break and continue jumps to patch
+func (c *Compiler) compileLoopBody(body *ast.BlockStatement) (*Loop, error) {
+	index := c.scopeIndex
+	loop := &Loop{tries: len(c.scopes[index].tries)}
+	c.scopes[index].loops = append(c.scopes[index].loops, loop)
+	err := c.Compile(body)
+	loops := c.scopes[index].loops
+	c.scopes[index].loops = loops[:len(loops)-1]
+	return loop, err
+}
+
+func (c *Compiler) currentLoop() *Loop {
+	loops := c.scopes[c.scopeIndex].loops
+	if len(loops) == 0 {
+		return nil
+	}
+	return loops[len(loops)-1]
+}
+
+func (c *Compiler) patchLoop(loop *Loop, continuePos, breakPos int) {
+	for _, pos := range loop.continues {
+		c.changeOperand(pos, continuePos)
+	}
+	for _, pos := range loop.breaks {
+		c.changeOperand(pos, breakPos)
+	}
+}
+
 func (c *Compiler) loadSymbol(s Symbol) {
+	if s.Value != nil {
+		c.loadValue(s.Value)
+		return
+	}
 	switch s.Scope {
 	case GlobalScope:
 		c.emit(code.OpGetGlobal, s.Index)
@@ -373,6 +1109,8 @@ func (c *Compiler) loadSymbol(s Symbol) {
 		c.emit(code.OpGetBuiltin, s.Index)
 	case FreeScope:
 		c.emit(code.OpGetFree, s.Index)
+	case CellScope:
+		c.emit(code.OpGetCell, s.Index)
 	case FunctionScope:
 		c.emit(code.OpCurrentClosure)
 	}
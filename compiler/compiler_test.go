@@ -2,9 +2,13 @@ package compiler
 
 import (
 	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 	"zetsu/ast"
 	"zetsu/code"
+	"zetsu/errrs"
+	"zetsu/global"
 	"zetsu/lexer"
 	"zetsu/object"
 	"zetsu/parser"
@@ -76,10 +80,38 @@ func testConstants(t *testing.T, expected []interface{}, actual []object.Object)
 			if err := testIntegerObject(int64(cons), actual[i]); err != nil {
 				return fmt.Errorf("constant %d - testIntegerObject failed - %s", i, err)
 			}
+		case *big.Int:
+			integer, ok := actual[i].(*object.BigInteger)
+			if !ok || integer.Value.Cmp(cons) != 0 {
+				return fmt.Errorf("constant %d - wrong big integer. got=%s, want=%s", i, actual[i].Inspect(), cons)
+			}
+		case float64:
+			if err := testFloatObject(cons, actual[i]); err != nil {
+				return fmt.Errorf("constant %d - testFloatObject failed - %s", i, err)
+			}
 		case string:
 			if err := testStringObject(string(cons), actual[i]); err != nil {
 				return fmt.Errorf("constant %d - testStringObject failed - %s", i, err)
 			}
+		case *object.StructType:
+			if actual[i].Inspect() != cons.Inspect() {
+				return fmt.Errorf("constant %d - wrong struct type. got=%s, want=%s", i, actual[i].Inspect(), cons.Inspect())
+			}
+		case *object.EnumValue:
+			value, ok := actual[i].(*object.EnumValue)
+			if !ok || *value != *cons {
+				return fmt.Errorf("constant %d - wrong enum value. got=%+v, want=%+v", i, actual[i], cons)
+			}
+		case []string:
+			arr, ok := actual[i].(*object.Array)
+			if !ok || len(arr.Elements) != len(cons) {
+				return fmt.Errorf("constant %d - not an array of %d elements: %s", i, len(cons), actual[i].Inspect())
+			}
+			for j, s := range cons {
+				if err := testStringObject(s, arr.Elements[j]); err != nil {
+					return fmt.Errorf("constant %d - element %d - testStringObject failed - %s", i, j, err)
+				}
+			}
 		case []code.Instructions:
 			fn, ok := actual[i].(*object.CompiledFunction)
 			if !ok {
@@ -107,6 +139,19 @@ func testIntegerObject(expected int64, actual object.Object) error {
 	return nil
 }
 
+func testFloatObject(expected float64, actual object.Object) error {
+	result, ok := actual.(*object.Float)
+	if !ok {
+		return fmt.Errorf("object is not float. got = %T, (%+v)", actual, actual)
+	}
+
+	if result.Value != expected {
+		return fmt.Errorf("object has wrong value. got = %g, want = %g", result.Value, expected)
+	}
+
+	return nil
+}
+
 func testStringObject(expected string, actual object.Object) error {
 	result, ok := actual.(*object.String)
 
@@ -196,6 +241,105 @@ func TestIntegerArithmatic(t *testing.T) {
 	runCompilerTests(t, tests)
 }
 
+func TestLogicalOperators(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "true && false",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpFalse, 12),
+				// 0004
+				code.Make(code.OpFalse),
+				// 0005
+				code.Make(code.OpJumpFalse, 12),
+				// 0008
+				code.Make(code.OpTrue),
+				// 0009
+				code.Make(code.OpJump, 13),
+				// 0012
+				code.Make(code.OpFalse),
+				// 0013
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "true || false",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpFalse, 8),
+				// 0004
+				code.Make(code.OpTrue),
+				// 0005
+				code.Make(code.OpJump, 17),
+				// 0008
+				code.Make(code.OpFalse),
+				// 0009
+				code.Make(code.OpJumpFalse, 16),
+				// 0012
+				code.Make(code.OpTrue),
+				// 0013
+				code.Make(code.OpJump, 17),
+				// 0016
+				code.Make(code.OpFalse),
+				// 0017
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "1 <= 2; 1 >= 2; 1 % 2",
+			expectedConstants: []interface{}{2, 1, 1, 2, 1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpGreaterEqual),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpConstant, 3),
+				code.Make(code.OpGreaterEqual),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 4),
+				code.Make(code.OpConstant, 5),
+				code.Make(code.OpMod),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestFloatArithmatic(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "1.5 + 2",
+			expectedConstants: []interface{}{1.5, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "-0.5",
+			expectedConstants: []interface{}{0.5},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpMinus),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
 func TestBooleanExpressions(t *testing.T) {
 	tests := []compilerTestCase{
 		{
@@ -287,6 +431,32 @@ func TestBooleanExpressions(t *testing.T) {
 	runCompilerTests(t, tests)
 }
 
+func TestBigIntegerLiterals(t *testing.T) {
+	n, _ := new(big.Int).SetString("99999999999999999999", 10)
+	tests := []compilerTestCase{
+		{
+			input:             "99999999999999999999 + 1",
+			expectedConstants: []interface{}{n, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "const n = 99999999999999999999; n",
+			expectedConstants: []interface{}{n},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
 func TestConditionals(t *testing.T) {
 	tests := []compilerTestCase{
 		{
@@ -333,36 +503,25 @@ func TestConditionals(t *testing.T) {
 				code.Make(code.OpPop),
 			},
 		},
-	}
-	runCompilerTests(t, tests)
-}
-
-func TestGlobalLetStatements(t *testing.T) {
-	tests := []compilerTestCase{
 		{
-			input:             ` let one = 1; let two = 2; `,
-			expectedConstants: []interface{}{1, 2},
+			input:             "if (true) { } else { 20 }; 3333; ",
+			expectedConstants: []interface{}{20, 3333},
 			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpFalse, 8),
+				// 0004
+				code.Make(code.OpNull),
+				// 0005
+				code.Make(code.OpJump, 11),
+				// 0008
 				code.Make(code.OpConstant, 0),
-				code.Make(code.OpSetGlobal, 0),
-				code.Make(code.OpConstant, 1),
-				code.Make(code.OpSetGlobal, 1),
-			},
-		},
-		{
-			input: ` let one = 1; one; `, expectedConstants: []interface{}{1}, expectedInstructions: []code.Instructions{code.Make(code.OpConstant, 0),
-				code.Make(code.OpSetGlobal, 0),
-				code.Make(code.OpGetGlobal, 0),
+				// 0011
 				code.Make(code.OpPop),
-			},
-		},
-		{
-			input: ` let one = 1; let two = one; two; `, expectedConstants: []interface{}{1}, expectedInstructions: []code.Instructions{
-				code.Make(code.OpConstant, 0),
-				code.Make(code.OpSetGlobal, 0),
-				code.Make(code.OpGetGlobal, 0),
-				code.Make(code.OpSetGlobal, 1),
-				code.Make(code.OpGetGlobal, 1),
+				// 0012
+				code.Make(code.OpConstant, 1),
+				// 0015
 				code.Make(code.OpPop),
 			},
 		},
@@ -370,99 +529,827 @@ func TestGlobalLetStatements(t *testing.T) {
 	runCompilerTests(t, tests)
 }
 
-func TestLetStatements(t *testing.T) {
+func TestLoops(t *testing.T) {
 	tests := []compilerTestCase{
 		{
-			input: "let num = 55; fn(){ num }",
-			expectedConstants: []interface{}{
-				55,
-				[]code.Instructions{
-					code.Make(code.OpGetGlobal, 0),
-					code.Make(code.OpReturnValue),
-				},
-			},
+			input:             "while (true) { 10; }; 3333;",
+			expectedConstants: []interface{}{10, 3333},
 			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpFalse, 11),
+				// 0004
 				code.Make(code.OpConstant, 0),
-				code.Make(code.OpSetGlobal, 0),
-				code.Make(code.OpClosure, 1, 0),
+				// 0007
+				code.Make(code.OpPop),
+				// 0008
+				code.Make(code.OpJump, 0),
+				// 0011
+				code.Make(code.OpConstant, 1),
+				// 0014
 				code.Make(code.OpPop),
 			},
 		},
 		{
-			input: "fn() { let a = 55; a; }",
-			expectedConstants: []interface{}{
-				55,
-				[]code.Instructions{
-					code.Make(code.OpConstant, 0),
-					code.Make(code.OpSetLocal, 0),
-					code.Make(code.OpGetLocal, 0),
-					code.Make(code.OpReturnValue),
-				},
-			},
+			input:             "while (true) { break; continue; }",
+			expectedConstants: []interface{}{},
 			expectedInstructions: []code.Instructions{
-				code.Make(code.OpClosure, 1, 0),
-				code.Make(code.OpPop),
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpFalse, 13),
+				// 0004
+				code.Make(code.OpJump, 13),
+				// 0007
+				code.Make(code.OpJump, 0),
+				// 0010
+				code.Make(code.OpJump, 0),
 			},
 		},
 		{
-			input: "fn(){let a = 1; let b = 2; a + b; }",
-			expectedConstants: []interface{}{
-				1,
-				2,
-				[]code.Instructions{
-					code.Make(code.OpConstant, 0),
-					code.Make(code.OpSetLocal, 0),
-
-					code.Make(code.OpConstant, 1),
-					code.Make(code.OpSetLocal, 1),
-
-					code.Make(code.OpGetLocal, 0),
-					code.Make(code.OpGetLocal, 1),
-
-					code.Make(code.OpAdd),
-					code.Make(code.OpReturnValue),
-				},
-			},
+			input:             "for (;;) { break; }",
+			expectedConstants: []interface{}{},
 			expectedInstructions: []code.Instructions{
-				code.Make(code.OpClosure, 2, 0),
-				code.Make(code.OpPop),
+				// 0000
+				code.Make(code.OpJump, 6),
+				// 0003
+				code.Make(code.OpJump, 0),
 			},
 		},
-	}
-	runCompilerTests(t, tests)
-}
-
-func TestStringExpressions(t *testing.T) {
-	tests := []compilerTestCase{
 		{
-			input:             `"monkey"`,
-			expectedConstants: []interface{}{"monkey"},
+			input:             "for (let i = 0; i < 2; i = i + 1) { continue; }",
+			expectedConstants: []interface{}{0, 2, 1},
 			expectedInstructions: []code.Instructions{
+				// 0000
 				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpSetGlobal, 0),
+				// 0006
+				code.Make(code.OpConstant, 1),
+				// 0009
+				code.Make(code.OpGetGlobal, 0),
+				// 0012
+				code.Make(code.OpGreater),
+				// 0013
+				code.Make(code.OpJumpFalse, 36),
+				// 0016
+				code.Make(code.OpJump, 19),
+				// 0019
+				code.Make(code.OpGetGlobal, 0),
+				// 0022
+				code.Make(code.OpConstant, 2),
+				// 0025
+				code.Make(code.OpAdd),
+				// 0026
+				code.Make(code.OpSetGlobal, 0),
+				// 0029
+				code.Make(code.OpGetGlobal, 0),
+				// 0032
 				code.Make(code.OpPop),
+				// 0033
+				code.Make(code.OpJump, 6),
 			},
 		},
 		{
-			input:             `"mon" + "key"`,
-			expectedConstants: []interface{}{"mon", "key"},
+			input:             "for x in [1] { x; }",
+			expectedConstants: []interface{}{1},
 			expectedInstructions: []code.Instructions{
+				// 0000
 				code.Make(code.OpConstant, 0),
-				code.Make(code.OpConstant, 1),
-				code.Make(code.OpAdd),
+				// 0003
+				code.Make(code.OpArray, 1),
+				// 0006
+				code.Make(code.OpGetIter),
+				// 0007
+				code.Make(code.OpSetGlobal, 0),
+				// 0010
+				code.Make(code.OpGetGlobal, 0),
+				// 0013
+				code.Make(code.OpIterNext, 26),
+				// 0016
+				code.Make(code.OpNewCell, 1),
+				// 0019
+				code.Make(code.OpGetCell, 1),
+				// 0022
 				code.Make(code.OpPop),
+				// 0023
+				code.Make(code.OpJump, 10),
 			},
 		},
 	}
+
 	runCompilerTests(t, tests)
 }
 
-func TestArrayLiterals(t *testing.T) {
+func TestTryExpressions(t *testing.T) {
 	tests := []compilerTestCase{
 		{
-			input:             "[]",
-			expectedConstants: []interface{}{},
+			input:             "try { 1 } catch (e) { e }; 2;",
+			expectedConstants: []interface{}{1, 2},
 			expectedInstructions: []code.Instructions{
-				code.Make(code.OpArray, 0),
+				// 0000
+				code.Make(code.OpTry, 10),
+				// 0003
+				code.Make(code.OpConstant, 0),
+				// 0006
+				code.Make(code.OpEndTry),
+				// 0007
+				code.Make(code.OpJump, 16),
+				// 0010
+				code.Make(code.OpNewCell, 0),
+				// 0013
+				code.Make(code.OpGetCell, 0),
+				// 0016
+				code.Make(code.OpPop),
+				// 0017
+				code.Make(code.OpConstant, 1),
+				// 0020
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "try { 1 } finally { 2 };",
+			expectedConstants: []interface{}{1, 2, 2},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTry, 14),
+				// 0003
+				code.Make(code.OpConstant, 0),
+				// 0006
+				code.Make(code.OpEndTry),
+				// 0007
+				code.Make(code.OpConstant, 1),
+				// 0010
+				code.Make(code.OpPop),
+				// 0011
+				code.Make(code.OpJump, 25),
+				// 0014
+				code.Make(code.OpSetGlobal, 0),
+				// 0017
+				code.Make(code.OpConstant, 2),
+				// 0020
+				code.Make(code.OpPop),
+				// 0021
+				code.Make(code.OpGetGlobal, 0),
+				// 0024
+				code.Make(code.OpThrow),
+				// 0025
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "while (true) { try { break; } finally { 1 } }",
+			expectedConstants: []interface{}{1, 1, 1},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpFalse, 39),
+				// 0004
+				code.Make(code.OpTry, 24),
+				// 0007
+				code.Make(code.OpEndTry),
+				// 0008
+				code.Make(code.OpConstant, 0),
+				// 0011
+				code.Make(code.OpPop),
+				// 0012
+				code.Make(code.OpJump, 39),
+				// 0015
+				code.Make(code.OpNull),
+				// 0016
+				code.Make(code.OpEndTry),
+				// 0017
+				code.Make(code.OpConstant, 1),
+				// 0020
+				code.Make(code.OpPop),
+				// 0021
+				code.Make(code.OpJump, 35),
+				// 0024
+				code.Make(code.OpSetGlobal, 0),
+				// 0027
+				code.Make(code.OpConstant, 2),
+				// 0030
+				code.Make(code.OpPop),
+				// 0031
+				code.Make(code.OpGetGlobal, 0),
+				// 0034
+				code.Make(code.OpThrow),
+				// 0035
+				code.Make(code.OpPop),
+				// 0036
+				code.Make(code.OpJump, 0),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "let x = 1; x += 2;",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "let x = 1; let x = 2;",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSetGlobal, 0),
+			},
+		},
+		{
+			input: "fn() { let x = 1; x = x * 3; }",
+			expectedConstants: []interface{}{
+				1,
+				3,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpMul),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn() { let n = 0; fn() { n -= 1 } }",
+			expectedConstants: []interface{}{
+				0,
+				1,
+				[]code.Instructions{
+					code.Make(code.OpGetFree, 0),
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpSub),
+					code.Make(code.OpSetFree, 0),
+					code.Make(code.OpGetFree, 0),
+					code.Make(code.OpReturnValue),
+				},
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpCaptureLocal, 0),
+					code.Make(code.OpClosure, 2, 1),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 3, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestIndexAssignments(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "[1][0] = 2",
+			expectedConstants: []interface{}{1, 0, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpArray, 1),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpSetIndex, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "{}[1] *= 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpHash, 0),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSetIndex, int(code.OpMul)),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestStructs(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: "struct P { x }; P{x: 1}.x;",
+			expectedConstants: []interface{}{
+				&object.StructType{Name: "P", Fields: []string{"x"}}, "x", 1, "x",
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpStruct, 2),
+				code.Make(code.OpConstant, 3),
+				code.Make(code.OpGetField),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "let p = 1; p.x += 2;",
+			expectedConstants: []interface{}{1, "x", 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpSetField, int(code.OpAdd)),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "match (1) { 1 => 2, _ => 3 };",
+			expectedConstants: []interface{}{1, 1, 2, 3},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpSetGlobal, 0),
+				// 0006
+				code.Make(code.OpGetGlobal, 0),
+				// 0009
+				code.Make(code.OpConstant, 1),
+				// 0012
+				code.Make(code.OpMatchValue),
+				// 0013
+				code.Make(code.OpJumpFalse, 22),
+				// 0016
+				code.Make(code.OpConstant, 2),
+				// 0019
+				code.Make(code.OpJump, 29),
+				// 0022
+				code.Make(code.OpConstant, 3),
+				// 0025
+				code.Make(code.OpJump, 29),
+				// 0028
+				code.Make(code.OpNull),
+				// 0029
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "match ([1]) { [x] => x };",
+			expectedConstants: []interface{}{1, 0},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpArray, 1),
+				// 0006
+				code.Make(code.OpSetGlobal, 0),
+				// 0009
+				code.Make(code.OpGetGlobal, 0),
+				// 0012
+				code.Make(code.OpMatchArray, 1),
+				// 0015
+				code.Make(code.OpJumpFalse, 34),
+				// 0018
+				code.Make(code.OpGetGlobal, 0),
+				// 0021
+				code.Make(code.OpConstant, 1),
+				// 0024
+				code.Make(code.OpIndex),
+				// 0025
+				code.Make(code.OpNewCell, 1),
+				// 0028
+				code.Make(code.OpGetCell, 1),
+				// 0031
+				code.Make(code.OpJump, 35),
+				// 0034
+				code.Make(code.OpNull),
+				// 0035
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestMatchWarnings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"match (1) { 1 => 2, _ => 3 }", nil},
+		{"match (1) { 1 => 2, x => x }", nil},
+		{"match (1) { 1 => 2 }", []string{"match has no _ arm, it is null for values no pattern fits"}},
+		{"match (1) { _ => 2, 1 => 3 }", []string{"unreachable match arm, an earlier arm matches every value"}},
+		{"enum S { A, B }; match (S.A) { S.A => 1, S.B => 2 }", nil},
+		{"enum S { A, B, C }; match (S.A) { S.B => 1 }", []string{"match on S is missing variants A, C"}},
+		{"enum S { A, B }; match (S.A) { S.A => 1, _ => 2 }", nil},
+	}
+
+	for _, tt := range tests {
+		compiler := New()
+		if err := compiler.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		warnings := compiler.Warnings()
+		if len(warnings) != len(tt.expected) {
+			t.Fatalf("wrong number of warnings for %q. want=%d, got=%d", tt.input, len(tt.expected), len(warnings))
+		}
+		for i, w := range warnings {
+			if w.Msg != tt.expected[i] {
+				t.Errorf("wrong warning for %q. want=%q, got=%q", tt.input, tt.expected[i], w.Msg)
+			}
+		}
+	}
+}
+
+func TestEnums(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: "enum Status { Pending, Done }; Status.Done; Status.Pending;",
+			expectedConstants: []interface{}{
+				&object.EnumValue{Enum: "Status", Name: "Done", Ordinal: 1},
+				&object.EnumValue{Enum: "Status", Name: "Pending", Ordinal: 0},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "enum Color { Red }; fn() { Color.Red }",
+			expectedConstants: []interface{}{
+				&object.EnumValue{Enum: "Color", Name: "Red", Ordinal: 0},
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestEnumErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"enum S { A, B }; S.C", "S has no variant C"},
+		{"enum S { A, B }; match (S.A) { S.D => 1, _ => 2 }", "S has no variant D"},
+		{"enum S { A, A }", "duplicate variant A in enum S"},
+		{"enum S { }", "enum S has no variants"},
+		{"enum S { A }; enum S { B }", "cannot redefine constant S"},
+		{"enum S { A }; S = 1;", "cannot assign to constant S"},
+	}
+
+	for _, tt := range tests {
+		err := New().Compile(parse(tt.input))
+		if err == nil {
+			t.Errorf("expected compiler error for %q but resulted in none.", tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong compiler error for %q. want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestStructErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct P { x, x }", "duplicate field x in struct P"},
+		{"struct P { x }; P{x: 1, x: 2}", "duplicate field x"},
+		{"Q{x: 1}", "undefined variable: Q"},
+	}
+
+	for _, tt := range tests {
+		err := New().Compile(parse(tt.input))
+		if err == nil {
+			t.Errorf("expected compiler error for %q but resulted in none.", tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong compiler error for %q. want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestLoopErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break;", "break outside of loop"},
+		{"while (true) { fn() { continue; } }", "continue outside of loop"},
+		{"x = 1;", "undefined variable: x"},
+		{"len = 1;", "cannot assign to builtin len"},
+		{"let f = fn() { f = 1; };", "cannot assign to f"},
+		{"let x = 1; y += 1;", "undefined variable: y"},
+	}
+
+	for _, tt := range tests {
+		err := New().Compile(parse(tt.input))
+		if err == nil {
+			t.Errorf("expected compiler error for %q but resulted in none.", tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong compiler error for %q. want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestConstStatements(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "const a = 1; a; a;",
+			expectedConstants: []interface{}{1, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "const on = true; fn() { on }",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpTrue),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 0, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn() { const n = -2; fn() { n } }",
+			expectedConstants: []interface{}{
+				-2,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpReturnValue),
+				},
+				[]code.Instructions{
+					code.Make(code.OpClosure, 1, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "const f = fn() { 1 }; f();",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpCall, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "const x = 1; fn() { let x = 2; x }",
+			expectedConstants: []interface{}{
+				2,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestConstErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 1; x = 2;", "cannot assign to constant x"},
+		{"const x = 1; x += 2;", "cannot assign to constant x"},
+		{"const x = 1; let x = 2;", "cannot redefine constant x"},
+		{"const x = 1; const x = 2;", "cannot redefine constant x"},
+		{"const P = 1; struct P { x }", "cannot redefine constant P"},
+		{"const x = 1; for x in [1] { }", "cannot redefine constant x"},
+		{"const x = 1; match ([5]) { [1] => 0, [x] => x }", "cannot redefine constant x"},
+		{`const e = 1; try { throw "x" } catch (e) { 0 }`, "cannot redefine constant e"},
+		{"const f = fn() { 1 }; f = 2;", "cannot assign to constant f"},
+		{"fn() { const xs = [1]; fn() { xs = 2 } }", "cannot assign to constant xs"},
+	}
+
+	for _, tt := range tests {
+		err := New().Compile(parse(tt.input))
+		if err == nil {
+			t.Errorf("expected compiler error for %q but resulted in none.", tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong compiler error for %q. want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestGlobalLetStatements(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             ` let one = 1; let two = 2; `,
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSetGlobal, 1),
+			},
+		},
+		{
+			input: ` let one = 1; one; `, expectedConstants: []interface{}{1}, expectedInstructions: []code.Instructions{code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: ` let one = 1; let two = one; two; `, expectedConstants: []interface{}{1}, expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpSetGlobal, 1),
+				code.Make(code.OpGetGlobal, 1),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestLetStatements(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: "let num = 55; fn(){ num }",
+			expectedConstants: []interface{}{
+				55,
+				[]code.Instructions{
+					code.Make(code.OpGetGlobal, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn() { let a = 55; a; }",
+			expectedConstants: []interface{}{
+				55,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn(){let a = 1; let b = 2; a + b; }",
+			expectedConstants: []interface{}{
+				1,
+				2,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpSetLocal, 1),
+
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpGetLocal, 1),
+
+					code.Make(code.OpAdd),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestStringExpressions(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             `"monkey"`,
+			expectedConstants: []interface{}{"monkey"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             `"mon" + "key"`,
+			expectedConstants: []interface{}{"mon", "key"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             `"sum: ${1 + 2}"`,
+			expectedConstants: []interface{}{"sum: ", 1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpAdd),
+				code.Make(code.OpConcat, 2),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestArrayLiterals(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "[]",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpArray, 0),
 				code.Make(code.OpPop),
 			},
 		},
@@ -577,6 +1464,47 @@ func TestIndexExpressions(t *testing.T) {
 	runCompilerTests(t, tests)
 }
 
+func TestSliceAndRangeExpressions(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "[1, 2][1:]",
+			expectedConstants: []interface{}{1, 2, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpArray, 2),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpNull),
+				code.Make(code.OpSlice),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             `"abc"[:-1]`,
+			expectedConstants: []interface{}{"abc", 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpNull),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpMinus),
+				code.Make(code.OpSlice),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "1..10",
+			expectedConstants: []interface{}{1, 10},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpRange),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
 func TestFunctions(t *testing.T) {
 	tests := []compilerTestCase{
 		{
@@ -640,6 +1568,91 @@ func TestFunctionsWithoutReturnValue(t *testing.T) {
 	runCompilerTests(t, tests)
 }
 
+func TestFunctionArguments(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: "fn(a, b = 2) { a + b }(1, b: 3)",
+			expectedConstants: []interface{}{
+				2,
+				[]code.Instructions{
+					code.Make(code.OpSkipDefault, 1, 9),
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 1),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpGetLocal, 1),
+					code.Make(code.OpAdd),
+					code.Make(code.OpReturnValue),
+				},
+				1,
+				3,
+				[]string{"b"},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpConstant, 3),
+				code.Make(code.OpCallNamed, 2, 4),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn(a, ...rest) { rest }(1, 2)",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpGetLocal, 1),
+					code.Make(code.OpReturnValue),
+				},
+				1,
+				2,
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 0, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpCall, 2),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestFunctionSignatures(t *testing.T) {
+	tests := []struct {
+		input       string
+		params      []string
+		numDefaults int
+		rest        bool
+	}{
+		{"fn() {}", []string{}, 0, false},
+		{"fn(a, b) {}", []string{"a", "b"}, 0, false},
+		{"fn(a, b = 1, c = 2) {}", []string{"a", "b", "c"}, 2, false},
+		{"fn(a = 1, ...rest) {}", []string{"a"}, 1, true},
+	}
+
+	for _, tt := range tests {
+		compiler := New()
+		if err := compiler.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		constants := compiler.ByteCode().Constants
+		fn, ok := constants[len(constants)-1].(*object.CompiledFunction)
+		if !ok {
+			t.Fatalf("last constant is not a function: %T", constants[len(constants)-1])
+		}
+		if strings.Join(fn.Params, ",") != strings.Join(tt.params, ",") {
+			t.Errorf("wrong params for %q. want=%v, got=%v", tt.input, tt.params, fn.Params)
+		}
+		if fn.NumDefaults != tt.numDefaults {
+			t.Errorf("wrong NumDefaults for %q. want=%d, got=%d", tt.input, tt.numDefaults, fn.NumDefaults)
+		}
+		if fn.Rest != tt.rest {
+			t.Errorf("wrong Rest for %q. want=%t, got=%t", tt.input, tt.rest, fn.Rest)
+		}
+	}
+}
+
 func TestCompilerScopes(t *testing.T) {
 	compiler := New()
 	if compiler.scopeIndex != 0 {
@@ -786,7 +1799,7 @@ func TestBuiltins(t *testing.T) {
 				[]code.Instructions{
 					code.Make(code.OpGetBuiltin, 0),
 					code.Make(code.OpArray, 0),
-					code.Make(code.OpCall, 1),
+					code.Make(code.OpTailCall, 1),
 					code.Make(code.OpReturnValue),
 				},
 			},
@@ -811,7 +1824,7 @@ func TestClosures(t *testing.T) {
 					code.Make(code.OpAdd),
 					code.Make(code.OpReturnValue),
 				},
-				[]code.Instructions{code.Make(code.OpGetLocal, 0),
+				[]code.Instructions{code.Make(code.OpCaptureLocal, 0),
 					code.Make(code.OpClosure, 0, 1),
 					code.Make(code.OpReturnValue),
 				},
@@ -832,14 +1845,14 @@ func TestClosures(t *testing.T) {
 					code.Make(code.OpAdd),
 					code.Make(code.OpReturnValue),
 				},
-				[]code.Instructions{
-					code.Make(code.OpGetFree, 0),
-					code.Make(code.OpGetLocal, 0),
+				[]code.Instructions{
+					code.Make(code.OpCaptureFree, 0),
+					code.Make(code.OpCaptureLocal, 0),
 					code.Make(code.OpClosure, 0, 2),
 					code.Make(code.OpReturnValue),
 				},
 				[]code.Instructions{
-					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpCaptureLocal, 0),
 					code.Make(code.OpClosure, 1, 1),
 					code.Make(code.OpReturnValue),
 				},
@@ -871,15 +1884,15 @@ func TestClosures(t *testing.T) {
 				[]code.Instructions{
 					code.Make(code.OpConstant, 2),
 					code.Make(code.OpSetLocal, 0),
-					code.Make(code.OpGetFree, 0),
-					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpCaptureFree, 0),
+					code.Make(code.OpCaptureLocal, 0),
 					code.Make(code.OpClosure, 4, 2),
 					code.Make(code.OpReturnValue),
 				},
 				[]code.Instructions{
 					code.Make(code.OpConstant, 1),
 					code.Make(code.OpSetLocal, 0),
-					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpCaptureLocal, 0),
 					code.Make(code.OpClosure, 5, 1),
 					code.Make(code.OpReturnValue),
 				},
@@ -896,6 +1909,71 @@ func TestClosures(t *testing.T) {
 	runCompilerTests(t, tests)
 }
 
+func TestLoopUpvalues(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			// the closure captures i, so every iteration closes it
+			input: "fn() { for i in 0..2 { fn() { i } } }",
+			expectedConstants: []interface{}{
+				0,
+				2,
+				[]code.Instructions{
+					code.Make(code.OpGetFree, 0),
+					code.Make(code.OpReturnValue),
+				},
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpRange),
+					code.Make(code.OpGetIter),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpIterNext, 29),
+					code.Make(code.OpSetLocal, 1),
+					code.Make(code.OpCaptureLocal, 1),
+					code.Make(code.OpClosure, 2, 1),
+					code.Make(code.OpPop),
+					code.Make(code.OpCloseUpvalues, 1),
+					code.Make(code.OpJump, 10),
+					code.Make(code.OpReturn),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 3, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			// n is defined before the loop, iterations share it
+			input: "fn() { let n = 0; while (true) { fn() { n } } }",
+			expectedConstants: []interface{}{
+				0,
+				[]code.Instructions{
+					code.Make(code.OpGetFree, 0),
+					code.Make(code.OpReturnValue),
+				},
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpTrue),
+					code.Make(code.OpJumpFalse, 19),
+					code.Make(code.OpCaptureLocal, 0),
+					code.Make(code.OpClosure, 1, 1),
+					code.Make(code.OpPop),
+					code.Make(code.OpJump, 5),
+					code.Make(code.OpReturn),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
 func TestRecursiveFunctions(t *testing.T) {
 	tests := []compilerTestCase{
 		{
@@ -910,7 +1988,7 @@ func TestRecursiveFunctions(t *testing.T) {
 					code.Make(code.OpGetLocal, 0),
 					code.Make(code.OpConstant, 0),
 					code.Make(code.OpSub),
-					code.Make(code.OpCall, 1),
+					code.Make(code.OpTailCall, 1),
 					code.Make(code.OpReturnValue),
 				},
 				1,
@@ -939,7 +2017,7 @@ func TestRecursiveFunctions(t *testing.T) {
 					code.Make(code.OpGetLocal, 0),
 					code.Make(code.OpConstant, 0),
 					code.Make(code.OpSub),
-					code.Make(code.OpCall, 1),
+					code.Make(code.OpTailCall, 1),
 					code.Make(code.OpReturnValue),
 				},
 				1,
@@ -948,7 +2026,7 @@ func TestRecursiveFunctions(t *testing.T) {
 					code.Make(code.OpSetLocal, 0),
 					code.Make(code.OpGetLocal, 0),
 					code.Make(code.OpConstant, 2),
-					code.Make(code.OpCall, 1),
+					code.Make(code.OpTailCall, 1),
 					code.Make(code.OpReturnValue),
 				},
 			},
@@ -964,3 +2042,354 @@ func TestRecursiveFunctions(t *testing.T) {
 
 	runCompilerTests(t, tests)
 }
+
+func TestGenerators(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: "fn*() { let x = yield 1; yield }",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpYield),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpNull),
+					code.Make(code.OpYield),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			// a generator keeps its frame, so its calls never become tail calls
+			input: "let f = fn() { 1 }; fn*() { return f() }",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpReturnValue),
+				},
+				[]code.Instructions{
+					code.Make(code.OpGetGlobal, 0),
+					code.Make(code.OpCall, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+
+	for _, input := range []string{"yield 1", "fn*() { fn() { yield 1 } }"} {
+		err := New().Compile(parse(input))
+		if err == nil || err.Error() != "yield outside of a generator function" {
+			t.Errorf("wrong compiler error for %q. got=%v", input, err)
+		}
+	}
+}
+
+func TestSpawn(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: "spawn len([1], 2); spawn fn() { 3 }",
+			expectedConstants: []interface{}{
+				1,
+				2,
+				3,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 2),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpGetBuiltin, 0),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpArray, 1),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSpawn, 2),
+				code.Make(code.OpPop),
+				code.Make(code.OpClosure, 3, 0),
+				code.Make(code.OpSpawn, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			// spawn hands the call to another vm, so it is never a tail call
+			input: "fn(f) { spawn f() }",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpSpawn, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 0, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+
+	err := New().Compile(parse("let f = fn(a) { a }; spawn f(a: 1)"))
+	if err == nil || err.Error() != "spawned calls do not take named arguments" {
+		t.Errorf("wrong compiler error for named spawn arguments. got=%v", err)
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: "fn(f) { if (true) { f() } else { f(1) } }",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpTrue),
+					code.Make(code.OpJumpFalse, 11),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpTailCall, 0),
+					code.Make(code.OpJump, 18),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpTailCall, 1),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn(f) { f() + 1 }",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpCall, 0),
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpAdd),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn(f) { try { return f() } catch (e) { 1 } }",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpTry, 14),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpCall, 0),
+					code.Make(code.OpEndTry),
+					code.Make(code.OpReturnValue),
+					code.Make(code.OpNull),
+					code.Make(code.OpEndTry),
+					code.Make(code.OpJump, 19),
+					code.Make(code.OpSetLocal, 1),
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestUnexpandedMacros(t *testing.T) {
+	input := "let f = fn() { let m = macro(x) { x }; };"
+
+	compiler := New()
+	err := compiler.Compile(parse(input))
+	if err == nil {
+		t.Fatalf("expected compiler error but resulted in none.")
+	}
+
+	if err.Error() != "macro must be defined by a top level let statement" {
+		t.Errorf("wrong compiler error. got=%q", err)
+	}
+}
+
+func TestModules(t *testing.T) {
+	lib := &Module{
+		Path:    "lib.zeta",
+		Program: parse("let hidden = 1; export let x = hidden + 1;").(*ast.Program),
+	}
+	main := &Module{
+		Program: parse(`import "lib.zeta" as l; let hidden = 5; l.x;`).(*ast.Program),
+		Imports: map[string]string{"lib.zeta": "lib.zeta"},
+	}
+
+	compiler := New()
+	for _, m := range []*Module{lib, main} {
+		if err := compiler.CompileModule(m); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+	}
+
+	expectedInstructions := []code.Instructions{
+		code.Make(code.OpConstant, 0),
+		code.Make(code.OpSetGlobal, 0),
+		code.Make(code.OpGetGlobal, 0),
+		code.Make(code.OpConstant, 1),
+		code.Make(code.OpAdd),
+		code.Make(code.OpSetGlobal, 1),
+		code.Make(code.OpConstant, 2),
+		code.Make(code.OpSetGlobal, 2),
+		code.Make(code.OpGetGlobal, 1),
+		code.Make(code.OpPop),
+	}
+
+	bytecode := compiler.ByteCode()
+	if err := testInstructions(expectedInstructions, bytecode.Instructions); err != nil {
+		t.Fatalf("testInstructions failed: %s", err)
+	}
+	if err := testConstants(t, []interface{}{1, 1, 5}, bytecode.Constants); err != nil {
+		t.Fatalf("testConstants failed: %s", err)
+	}
+}
+
+func TestModuleErrors(t *testing.T) {
+	lib := "let hidden = 1; export let x = 2;"
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib.zeta" as l; l.hidden`, "hidden is not exported by module l"},
+		{`import "lib.zeta" as l; l`, "l is a module, use l.name to reach its exports"},
+		{`import "lib.zeta" as l; l = 1`, "cannot assign to module l"},
+		{`import "other.zeta" as o;`, `module "other.zeta" was not resolved`},
+		{`let f = fn() { import "lib.zeta" as l; };`, "import must be at the top level of a module"},
+		{`import "lib.zeta" as l; l.x = 1`, "cannot assign to l.x, exports of a module are read only"},
+	}
+
+	for _, tt := range tests {
+		compiler := New()
+		if err := compiler.CompileModule(&Module{Path: "lib.zeta", Program: parse(lib).(*ast.Program)}); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		main := &Module{Program: parse(tt.input).(*ast.Program), Imports: map[string]string{"lib.zeta": "lib.zeta"}}
+		err := compiler.CompileModule(main)
+		if err == nil {
+			t.Fatalf("expected compiler error for %q but resulted in none.", tt.input)
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong compiler error for %q. want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestCompilerErrorPositions(t *testing.T) {
+	input := "let a = 1;\nlet b = a + c;"
+
+	compiler := New()
+	err := compiler.Compile(parse(input))
+	if err == nil {
+		t.Fatalf("expected compiler error but resulted in none.")
+	}
+
+	diag, ok := err.(*errrs.Diagnostic)
+	if !ok {
+		t.Fatalf("compiler error is not a diagnostic. got=%T (%s)", err, err)
+	}
+
+	if diag.Msg != "undefined variable: c" {
+		t.Errorf("wrong error message. got=%q", diag.Msg)
+	}
+
+	if start := diag.Span.Start; start.Line != 2 || start.Column != 13 {
+		t.Errorf("wrong error position. want=2:13, got=%s", start)
+	}
+}
+
+func TestWideOperands(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < 70000; i++ {
+		fmt.Fprintf(&body, "%d; ", i)
+	}
+
+	compiler := New()
+	if err := compiler.Compile(parse("if (true) { " + body.String() + "} else { -1 }")); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	bytecode := compiler.ByteCode()
+	ins := bytecode.Instructions
+
+	if len(bytecode.Constants) < 70000 {
+		t.Fatalf("wrong number of constants. got=%d", len(bytecode.Constants))
+	}
+	if len(ins) <= 1<<16 {
+		t.Fatalf("instructions too short to need wide jumps. got=%d", len(ins))
+	}
+	if op, _, _ := code.Decode(ins[1:]); ins[1] != byte(code.OpWide) || op != code.OpJumpFalse {
+		t.Fatalf("jump over the consequence is not wide. got=%q", ins[1:].String())
+	}
+
+	boundaries := map[int]bool{len(ins): true}
+	var targets []int
+	for ip := 0; ip < len(ins); {
+		boundaries[ip] = true
+		op, operands, width := code.Decode(ins[ip:])
+		if i, ok := code.JumpOperand(op); ok {
+			targets = append(targets, operands[i])
+		}
+		ip += width
+	}
+	for _, target := range targets {
+		if !boundaries[target] {
+			t.Errorf("jump target %d is not the start of an instruction", target)
+		}
+	}
+
+	var locals strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&locals, "let v%d = %d; ", i, i)
+	}
+	compiler = New()
+	if err := compiler.Compile(parse("fn() { " + locals.String() + "v299 }")); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	fn, ok := compiler.ByteCode().Constants[len(compiler.ByteCode().Constants)-1].(*object.CompiledFunction)
+	if !ok {
+		t.Fatalf("last constant is not a function")
+	}
+	if fn.NumLocals != 300 {
+		t.Errorf("wrong number of locals. want=300, got=%d", fn.NumLocals)
+	}
+	for _, want := range []code.Instructions{code.Make(code.OpSetLocal, 299), code.Make(code.OpGetLocal, 299)} {
+		if want[0] != byte(code.OpWide) || !strings.Contains(string(fn.Instructions), string(want)) {
+			t.Errorf("missing wide instruction %q", want.String())
+		}
+	}
+}
+
+func TestOperandLimits(t *testing.T) {
+	compiler := New()
+	compiler.symbolTable.numDefinitions = global.GlobalSize
+	err := compiler.Compile(parse("let x = 1"))
+	if err == nil {
+		t.Fatalf("expected compiler error but resulted in none.")
+	}
+	if want := fmt.Sprintf("too many globals, the vm holds %d", global.GlobalSize); err.Error() != want {
+		t.Errorf("wrong compiler error. want=%q, got=%q", want, err)
+	}
+}
@@ -0,0 +1,92 @@
+package compiler
+
+import (
+	"zetsu/ast"
+	"zetsu/code"
+	"zetsu/errrs"
+	"zetsu/object"
+)
+
+// compileConst compiles a const statement. Literal values are kept in
+// the symbol and inlined where the constant is used, anything else is
+// stored in a slot the compiler refuses to assign to
+func (c *Compiler) compileConst(node *ast.LetStatement) error {
+	if c.symbolTable.IsConstant(node.Name.Value) {
+		return errrs.Errorf(node.Name.Span(), "cannot redefine constant %s", node.Name.Value)
+	}
+	if value := constantValue(node.Value); value != nil {
+		c.symbolTable.DefineConstant(node.Name.Value, value)
+		return nil
+	}
+
+	symbol := c.symbolTable.DefineConstant(node.Name.Value, nil)
+	if err := c.Compile(node.Value); err != nil {
+		return err
+	}
+	c.storeSymbol(symbol)
+	return nil
+}
+
+// define binds name in the current scope, the name of a constant
+// can't be bound a second time in the scope that defines it
+func (c *Compiler) define(name *ast.Identifier) (Symbol, error) {
+	if c.symbolTable.IsConstant(name.Value) {
+		return Symbol{}, errrs.Errorf(name.Span(), "cannot redefine constant %s", name.Value)
+	}
+	return c.symbolTable.Define(name.Value), nil
+}
+
+// defineBlock binds name for a single block, see DefineBlock. A
+// constant can't be rebound this way either
+func (c *Compiler) defineBlock(name *ast.Identifier) (Symbol, func(), error) {
+	if c.symbolTable.IsConstant(name.Value) {
+		return Symbol{}, nil, errrs.Errorf(name.Span(), "cannot redefine constant %s", name.Value)
+	}
+	symbol, restore := c.symbolTable.DefineBlock(name.Value)
+	return symbol, restore, nil
+}
+
+// constantValue returns the value of node when it is a literal, or a
+// negated number literal, and nil otherwise
+func constantValue(node ast.Expression) object.Object {
+	switch node := node.(type) {
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+	case *ast.BigIntegerLiteral:
+		return &object.BigInteger{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+	case *ast.Boolean:
+		return &object.Boolean{Value: node.Value}
+	case *ast.PrefixExpression:
+		if node.Operator != "-" {
+			return nil
+		}
+		switch right := node.Right.(type) {
+		case *ast.IntegerLiteral:
+			return &object.Integer{Value: -right.Value}
+		case *ast.BigIntegerLiteral:
+			return object.NegateInteger(&object.BigInteger{Value: right.Value})
+		case *ast.FloatLiteral:
+			return &object.Float{Value: -right.Value}
+		}
+	}
+	return nil
+}
+
+// loadValue emits the instructions that push the value of an inlined
+// constant
+func (c *Compiler) loadValue(value object.Object) {
+	switch value := value.(type) {
+	case *object.Boolean:
+		if value.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+	default:
+		c.emit(code.OpConstant, c.addConstant(value))
+	}
+}
@@ -0,0 +1,77 @@
+package compiler
+
+import (
+	"zetsu/ast"
+	"zetsu/errrs"
+	"zetsu/object"
+)
+
+// compileEnum binds the enum type to its name as an inlined constant,
+// so uses of its variants can be checked while compiling
+func (c *Compiler) compileEnum(node *ast.EnumStatement) error {
+	if c.symbolTable.IsConstant(node.Name.Value) {
+		return errrs.Errorf(node.Name.Span(), "cannot redefine constant %s", node.Name.Value)
+	}
+	if len(node.Variants) == 0 {
+		return errrs.Errorf(node.Span(), "enum %s has no variants", node.Name.Value)
+	}
+
+	seen := make(map[string]bool)
+	variants := make([]string, len(node.Variants))
+	for i, variant := range node.Variants {
+		if seen[variant.Value] {
+			return errrs.Errorf(variant.Span(), "duplicate variant %s in enum %s", variant.Value, node.Name.Value)
+		}
+		seen[variant.Value] = true
+		variants[i] = variant.Value
+	}
+	c.symbolTable.DefineConstant(node.Name.Value, &object.EnumType{Name: node.Name.Value, Variants: variants})
+	return nil
+}
+
+// compileVariant inlines the variant a member expression such as
+// `Status.Done` names, unknown variants are a compile error
+func (c *Compiler) compileVariant(enum *object.EnumType, node *ast.MemberExpression) error {
+	value, err := enum.Variant(node.Member.Value)
+	if err != nil {
+		return errrs.Errorf(node.Member.Span(), "%s", err)
+	}
+	c.loadValue(value)
+	return nil
+}
+
+// enumType returns the enum type node refers to, if it names one
+func (c *Compiler) enumType(node ast.Expression) (*object.EnumType, bool) {
+	ident, ok := node.(*ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+	symbol, ok := c.symbolTable.Resolve(ident.Value)
+	if !ok {
+		return nil, false
+	}
+	enum, ok := symbol.Value.(*object.EnumType)
+	return enum, ok
+}
+
+// patternVariant returns the enum and the variant a match pattern
+// compares against, if it is a variant
+func (c *Compiler) patternVariant(pattern ast.Expression) (*object.EnumType, string, bool) {
+	member, ok := pattern.(*ast.MemberExpression)
+	if !ok {
+		return nil, "", false
+	}
+	enum, ok := c.enumType(member.Object)
+	return enum, member.Member.Value, ok
+}
+
+// missingVariants lists the variants of enum that covered lacks
+func missingVariants(enum *object.EnumType, covered map[string]bool) []string {
+	missing := []string{}
+	for _, v := range enum.Variants {
+		if !covered[v] {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,198 @@
+package compiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"zetsu/ast"
+	"zetsu/code"
+	"zetsu/object"
+)
+
+// compileMatch keeps the subject in a slot of its own and tries the
+// arms in order. Each arm tests its whole pattern first, jumping to the
+// next arm as soon as a part doesn't fit, and only then binds names
+func (c *Compiler) compileMatch(node *ast.MatchExpression) error {
+	if err := c.Compile(node.Subject); err != nil {
+		return err
+	}
+	subject := c.symbolTable.Define(fmt.Sprintf("match@%d", len(c.currentInstructions())))
+	c.storeSymbol(subject)
+
+	catchAll := false
+	jumps := []int{}
+	// variants of the enum the arms compare against, a match covering
+	// all of them needs no _ arm
+	var enum *object.EnumType
+	covered := map[string]bool{}
+	for _, arm := range node.Arms {
+		if catchAll {
+			c.warnf(arm.Pattern.Span(), "unreachable match arm, an earlier arm matches every value")
+		}
+		if armEnum, variant, ok := c.patternVariant(arm.Pattern); ok && (enum == nil || enum == armEnum) {
+			enum = armEnum
+			covered[variant] = true
+		}
+
+		misses := []int{}
+		if err := c.compilePatternTest(arm.Pattern, subject, nil, &misses); err != nil {
+			return err
+		}
+		restores := []func(){}
+		err := c.compilePatternBindings(arm.Pattern, subject, nil, &restores)
+		if err == nil {
+			err = c.compileValueBlock(arm.Body)
+		}
+		for i := len(restores) - 1; i >= 0; i-- {
+			restores[i]()
+		}
+		if err != nil {
+			return err
+		}
+		jumps = append(jumps, c.emit(code.OpJump, 9999))
+
+		nextArm := len(c.currentInstructions())
+		for _, pos := range misses {
+			c.changeOperand(pos, nextArm)
+		}
+		catchAll = catchAll || arm.IsCatchAll()
+	}
+
+	switch {
+	case catchAll:
+	case enum != nil:
+		if missing := missingVariants(enum, covered); len(missing) > 0 {
+			c.warnf(node.Span(), "match on %s is missing variants %s", enum.Name, strings.Join(missing, ", "))
+		}
+	default:
+		c.warnf(node.Span(), "match has no _ arm, it is null for values no pattern fits")
+	}
+	c.emit(code.OpNull)
+
+	afterMatchPosition := len(c.currentInstructions())
+	for _, pos := range jumps {
+		c.changeOperand(pos, afterMatchPosition)
+	}
+	return nil
+}
+
+// compilePatternTest emits the checks of pattern against the part of
+// the subject path leads to, misses collects the jumps taken when a
+// check fails
+func (c *Compiler) compilePatternTest(pattern ast.Expression, subject Symbol, path []ast.Expression, misses *[]int) error {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		return nil
+
+	case *ast.ArrayLiteral:
+		if err := c.loadMatched(subject, path); err != nil {
+			return err
+		}
+		c.emit(code.OpMatchArray, len(pattern.Elements))
+		*misses = append(*misses, c.emit(code.OpJumpFalse, 9999))
+		for i, el := range pattern.Elements {
+			index := &ast.IntegerLiteral{Token: pattern.Token, Value: int64(i)}
+			if err := c.compilePatternTest(el, subject, extendPath(path, index), misses); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ast.HashLiteral:
+		if err := c.loadMatched(subject, path); err != nil {
+			return err
+		}
+		c.emit(code.OpMatchHash)
+		*misses = append(*misses, c.emit(code.OpJumpFalse, 9999))
+		for _, key := range sortedKeys(pattern) {
+			if err := c.loadMatched(subject, path); err != nil {
+				return err
+			}
+			if err := c.Compile(key); err != nil {
+				return err
+			}
+			c.emit(code.OpMatchKey)
+			*misses = append(*misses, c.emit(code.OpJumpFalse, 9999))
+			if err := c.compilePatternTest(pattern.Pairs[key], subject, extendPath(path, key), misses); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		if err := c.loadMatched(subject, path); err != nil {
+			return err
+		}
+		if err := c.Compile(pattern); err != nil {
+			return err
+		}
+		c.emit(code.OpMatchValue)
+		*misses = append(*misses, c.emit(code.OpJumpFalse, 9999))
+		return nil
+	}
+}
+
+// compilePatternBindings stores the parts of the subject that pattern
+// names, once the whole pattern is known to fit. The names are bound
+// for the arm only, restores collects what puts back the ones before
+func (c *Compiler) compilePatternBindings(pattern ast.Expression, subject Symbol, path []ast.Expression, restores *[]func()) error {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value == "_" {
+			return nil
+		}
+		if err := c.loadMatched(subject, path); err != nil {
+			return err
+		}
+		symbol, restore, err := c.defineBlock(pattern)
+		if err != nil {
+			return err
+		}
+		*restores = append(*restores, restore)
+		c.bindSymbol(symbol)
+
+	case *ast.ArrayLiteral:
+		for i, el := range pattern.Elements {
+			index := &ast.IntegerLiteral{Token: pattern.Token, Value: int64(i)}
+			if err := c.compilePatternBindings(el, subject, extendPath(path, index), restores); err != nil {
+				return err
+			}
+		}
+
+	case *ast.HashLiteral:
+		for _, key := range sortedKeys(pattern) {
+			if err := c.compilePatternBindings(pattern.Pairs[key], subject, extendPath(path, key), restores); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadMatched pushes the subject indexed by each key of path in turn
+func (c *Compiler) loadMatched(subject Symbol, path []ast.Expression) error {
+	c.loadSymbol(subject)
+	for _, key := range path {
+		if err := c.Compile(key); err != nil {
+			return err
+		}
+		c.emit(code.OpIndex)
+	}
+	return nil
+}
+
+// extendPath copies path, nested patterns must not share the array
+func extendPath(path []ast.Expression, key ast.Expression) []ast.Expression {
+	extended := make([]ast.Expression, len(path), len(path)+1)
+	copy(extended, path)
+	return append(extended, key)
+}
+
+func sortedKeys(hash *ast.HashLiteral) []ast.Expression {
+	keys := []ast.Expression{}
+	for k := range hash.Pairs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
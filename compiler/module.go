@@ -0,0 +1,72 @@
+package compiler
+
+import (
+	"zetsu/ast"
+	"zetsu/builtin"
+	"zetsu/errrs"
+)
+
+// Module is a parsed .zeta file ready to be compiled. Imports maps
+// the paths its import statements are written with to the Path of
+// the module each of them refers to
+type Module struct {
+	Path    string
+	Program *ast.Program
+	Imports map[string]string
+}
+
+// CompileModule compiles m into the instructions, constants and
+// globals shared by everything the compiler has compiled so far, so
+// that a program and its imports link into a single ByteCode. A module
+// has to be compiled after the modules it imports. The main program
+// has an empty Path and is compiled against the compiler's own symbol
+// table, imported modules get a table of their own so their names
+// don't clash
+func (c *Compiler) CompileModule(m *Module) error {
+	outer, imports, exports := c.symbolTable, c.imports, c.exports
+	defer func() { c.symbolTable, c.imports, c.exports = outer, imports, exports }()
+
+	c.imports = m.Imports
+	c.exports = map[string]Symbol{}
+	if m.Path == "" {
+		return c.Compile(m.Program)
+	}
+
+	table := NewSymbolTable()
+	for i, v := range builtin.Builtins {
+		table.DefineBuiltin(i, v.Name)
+	}
+	// globals of every module live in the same store
+	table.numDefinitions = outer.numDefinitions
+	c.symbolTable = table
+
+	err := c.Compile(m.Program)
+	outer.numDefinitions = table.numDefinitions
+	if err != nil {
+		return err
+	}
+
+	c.modules[m.Path] = c.exports
+	return nil
+}
+
+// compileExport loads an export of an imported module, `alias.name`
+func (c *Compiler) compileExport(node *ast.MemberExpression) error {
+	alias := node.Object.(*ast.Identifier).Value
+	symbol, ok := c.symbolTable.Resolve(alias + "." + node.Member.Value)
+	if !ok {
+		return errrs.Errorf(node.Member.Span(), "%s is not exported by module %s", node.Member.Value, alias)
+	}
+	c.loadSymbol(symbol)
+	return nil
+}
+
+// isNamespace reports whether node names an imported module
+func (c *Compiler) isNamespace(node ast.Expression) bool {
+	alias, ok := node.(*ast.Identifier)
+	if !ok {
+		return false
+	}
+	symbol, ok := c.symbolTable.Resolve(alias.Value)
+	return ok && symbol.Scope == NamespaceScope
+}
@@ -0,0 +1,36 @@
+package compiler
+
+import (
+	"zetsu/ast"
+	"zetsu/code"
+	"zetsu/errrs"
+)
+
+// compileSpawn compiles `spawn call`. The function and the arguments
+// of the call are pushed like for OpCall, OpSpawn then runs the call
+// as a task instead of in the current frame. Spawning anything but a
+// call calls it without arguments
+func (c *Compiler) compileSpawn(node *ast.SpawnExpression) error {
+	call, ok := node.Call.(*ast.CallExpression)
+	if !ok {
+		if err := c.Compile(node.Call); err != nil {
+			return err
+		}
+		c.emit(code.OpSpawn, 0)
+		return nil
+	}
+
+	if err := c.Compile(call.Function); err != nil {
+		return err
+	}
+	for _, arg := range call.Arguments {
+		if _, ok := arg.(*ast.NamedArgument); ok {
+			return errrs.Errorf(arg.Span(), "spawned calls do not take named arguments")
+		}
+		if err := c.Compile(arg); err != nil {
+			return err
+		}
+	}
+	c.emit(code.OpSpawn, len(call.Arguments))
+	return nil
+}
@@ -1,5 +1,7 @@
 package compiler
 
+import "zetsu/object"
+
 type SymbolScope string
 
 const (
@@ -8,12 +10,23 @@ const (
 	BuiltinScope  SymbolScope = "BUILTIN"
 	FreeScope     SymbolScope = "FREE"
 	FunctionScope SymbolScope = "FUNCTION"
+	// NamespaceScope marks the alias of an imported module, its
+	// exports are stored as "alias.name"
+	NamespaceScope SymbolScope = "NAMESPACE"
+	// CellScope marks the names a block binds at global scope, their
+	// global holds a cell that every run of the block replaces, so
+	// closures capture them the way they capture locals
+	CellScope SymbolScope = "CELL"
 )
 
 type Symbol struct {
 	Name  string
 	Scope SymbolScope
 	Index int
+	// Constant marks the symbols of const statements, Value holds
+	// the value of the ones the compiler inlines, they take no slot
+	Constant bool
+	Value    object.Object
 }
 
 type SymbolTable struct {
@@ -21,6 +34,8 @@ type SymbolTable struct {
 	store          map[string]Symbol
 	numDefinitions int
 	FreeSymbols    []Symbol
+	// captured holds the indexes of the locals inner functions capture
+	captured map[int]bool
 }
 
 func NewSymbolTable() *SymbolTable {
@@ -35,7 +50,13 @@ func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
 	return s
 }
 
+// Define binds name in this table, defining a name that was already
+// defined in the same table hands back its existing slot
 func (st *SymbolTable) Define(name string) Symbol {
+	if symbol, ok := st.store[name]; ok && (symbol.Scope == GlobalScope || symbol.Scope == LocalScope || symbol.Scope == CellScope) {
+		return symbol
+	}
+
 	symbol := Symbol{Name: name, Index: st.numDefinitions}
 	if st.Outer == nil {
 		symbol.Scope = GlobalScope
@@ -46,6 +67,29 @@ func (st *SymbolTable) Define(name string) Symbol {
 	st.numDefinitions++
 	return symbol
 }
+
+// DefineBlock binds name for one block only, the variable of a for-in
+// loop, a name in a match pattern or the parameter of a catch. It takes
+// a fresh slot even when name is defined, the returned func puts back
+// what name referred to before the block. At global scope the slot
+// holds a cell, see CellScope
+func (st *SymbolTable) DefineBlock(name string) (Symbol, func()) {
+	prev, had := st.store[name]
+	delete(st.store, name)
+	symbol := st.Define(name)
+	if symbol.Scope == GlobalScope {
+		symbol.Scope = CellScope
+		st.store[name] = symbol
+	}
+	return symbol, func() {
+		if had {
+			st.store[name] = prev
+		} else {
+			delete(st.store, name)
+		}
+	}
+}
+
 func (st *SymbolTable) Resolve(name string) (Symbol, bool) {
 	obj, ok := st.store[name]
 
@@ -55,9 +99,12 @@ func (st *SymbolTable) Resolve(name string) (Symbol, bool) {
 			return obj, ok
 		}
 
-		if obj.Scope == GlobalScope || obj.Scope == BuiltinScope {
+		if obj.Scope == GlobalScope || obj.Scope == BuiltinScope || obj.Scope == NamespaceScope || obj.Value != nil {
 			return obj, ok
 		}
+		if obj.Scope == LocalScope {
+			st.Outer.capture(obj.Index)
+		}
 
 		free := st.defineFree(obj)
 		return free, true
@@ -66,6 +113,31 @@ func (st *SymbolTable) Resolve(name string) (Symbol, bool) {
 	return obj, ok
 }
 
+// DefineConstant binds name as a constant. A constant with a value
+// known at compile time is inlined wherever it is used, the others get
+// a slot like any other definition
+func (st *SymbolTable) DefineConstant(name string, value object.Object) Symbol {
+	var symbol Symbol
+	if value == nil {
+		symbol = st.Define(name)
+	} else {
+		symbol = Symbol{Name: name, Scope: LocalScope, Value: value}
+		if st.Outer == nil {
+			symbol.Scope = GlobalScope
+		}
+	}
+	symbol.Constant = true
+	st.store[name] = symbol
+	return symbol
+}
+
+// IsConstant reports whether name is a constant defined in this table
+// rather than one captured from an enclosing scope
+func (st *SymbolTable) IsConstant(name string) bool {
+	symbol, ok := st.store[name]
+	return ok && symbol.Constant && symbol.Scope != FreeScope
+}
+
 func (st *SymbolTable) DefineBuiltin(index int, name string) Symbol {
 	symbol := Symbol{Name: name, Index: index, Scope: BuiltinScope}
 	st.store[name] = symbol
@@ -78,9 +150,38 @@ func (st *SymbolTable) DefineFunctionName(name string) Symbol {
 	return symbol
 }
 
+// DefineNamespace binds name to an imported module, members are the
+// global symbols the module exports
+func (st *SymbolTable) DefineNamespace(name string, members map[string]Symbol) Symbol {
+	symbol := Symbol{Name: name, Scope: NamespaceScope}
+	st.store[name] = symbol
+	for member, s := range members {
+		st.store[name+"."+member] = s
+	}
+	return symbol
+}
+
+func (st *SymbolTable) capture(index int) {
+	if st.captured == nil {
+		st.captured = make(map[int]bool)
+	}
+	st.captured[index] = true
+}
+
+// CapturesFrom reports whether an inner function captures one of the
+// locals from index on
+func (st *SymbolTable) CapturesFrom(index int) bool {
+	for captured := range st.captured {
+		if captured >= index {
+			return true
+		}
+	}
+	return false
+}
+
 func (st *SymbolTable) defineFree(original Symbol) Symbol {
 	st.FreeSymbols = append(st.FreeSymbols, original)
-	symbol := Symbol{Name: original.Name, Index: len(st.FreeSymbols) - 1}
+	symbol := Symbol{Name: original.Name, Index: len(st.FreeSymbols) - 1, Constant: original.Constant}
 	symbol.Scope = FreeScope
 	st.store[original.Name] = symbol
 	return symbol
@@ -1,6 +1,9 @@
 package compiler
 
-import "testing"
+import (
+	"testing"
+	"zetsu/object"
+)
 
 func TestDefine(t *testing.T) {
 	expected := map[string]Symbol{
@@ -45,6 +48,54 @@ func TestDefine(t *testing.T) {
 	}
 }
 
+func TestDefineExisting(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a")
+	global.Define("b")
+
+	expected := Symbol{Name: "a", Scope: GlobalScope, Index: 0}
+	if a := global.Define("a"); a != expected {
+		t.Errorf("expected a=%+v, got=%+v", expected, a)
+	}
+
+	local := NewEnclosedSymbolTable(global)
+	local.Define("c")
+	expected = Symbol{Name: "a", Scope: LocalScope, Index: 1}
+	if a := local.Define("a"); a != expected {
+		t.Errorf("expected a=%+v, got=%+v", expected, a)
+	}
+	expected = Symbol{Name: "c", Scope: LocalScope, Index: 0}
+	if c := local.Define("c"); c != expected {
+		t.Errorf("expected c=%+v, got=%+v", expected, c)
+	}
+}
+
+func TestDefineBlock(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a")
+
+	a, restore := global.DefineBlock("a")
+	expected := Symbol{Name: "a", Scope: CellScope, Index: 1}
+	if a != expected {
+		t.Errorf("expected a=%+v, got=%+v", expected, a)
+	}
+	b, restoreB := global.DefineBlock("b")
+	expected = Symbol{Name: "b", Scope: CellScope, Index: 2}
+	if b != expected {
+		t.Errorf("expected b=%+v, got=%+v", expected, b)
+	}
+
+	restoreB()
+	restore()
+	expected = Symbol{Name: "a", Scope: GlobalScope, Index: 0}
+	if a, ok := global.Resolve("a"); !ok || a != expected {
+		t.Errorf("expected a=%+v after the block, got=%+v", expected, a)
+	}
+	if _, ok := global.Resolve("b"); ok {
+		t.Errorf("b is still defined after the block")
+	}
+}
+
 func TestResolveGlobal(t *testing.T) {
 	global := NewSymbolTable()
 	global.Define("a")
@@ -318,3 +369,85 @@ func TestShadowingFunctionName(t *testing.T) {
 			expected.Name, expected, result)
 	}
 }
+
+func TestDefineResolveNamespace(t *testing.T) {
+	global := NewSymbolTable()
+	exported := Symbol{Name: "trim", Scope: GlobalScope, Index: 7}
+	global.DefineNamespace("s", map[string]Symbol{"trim": exported})
+
+	local := NewEnclosedSymbolTable(global)
+
+	ns, ok := local.Resolve("s")
+	if !ok || ns.Scope != NamespaceScope {
+		t.Fatalf("s not resolvable as namespace. got=%+v", ns)
+	}
+
+	result, ok := local.Resolve("s.trim")
+	if !ok {
+		t.Fatalf("name s.trim not resolvable")
+	}
+	if result != exported {
+		t.Errorf("expected s.trim to resolve to %+v, got=%+v", exported, result)
+	}
+
+	if len(local.FreeSymbols) != 0 {
+		t.Errorf("namespace members should not be free. got=%+v", local.FreeSymbols)
+	}
+}
+
+func TestDefineResolveConstant(t *testing.T) {
+	global := NewSymbolTable()
+	inlined := global.DefineConstant("a", &object.Integer{Value: 1})
+	stored := global.DefineConstant("b", nil)
+
+	if stored != (Symbol{Name: "b", Scope: GlobalScope, Index: 0, Constant: true}) {
+		t.Errorf("wrong symbol for b. got=%+v", stored)
+	}
+	if !global.IsConstant("a") || !global.IsConstant("b") {
+		t.Errorf("a and b should be constants")
+	}
+
+	outer := NewEnclosedSymbolTable(global)
+	local := outer.DefineConstant("c", &object.Integer{Value: 2})
+	inner := NewEnclosedSymbolTable(outer)
+
+	for _, expected := range []Symbol{inlined, local} {
+		result, ok := inner.Resolve(expected.Name)
+		if !ok {
+			t.Fatalf("name %s not resolvable", expected.Name)
+		}
+		if result != expected {
+			t.Errorf("expected %s to resolve to %+v, got=%+v", expected.Name, expected, result)
+		}
+	}
+	if len(inner.FreeSymbols) != 0 {
+		t.Errorf("inlined constants should not be free. got=%+v", inner.FreeSymbols)
+	}
+	if inner.IsConstant("c") {
+		t.Errorf("c is not a constant of the inner table")
+	}
+}
+
+func TestCapturesFrom(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("g")
+
+	outer := NewEnclosedSymbolTable(global)
+	outer.Define("a")
+	outer.Define("b")
+	outer.Define("c")
+
+	inner := NewEnclosedSymbolTable(outer)
+	inner.Resolve("g")
+	inner.Resolve("b")
+
+	if !outer.CapturesFrom(0) || !outer.CapturesFrom(1) {
+		t.Errorf("outer doesn't report b as captured")
+	}
+	if outer.CapturesFrom(2) {
+		t.Errorf("outer reports a local from c on as captured")
+	}
+	if global.CapturesFrom(0) {
+		t.Errorf("resolving a global marked it as captured")
+	}
+}
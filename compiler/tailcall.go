@@ -0,0 +1,40 @@
+package compiler
+
+import "zetsu/code"
+
+// markTailCalls turns the calls of a function body that are in tail
+// position into OpTailCall, so the vm can run them in the frame of the
+// caller. A call is in tail position when the next instruction to run
+// after it, following jumps, returns its value. Calls inside a try are
+// never in tail position since OpEndTry comes right after them
+func markTailCalls(ins code.Instructions) {
+	for pos := 0; pos < len(ins); {
+		op, _, read := code.Decode(ins[pos:])
+		next := pos + read
+
+		if op == code.OpCall && returnsAt(ins, next) {
+			if code.Opcode(ins[pos]) == code.OpWide {
+				pos++
+			}
+			ins[pos] = byte(code.OpTailCall)
+		}
+		pos = next
+	}
+}
+
+// returnsAt reports whether running ins from pos reaches OpReturnValue
+// through nothing but jumps
+func returnsAt(ins code.Instructions, pos int) bool {
+	for hops := 0; pos < len(ins) && hops < len(ins); hops++ {
+		op, operands, _ := code.Decode(ins[pos:])
+		switch op {
+		case code.OpReturnValue:
+			return true
+		case code.OpJump:
+			pos = operands[0]
+		default:
+			return false
+		}
+	}
+	return false
+}
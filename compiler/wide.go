@@ -0,0 +1,90 @@
+package compiler
+
+import (
+	"zetsu/code"
+	"zetsu/errrs"
+	"zetsu/global"
+)
+
+// checkOperands records an error for operands the vm can't take, the
+// first one recorded fails the compilation once the statement being
+// compiled is done
+func (c *Compiler) checkOperands(op code.Opcode, operands []int) {
+	if c.err != nil {
+		return
+	}
+	for _, operand := range operands {
+		if operand > code.MaxOperand {
+			c.err = errrs.Errorf(c.span, "program is too large, operand %d does not fit in an instruction", operand)
+			return
+		}
+	}
+	if (op == code.OpGetGlobal || op == code.OpSetGlobal) && operands[0] >= global.GlobalSize {
+		c.err = errrs.Errorf(c.span, "too many globals, the vm holds %d", global.GlobalSize)
+	}
+}
+
+// widenJumps gives the jumps of the current scope whose target turned
+// out too far for the operand they were emitted with the OpWide prefix.
+// Widening a jump moves every instruction after it, which can push the
+// targets of other jumps out of reach too, so the instructions are laid
+// out again until no jump has to be widened anymore
+func (c *Compiler) widenJumps() {
+	scope := &c.scopes[c.scopeIndex]
+	if len(scope.farJumps) == 0 {
+		return
+	}
+
+	type instruction struct {
+		pos      int
+		op       code.Opcode
+		operands []int
+	}
+	ins := scope.instructions
+	var list []instruction
+	for pos := 0; pos < len(ins); {
+		op, operands, read := code.Decode(ins[pos:])
+		if target, ok := scope.farJumps[pos]; ok {
+			index, _ := code.JumpOperand(op)
+			operands[index] = target
+		}
+		list = append(list, instruction{pos: pos, op: op, operands: operands})
+		pos += read
+	}
+
+	// moved maps the position of every instruction to where it is laid
+	// out, and the end of the instructions to the new end
+	moved := map[int]int{len(ins): len(ins)}
+	for _, in := range list {
+		moved[in.pos] = in.pos
+	}
+	for {
+		out := code.Instructions{}
+		next := make(map[int]int, len(moved))
+		for _, in := range list {
+			next[in.pos] = len(out)
+			operands := in.operands
+			if index, ok := code.JumpOperand(in.op); ok {
+				operands = append([]int{}, operands...)
+				operands[index] = moved[operands[index]]
+			}
+			out = append(out, code.Make(in.op, operands...)...)
+		}
+		next[len(ins)] = len(out)
+
+		if next[len(ins)] == moved[len(ins)] {
+			scope.instructions = out
+			break
+		}
+		moved = next
+	}
+
+	for i, mark := range scope.sourceMap {
+		if pos, ok := moved[mark.Offset]; ok {
+			scope.sourceMap[i].Offset = pos
+		}
+	}
+	scope.lastInstruction.Position = moved[scope.lastInstruction.Position]
+	scope.prevInstruction.Position = moved[scope.prevInstruction.Position]
+	scope.farJumps = nil
+}
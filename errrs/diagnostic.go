@@ -0,0 +1,28 @@
+package errrs
+
+import (
+	"fmt"
+	"zetsu/token"
+)
+
+// Diagnostic is an error that remembers which part of the source
+// code it is about, so that it can be reported as file:line:column
+// along with an excerpt of the offending line
+type Diagnostic struct {
+	Span token.Span
+	Msg  string
+}
+
+func (d *Diagnostic) Error() string { return d.Msg }
+
+// Errorf builds a Diagnostic for span
+func Errorf(span token.Span, format string, a ...interface{}) *Diagnostic {
+	return &Diagnostic{Span: span, Msg: fmt.Sprintf(format, a...)}
+}
+
+// Source is the code diagnostics are reported against, Name is
+// usually the path of a .zeta file
+type Source struct {
+	Name string
+	Text string
+}
@@ -1,23 +1,122 @@
 package errrs
 
-import "io"
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
 
-func PrintParseErrors(out io.Writer, msgs []string) {
+func PrintParseErrors(out io.Writer, src Source, diags []*Diagnostic) {
 	io.WriteString(out, "\nMutation gone wrong 😕. Below error messages may help!\n\n")
 	io.WriteString(out, "parser errors:")
-	for _, msg := range msgs {
-		io.WriteString(out, "\n\t"+msg+"\t\n")
+	for _, d := range diags {
+		writeDiagnostic(out, src, d)
 	}
 }
 
-func PrintCompilerError(out io.Writer, msg string) {
+func PrintImportError(out io.Writer, src Source, err error) {
+	io.WriteString(out, "\nModules didn't fit together 😕. Below error messages may help!\n\n")
+	io.WriteString(out, "import error:")
+	writeError(out, src, err)
+}
+
+func PrintMacroError(out io.Writer, src Source, err error) {
+	io.WriteString(out, "\nMacros wrote something odd 😕. Below error messages may help!\n\n")
+	io.WriteString(out, "macro error:")
+	writeError(out, src, err)
+}
+
+func PrintCompilerError(out io.Writer, src Source, err error) {
 	io.WriteString(out, "\nBytes are small but confusing 😕. Below error messages may help!\n\n")
 	io.WriteString(out, "compiler error:")
-	io.WriteString(out, "\n\t"+msg+"\t\n")
+	writeError(out, src, err)
 }
 
-func PrintMachineError(out io.Writer, msg string) {
+func PrintMachineError(out io.Writer, src Source, err error) {
 	io.WriteString(out, "\nEven machines aren't perfect 😕. Below error messages may help!\n\n")
 	io.WriteString(out, "vm error:")
-	io.WriteString(out, "\n\t"+msg+"\t\n")
+	writeError(out, src, err)
+}
+
+// PrintWarnings reports code that compiled but looks like a mistake
+func PrintWarnings(out io.Writer, src Source, diags []*Diagnostic) {
+	io.WriteString(out, "\nCompiled, but have a second look 🧐. Below messages may help!\n\n")
+	io.WriteString(out, "warnings:")
+	for _, d := range diags {
+		writeDiagnostic(out, src, d)
+	}
+}
+
+func writeError(out io.Writer, src Source, err error) {
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		writeDiagnostic(out, src, d)
+		return
+	}
+	io.WriteString(out, "\n\t"+err.Error()+"\t\n")
+}
+
+// writeDiagnostic prints d as "name:line:col: message" followed by the
+// source line it points at, with the offending span underlined
+func writeDiagnostic(out io.Writer, src Source, d *Diagnostic) {
+	start := d.Span.Start
+	if !start.IsValid() {
+		io.WriteString(out, "\n\t"+d.Msg+"\t\n")
+		return
+	}
+
+	// spans in imported modules name the file they come from
+	if file := d.Span.File; file != "" && file != src.Name {
+		src = Source{Name: file}
+		if data, err := os.ReadFile(file); err == nil {
+			src.Text = string(data)
+		}
+	}
+
+	location := start.String()
+	if src.Name != "" {
+		location = src.Name + ":" + location
+	}
+	io.WriteString(out, "\n\t"+location+": "+d.Msg+"\t\n")
+
+	if start.Offset > len(src.Text) {
+		return
+	}
+
+	lineStart := strings.LastIndexByte(src.Text[:start.Offset], '\n') + 1
+	lineEnd := strings.IndexByte(src.Text[start.Offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(src.Text)
+	} else {
+		lineEnd += start.Offset
+	}
+	line := src.Text[lineStart:lineEnd]
+
+	end := d.Span.End.Offset
+	if end > lineEnd {
+		end = lineEnd
+	}
+	if end < start.Offset {
+		end = start.Offset
+	}
+	// one caret per character, multi-byte characters get a single one
+	width := utf8.RuneCountInString(src.Text[start.Offset:end])
+	if width < 1 {
+		width = 1
+	}
+
+	// keep tabs in the padding so the carets line up with the excerpt
+	padding := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, src.Text[lineStart:start.Offset])
+
+	gutter := strings.Repeat(" ", len(strconv.Itoa(start.Line)))
+	io.WriteString(out, "\t"+strconv.Itoa(start.Line)+" | "+line+"\n")
+	io.WriteString(out, "\t"+gutter+" | "+padding+strings.Repeat("^", width)+"\n")
 }
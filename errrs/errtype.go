@@ -5,6 +5,8 @@ type ErrorType string
 const (
 	ERROR          = "ERROR"
 	PARSER_ERROR   = "PARSER ERROR"
+	IMPORT_ERROR   = "IMPORT ERROR"
+	MACRO_ERROR    = "MACRO ERROR"
 	COMPILER_ERROR = "COMPILER ERROR"
 	VM_ERROR       = "VM ERROR"
 )
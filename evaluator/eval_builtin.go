@@ -11,4 +11,5 @@ var builtins = map[string]*builtin.BuiltIn{
 	"rest":  builtin.GetBuiltinByName("rest"),
 	"push":  builtin.GetBuiltinByName("push"),
 	"puts":  builtin.GetBuiltinByName("puts"),
+	"range": builtin.GetBuiltinByName("range"),
 }
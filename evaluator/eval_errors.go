@@ -3,15 +3,43 @@ package evaluator
 import (
 	"fmt"
 	"zetsu/object"
+	"zetsu/token"
 )
 
 func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// isError reports whether obj is an error being raised, a caught one
+// is a plain value
 func isError(obj object.Object) bool {
-	if obj != nil {
-		return obj.Type() == object.ERROR_OBJ
+	_, ok := obj.(*object.Error)
+	return ok
+}
+
+// stackTrace lists the calls env is in, innermost first, the way the
+// vm does. span is where the innermost one is at, every call is at the
+// site of the one it made
+func stackTrace(env *object.Environment, span token.Span) []string {
+	trace := []string{}
+	for call := env.Call(); call != nil; call = call.Caller {
+		name := call.Name
+		if name == "" {
+			name = "<fn>"
+		}
+		trace = append(trace, traceEntry(name, span))
+		span = call.Site
+	}
+	return append(trace, traceEntry("<main>", span))
+}
+
+func traceEntry(name string, span token.Span) string {
+	switch {
+	case !span.Start.IsValid():
+		return name
+	case span.File != "":
+		return fmt.Sprintf("%s at %s:%s", name, span.File, span.Start)
+	default:
+		return fmt.Sprintf("%s at %s", name, span.Start)
 	}
-	return false
 }
@@ -1,6 +1,8 @@
 package evaluator
 
 import (
+	"math"
+	"strings"
 	"zetsu/ast"
 	"zetsu/object"
 )
@@ -19,6 +21,35 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
+// evalCallArguments evaluates the arguments of a call, the values of
+// named arguments come last in the same order as their names
+func evalCallArguments(exps []ast.Expression, env *object.Environment) ([]object.Object, []string) {
+	values := make([]ast.Expression, len(exps))
+	var names []string
+	for i, e := range exps {
+		values[i] = e
+		if named, ok := e.(*ast.NamedArgument); ok {
+			names = append(names, named.Name.Value)
+			values[i] = named.Value
+		}
+	}
+	return evalExpressions(values, env), names
+}
+
+// evalInterpolatedString joins what Inspect returns for each part, the
+// same way OpConcat does in the vm
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+	for _, part := range node.Parts {
+		evaluated := Eval(part, env)
+		if isError(evaluated) {
+			return evaluated
+		}
+		out.WriteString(evaluated.Inspect())
+	}
+	return &object.String{Value: out.String()}
+}
+
 func evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
 	case "!":
@@ -44,17 +75,25 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer, *object.BigInteger:
+		return object.NegateInteger(right)
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
 	switch {
-	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+	case object.IsInteger(left) && object.IsInteger(right):
 		return evalIntegerInfixExpression(operator, left, right)
+	case object.IsNumber(left) && object.IsNumber(right):
+		return evalFloatInfixExpression(operator, object.ToFloat(left), object.ToFloat(right))
+	case (left.Type() == object.ENUM_OBJ || right.Type() == object.ENUM_OBJ) && (operator == "==" || operator == "!="):
+		equal := left.Type() == right.Type() && left.(object.Hashable).HashKey() == right.(object.Hashable).HashKey()
+		return nativeBoolToBoolObject(equal == (operator == "=="))
 	case operator == "==":
 		return nativeBoolToBoolObject(left.Inspect() == right.Inspect())
 	case operator == "!=":
@@ -69,31 +108,80 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 }
 
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
-	leftVal := left.(*object.Integer).Value
-	rightVal := right.(*object.Integer).Value
+	switch operator {
+	case "+", "-", "*", "/", "%":
+		result, err := object.IntegerArithmetic(operator, left, right)
+		if err != nil {
+			return newError("%s", err)
+		}
+		return result
+	}
+
+	cmp := object.CompareIntegers(left, right)
+	switch operator {
+	case "<":
+		return nativeBoolToBoolObject(cmp < 0)
+	case ">":
+		return nativeBoolToBoolObject(cmp > 0)
+	case "<=":
+		return nativeBoolToBoolObject(cmp <= 0)
+	case ">=":
+		return nativeBoolToBoolObject(cmp >= 0)
+	case "==":
+		return nativeBoolToBoolObject(cmp == 0)
+	case "!=":
+		return nativeBoolToBoolObject(cmp != 0)
+	default:
+		return newError("unknown operator: %s%s%s", left.Type(), operator, right.Type())
+	}
+}
 
+func evalFloatInfixExpression(operator string, leftVal, rightVal float64) object.Object {
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		return &object.Float{Value: leftVal + rightVal}
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		return &object.Float{Value: leftVal - rightVal}
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		return &object.Float{Value: leftVal * rightVal}
 	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToBoolObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBoolObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBoolObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBoolObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBoolObject(leftVal == rightVal)
 	case "!=":
 		return nativeBoolToBoolObject(leftVal != rightVal)
 	default:
-		return newError("unknown operator: %s%s%s", left.Type(), operator, right.Type())
+		return newError("unknown operator: %s%s%s", object.FLOAT_OBJ, operator, object.FLOAT_OBJ)
 	}
 }
 
+// evalLogicalExpression only evaluates the right operand of && and
+// || when the left one does not decide the result
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	if isTruthy(left) == (node.Operator == "||") {
+		return nativeBoolToBoolObject(isTruthy(left))
+	}
+	right := Eval(node.Right, env)
+	if isError(right) {
+		return right
+	}
+	return nativeBoolToBoolObject(isTruthy(right))
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	if operator != "+" {
 		return newError("unknown operator: %s%s%s", left.Type(), operator, right.Type())
@@ -103,6 +191,80 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 	return &object.String{Value: lval + rval}
 }
 
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	current, ok := env.Get(node.Name.Value)
+	if !ok {
+		if _, ok := builtins[node.Name.Value]; ok {
+			return newError("cannot assign to builtin %s", node.Name.Value)
+		}
+		return newError("identifier not found: " + node.Name.Value)
+	}
+	if env.IsConst(node.Name.Value) {
+		return newError("cannot assign to constant %s", node.Name.Value)
+	}
+
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	if node.Operator != "=" {
+		val = evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, val)
+		if isError(val) {
+			return val
+		}
+	}
+
+	env.Assign(node.Name.Value, val)
+	return val
+}
+
+func evalIndexAssignExpression(node *ast.IndexAssignExpression, env *object.Environment) object.Object {
+	left := Eval(node.Target.Left, env)
+	if isError(left) {
+		return left
+	}
+	index := Eval(node.Target.Index, env)
+	if isError(index) {
+		return index
+	}
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+	if array, ok := left.(*object.Array); ok {
+		if index = arrayPosition(array, index); isError(index) {
+			return index
+		}
+	}
+
+	if node.Operator != "=" {
+		current := evalIndexExpression(left, index)
+		if isError(current) {
+			return current
+		}
+		val = evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, val)
+		if isError(val) {
+			return val
+		}
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		left.Elements[index.(*object.Integer).Value] = val
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: val}
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+
+	return val
+}
+
 func evalIfExpression(node *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(node.Condition, env)
 	if isError(condition) {
@@ -117,6 +279,20 @@ func evalIfExpression(node *ast.IfExpression, env *object.Environment) object.Ob
 	return NULL
 }
 
+// arrayPosition resolves the index an element of array is assigned
+// at, negative indexes count from the end
+func arrayPosition(array *object.Array, index object.Object) object.Object {
+	i, ok := index.(*object.Integer)
+	if !ok {
+		return newError("array index must be INTEGER, got %s", index.Type())
+	}
+	pos, ok := array.Position(i.Value)
+	if !ok {
+		return newError("index out of range: %d (length %d)", i.Value, len(array.Elements))
+	}
+	return &object.Integer{Value: pos}
+}
+
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
 	idx := index.(*object.Integer).Value
@@ -144,9 +320,65 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		if char, ok := left.(*object.String).At(index.(*object.Integer).Value); ok {
+			return char
+		}
+		return NULL
+	case left.Type() == object.RANGE_OBJ && index.Type() == object.INTEGER_OBJ:
+		if item, ok := left.(*object.Range).At(index.(*object.Integer).Value); ok {
+			return item
+		}
+		return NULL
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case isCaughtError(left) && index.Type() == object.STRING_OBJ:
+		if field, ok := left.(*caughtError).Field(index.(*object.String).Value); ok {
+			return field
+		}
+		return NULL
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
 }
+
+// evalSliceExpression evaluates `a[start:end]`, bounds that were left
+// out are passed to object.Slice as null
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	bounds := []object.Object{NULL, NULL}
+	for i, bound := range []ast.Expression{node.Start, node.End} {
+		if bound == nil {
+			continue
+		}
+		if bounds[i] = Eval(bound, env); isError(bounds[i]) {
+			return bounds[i]
+		}
+	}
+
+	slice, err := object.Slice(left, bounds[0], bounds[1])
+	if err != nil {
+		return newError("%s", err)
+	}
+	return slice
+}
+
+func evalRangeExpression(node *ast.RangeExpression, env *object.Environment) object.Object {
+	start := Eval(node.Start, env)
+	if isError(start) {
+		return start
+	}
+	end := Eval(node.End, env)
+	if isError(end) {
+		return end
+	}
+
+	rng, err := object.NewRange(start, end, &object.Integer{Value: 1})
+	if err != nil {
+		return newError("%s", err)
+	}
+	return rng
+}
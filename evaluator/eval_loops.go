@@ -0,0 +1,98 @@
+package evaluator
+
+import (
+	"zetsu/ast"
+	"zetsu/object"
+)
+
+func evalWhileStatement(node *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return nil
+		}
+		if res, done := evalLoopBody(node.Body, env); done {
+			return res
+		}
+	}
+}
+
+func evalForStatement(node *ast.ForStatement, env *object.Environment) object.Object {
+	if node.Init != nil {
+		if init := Eval(node.Init, env); isError(init) {
+			return init
+		}
+	}
+
+	for {
+		if node.Condition != nil {
+			condition := Eval(node.Condition, env)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return nil
+			}
+		}
+		if res, done := evalLoopBody(node.Body, env); done {
+			return res
+		}
+		if node.Post != nil {
+			if post := Eval(node.Post, env); isError(post) {
+				return post
+			}
+		}
+	}
+}
+
+func evalForInStatement(node *ast.ForInStatement, env *object.Environment) object.Object {
+	if env.DefinesConst(node.Variable.Value) {
+		return newError("cannot redefine constant %s", node.Variable.Value)
+	}
+	iterable := Eval(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+	iterator, ok := object.NewIterator(iterable)
+	if !ok {
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	for {
+		item, ok := iterator.Next()
+		if !ok {
+			if err := iterator.Err(); err != nil {
+				return newError("%s", err)
+			}
+			return nil
+		}
+		scope := object.NewBlockEnvironment(env)
+		scope.Bind(node.Variable.Value, item)
+		if res, done := evalLoopBody(node.Body, scope); done {
+			return res
+		}
+	}
+}
+
+// evalLoopBody runs one iteration of a loop, done reports whether
+// the loop has to stop, res is then what the loop evaluates to
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (res object.Object, done bool) {
+	res = Eval(body, env)
+	if res == nil {
+		return nil, false
+	}
+	if isError(res) {
+		return res, true
+	}
+	switch res.Type() {
+	case object.RETURN_VALUE_OBJ:
+		return res, true
+	case object.BREAK_OBJ:
+		return nil, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,119 @@
+package evaluator
+
+import (
+	"zetsu/ast"
+	"zetsu/object"
+)
+
+func evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	for _, arm := range node.Arms {
+		if err := checkPatternNames(arm.Pattern, env); err != nil {
+			return err
+		}
+	}
+	subject := Eval(node.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range node.Arms {
+		bindings := make(map[string]object.Object)
+		matched, err := matchPattern(arm.Pattern, subject, bindings, env)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+
+		scope := object.NewBlockEnvironment(env)
+		for name, value := range bindings {
+			scope.Bind(name, value)
+		}
+		res := Eval(arm.Body, scope)
+		if res == nil {
+			return NULL
+		}
+		return res
+	}
+	return NULL
+}
+
+// checkPatternNames returns an error when pattern binds the name of a
+// constant, the compiler rejects these matches as well
+func checkPatternNames(pattern ast.Expression, env *object.Environment) object.Object {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" && env.DefinesConst(pattern.Value) {
+			return newError("cannot redefine constant %s", pattern.Value)
+		}
+	case *ast.ArrayLiteral:
+		for _, el := range pattern.Elements {
+			if err := checkPatternNames(el, env); err != nil {
+				return err
+			}
+		}
+	case *ast.HashLiteral:
+		for _, value := range pattern.Pairs {
+			if err := checkPatternNames(value, env); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchPattern reports whether value fits pattern, collecting the
+// names the pattern binds. Nothing is bound unless the whole pattern fits
+func matchPattern(pattern ast.Expression, value object.Object, bindings map[string]object.Object, env *object.Environment) (bool, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			bindings[pattern.Value] = value
+		}
+		return true, nil
+
+	case *ast.ArrayLiteral:
+		array, ok := value.(*object.Array)
+		if !ok || len(array.Elements) != len(pattern.Elements) {
+			return false, nil
+		}
+		for i, el := range pattern.Elements {
+			if matched, err := matchPattern(el, array.Elements[i], bindings, env); !matched || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case *ast.HashLiteral:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return false, nil
+		}
+		for keyNode, valueNode := range pattern.Pairs {
+			key := Eval(keyNode, env)
+			if isError(key) {
+				return false, key
+			}
+			pair, ok := hash.Pairs[key.(object.Hashable).HashKey()]
+			if !ok {
+				return false, nil
+			}
+			if matched, err := matchPattern(valueNode, pair.Value, bindings, env); !matched || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+
+	default:
+		literal := Eval(pattern, env)
+		if isError(literal) {
+			return false, literal
+		}
+		if literal.Type() != value.Type() {
+			return false, nil
+		}
+		hashable, ok := value.(object.Hashable)
+		return ok && hashable.HashKey() == literal.(object.Hashable).HashKey(), nil
+	}
+}
@@ -0,0 +1,118 @@
+package evaluator
+
+import (
+	"strings"
+	"zetsu/ast"
+	"zetsu/object"
+)
+
+func evalStructStatement(node *ast.StructStatement, env *object.Environment) object.Object {
+	seen := make(map[string]bool)
+	fields := make([]string, len(node.Fields))
+	for i, field := range node.Fields {
+		if seen[field.Value] {
+			return newError("duplicate field %s in struct %s", field.Value, node.Name.Value)
+		}
+		seen[field.Value] = true
+		fields[i] = field.Value
+	}
+	env.Set(node.Name.Value, &object.StructType{Name: node.Name.Value, Fields: fields})
+	return nil
+}
+
+func evalEnumStatement(node *ast.EnumStatement, env *object.Environment) object.Object {
+	if env.DefinesConst(node.Name.Value) {
+		return newError("cannot redefine constant %s", node.Name.Value)
+	}
+	seen := make(map[string]bool)
+	variants := make([]string, len(node.Variants))
+	for i, variant := range node.Variants {
+		if seen[variant.Value] {
+			return newError("duplicate variant %s in enum %s", variant.Value, node.Name.Value)
+		}
+		seen[variant.Value] = true
+		variants[i] = variant.Value
+	}
+	env.SetConst(node.Name.Value, &object.EnumType{Name: node.Name.Value, Variants: variants})
+	return nil
+}
+
+func evalStructLiteral(node *ast.StructLiteral, env *object.Environment) object.Object {
+	def := Eval(node.Struct, env)
+	if isError(def) {
+		return def
+	}
+	structType, ok := def.(*object.StructType)
+	if !ok {
+		return newError("not a struct: %s", def.Type())
+	}
+
+	fields := make([]string, len(node.Fields))
+	for i, field := range node.Fields {
+		fields[i] = field.Value
+	}
+	values := evalExpressions(node.Values, env)
+	if len(values) == 1 && isError(values[0]) {
+		return values[0]
+	}
+
+	structValue, err := structType.New(fields, values, NULL)
+	if err != nil {
+		return newError("%s", err)
+	}
+	return structValue
+}
+
+func evalMemberExpression(node *ast.MemberExpression, env *object.Environment) object.Object {
+	left := Eval(node.Object, env)
+	if isError(left) {
+		return left
+	}
+	var value object.Object
+	var err error
+	switch left := left.(type) {
+	case *object.Struct:
+		value, err = left.Get(node.Member.Value)
+	case *object.EnumType:
+		value, err = left.Variant(node.Member.Value)
+	case *object.EnumValue:
+		value, err = left.Get(node.Member.Value)
+	default:
+		return newError("field access not supported: %s", left.Type())
+	}
+	if err != nil {
+		return newError("%s", err)
+	}
+	return value
+}
+
+func evalFieldAssignExpression(node *ast.FieldAssignExpression, env *object.Environment) object.Object {
+	left := Eval(node.Target.Object, env)
+	if isError(left) {
+		return left
+	}
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	structValue, ok := left.(*object.Struct)
+	if !ok {
+		return newError("field assignment not supported: %s", left.Type())
+	}
+	if node.Operator != "=" {
+		current, err := structValue.Get(node.Target.Member.Value)
+		if err != nil {
+			return newError("%s", err)
+		}
+		val = evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, val)
+		if isError(val) {
+			return val
+		}
+	}
+
+	if err := structValue.Set(node.Target.Member.Value, val); err != nil {
+		return newError("%s", err)
+	}
+	return val
+}
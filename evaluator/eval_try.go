@@ -0,0 +1,63 @@
+package evaluator
+
+import (
+	"zetsu/ast"
+	"zetsu/object"
+)
+
+// caughtError is an error a catch block got hold of. An *object.Error
+// unwinds the evaluation, this one is a plain value until it's thrown
+// again. Its type is still ERROR_OBJ, as in the vm
+type caughtError struct {
+	*object.Error
+}
+
+func isCaughtError(obj object.Object) bool {
+	_, ok := obj.(*caughtError)
+	return ok
+}
+
+func evalThrowStatement(node *ast.ThrowStatement, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	switch val := val.(type) {
+	case *caughtError:
+		return val.Error
+	case *object.String:
+		return &object.Error{Message: val.Value}
+	default:
+		return &object.Error{Message: val.Inspect()}
+	}
+}
+
+func evalTryExpression(node *ast.TryExpression, env *object.Environment) object.Object {
+	if node.Param != nil && env.DefinesConst(node.Param.Value) {
+		return newError("cannot redefine constant %s", node.Param.Value)
+	}
+	res := Eval(node.Block, env)
+	if errObj, ok := res.(*object.Error); ok && node.Catch != nil {
+		scope := object.NewBlockEnvironment(env)
+		if node.Param != nil {
+			scope.Bind(node.Param.Value, &caughtError{errObj})
+		}
+		res = Eval(node.Catch, scope)
+	}
+
+	if node.Finally != nil {
+		fin := Eval(node.Finally, env)
+		if fin != nil {
+			ft := fin.Type()
+			if ft == object.RETURN_VALUE_OBJ || isError(fin) || ft == object.BREAK_OBJ || ft == object.CONTINUE_OBJ {
+				return fin
+			}
+		}
+	}
+
+	if res == nil {
+		return NULL
+	}
+	return res
+}
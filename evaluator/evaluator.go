@@ -7,20 +7,38 @@ import (
 )
 
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL     = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
+// Eval evaluates n in env. An error gets the stack of the calls env is
+// in when it comes out of the node that raised it, see stackTrace
 func Eval(n ast.Node, env *object.Environment) object.Object {
+	obj := eval(n, env)
+	if err, ok := obj.(*object.Error); ok && err.Stack == nil {
+		err.Stack = stackTrace(env, n.Span())
+	}
+	return obj
+}
+
+func eval(n ast.Node, env *object.Environment) object.Object {
 	switch node := n.(type) {
 
 	/// ---------- expressions ---------- ///
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 
+	case *ast.BigIntegerLiteral:
+		return &object.BigInteger{Value: node.Value}
+
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
 	case *ast.Boolean:
-		return &object.Boolean{Value: node.Value}
+		return nativeBoolToBoolObject(node.Value)
 
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
@@ -30,6 +48,9 @@ func Eval(n ast.Node, env *object.Environment) object.Object {
 		return evalPrefixExpression(node.Operator, right)
 
 	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env)
+		}
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -46,12 +67,33 @@ func Eval(n ast.Node, env *object.Environment) object.Object {
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+
+	case *ast.IndexAssignExpression:
+		return evalIndexAssignExpression(node, env)
+
 	case *ast.FunctionLiteral:
-		params := node.Parameters
-		body := node.Body
-		return &object.Function{Parameters: params, Env: env, Body: body}
+		if node.Generator {
+			return newError("generators are not supported by the evaluator")
+		}
+		return &object.Function{
+			Name:       node.Name,
+			Parameters: node.Parameters,
+			Defaults:   node.Defaults,
+			Rest:       node.Rest,
+			Env:        env,
+			Body:       node.Body,
+		}
+	case *ast.YieldExpression:
+		// generators only run in the vm, see the fn* case above
+		return newError("yield outside of a generator function")
+	case *ast.SpawnExpression:
+		return newError("spawn is not supported by the evaluator")
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
 	case *ast.CallExpression:
 		if node.Function.TokenLiteral() == "quote" {
 			return quote(node.Arguments[0], env)
@@ -60,11 +102,11 @@ func Eval(n ast.Node, env *object.Environment) object.Object {
 		if isError(function) {
 			return function
 		}
-		args := evalExpressions(node.Arguments, env)
+		args, names := evalCallArguments(node.Arguments, env)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return applyFunction(function, args, names, &object.Call{Site: node.Span(), Caller: env.Call()})
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
@@ -81,8 +123,22 @@ func Eval(n ast.Node, env *object.Environment) object.Object {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case *ast.RangeExpression:
+		return evalRangeExpression(node, env)
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+	case *ast.StructLiteral:
+		return evalStructLiteral(node, env)
+	case *ast.MemberExpression:
+		return evalMemberExpression(node, env)
+	case *ast.FieldAssignExpression:
+		return evalFieldAssignExpression(node, env)
 
 	/// ---------- statements ---------- ///
 	case *ast.Program:
@@ -101,12 +157,49 @@ func Eval(n ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.ReturnValue{Value: val}
 
+	case *ast.StructStatement:
+		return evalStructStatement(node, env)
+	case *ast.EnumStatement:
+		return evalEnumStatement(node, env)
+
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+
 	case *ast.LetStatement:
+		if env.DefinesConst(node.Name.Value) {
+			return newError("cannot redefine constant %s", node.Name.Value)
+		}
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if node.IsConst() {
+			env.SetConst(node.Name.Value, val)
+		} else {
+			env.Set(node.Name.Value, val)
+		}
+
+	case *ast.ExportStatement:
+		return Eval(node.Statement, env)
+
+	case *ast.ImportStatement:
+		// modules are linked by the compiler, see package resolver
+		return newError("import is not supported by the evaluator: %s", node.Path)
+
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env)
+
+	case *ast.BreakStatement:
+		return BREAK
+
+	case *ast.ContinueStatement:
+		return CONTINUE
 	}
 	return nil
 }
@@ -121,6 +214,10 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 			return res.Value
 		case *object.Error:
 			return res
+		case *object.Break:
+			return newError("break outside of loop")
+		case *object.Continue:
+			return newError("continue outside of loop")
 		}
 	}
 	return res
@@ -132,7 +229,7 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 		res = Eval(stmt, env)
 		if res != nil {
 			rt := res.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || isError(res) || rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
 				return res
 			}
 		}
@@ -157,13 +254,30 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	return newError("identifier not found: " + node.Value)
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// applyFunction calls fn, names holds the parameter names of the
+// trailing named arguments in args. call is where fn is called from,
+// the call of a function gets its name
+func applyFunction(fn object.Object, args []object.Object, names []string, call *object.Call) object.Object {
 	switch fun := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fun, args)
+		extendedEnv, err := extendFunctionEnv(fun, args, names)
+		if err != nil {
+			return err
+		}
+		call.Name = fun.Name
+		extendedEnv.EnterCall(call)
 		evaluated := Eval(fun.Body, extendedEnv)
+		switch evaluated.(type) {
+		case *object.Break:
+			return newError("break outside of loop")
+		case *object.Continue:
+			return newError("continue outside of loop")
+		}
 		return unwrapReturnValue(evaluated)
 	case *builtin.BuiltIn:
+		if len(names) > 0 {
+			return newError("builtin functions do not take named arguments")
+		}
 		if result := fun.Fn(args...); result != nil {
 			return result
 		}
@@ -173,17 +287,59 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 	}
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+// extendFunctionEnv binds the arguments of a call to the parameters of
+// fn. Named arguments bind by name, extra positional ones are packed
+// into the rest array and defaults are evaluated in order, after the
+// arguments, so they can refer to the parameters before them
+func extendFunctionEnv(fn *object.Function, args []object.Object, names []string) (*object.Environment, *object.Error) {
+	positional := len(args) - len(names)
+	if positional > len(fn.Parameters) && fn.Rest == nil {
+		return nil, newError("wrong number of arguments to %s. want=%d, got=%d", fn.DisplayName(), len(fn.Parameters), len(args))
+	}
+
+	params := make([]object.Object, len(fn.Parameters))
+	bound := copy(params, args[:positional])
+
+	for i, name := range names {
+		index := -1
+		for j, param := range fn.Parameters {
+			if param.Value == name {
+				index = j
+			}
+		}
+		if index < 0 {
+			return nil, newError("%s has no parameter named %s", fn.DisplayName(), name)
+		}
+		if params[index] != nil {
+			return nil, newError("%s got more than one value for parameter %s", fn.DisplayName(), name)
+		}
+		params[index] = args[positional+i]
+	}
+
 	env := object.NewEnclosedEnvironement(fn.Env)
-	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+	if fn.Rest != nil {
+		rest := append([]object.Object{}, args[bound:positional]...)
+		env.Set(fn.Rest.Value, &object.Array{Elements: rest})
+	}
+	for i, param := range fn.Parameters {
+		value := params[i]
+		if value == nil {
+			if i >= len(fn.Defaults) || fn.Defaults[i] == nil {
+				return nil, newError("%s is missing an argument for parameter %s", fn.DisplayName(), param.Value)
+			}
+			value = Eval(fn.Defaults[i], env)
+			if isError(value) {
+				return nil, value.(*object.Error)
+			}
+		}
+		env.Set(param.Value, value)
 	}
-	return env
+	return env, nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
 	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue
+		return returnValue.Value
 	}
 	return obj
 }
@@ -33,6 +33,32 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"2.5", 2.5},
+		{"-2.5", -2.5},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"7 / 2.0", 3.5},
+		{"1.25e2 - 25", 100},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		result, ok := evaluated.(*object.Float)
+		if !ok {
+			t.Errorf("object is not Float. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if result.Value != tt.expected {
+			t.Errorf("object has wrong value. got=%g, want=%g", result.Value, tt.expected)
+		}
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -44,6 +70,36 @@ func TestEvalBooleanExpression(t *testing.T) {
 	}
 }
 
+func TestComparisonAndLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"1 <= 2", true},
+		{"3 <= 2", false},
+		{"2 >= 2", true},
+		{"2.5 >= 2", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"1 && 2", true},
+		{"let calls = 0; let f = fn() { calls += 1; true }; false && f(); calls", 0},
+		{"let calls = 0; let f = fn() { calls += 1; true }; true || f(); calls", 0},
+		{"let calls = 0; let f = fn() { calls += 1; true }; true && f(); calls", 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
 func TestBangOperator(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -82,7 +138,7 @@ func TestBangOperator(t *testing.T) {
 	}
 }
 
-func TestiFElseExpressions(t *testing.T) {
+func TestIfElseExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected interface{}
@@ -117,6 +173,7 @@ func TestReturnStatements(t *testing.T) {
 		{"return 2 * 5; 9;", 10},
 		{"9; return 2 * 5; 9;", 10},
 		{"if(10 > 1){ if (10 > 1) { return 1; } return 10; }", 1},
+		{"let f = fn() { return 1; }; f() + 1", 2},
 	}
 
 	for _, tt := range tests {
@@ -140,6 +197,11 @@ func TestErrorHandling(t *testing.T) {
 		{"foobar", "identifier not found: foobar"},
 		{`"Hello" - "World"`, "unknown operator: STRING-STRING"},
 		{`{"name": "Monkey"}[fn(x) { x }];`, "unusable as hash key: FUNCTION"},
+		{"fn() { 1 }(1)", "wrong number of arguments to fn. want=0, got=1"},
+		{"let add = fn(a, b) { a + b }; add(1)", "add is missing an argument for parameter b"},
+		{"let add = fn(a, b = 1) { a + b }; add(1, c: 2)", "add has no parameter named c"},
+		{"let add = fn(a, b = 1) { a + b }; add(1, a: 2)", "add got more than one value for parameter a"},
+		{"len(a: [1])", "builtin functions do not take named arguments"},
 	}
 
 	for _, tt := range tests {
@@ -156,6 +218,246 @@ func TestErrorHandling(t *testing.T) {
 	}
 }
 
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"while (true) { break; }; 1", 1},
+		{"let first = fn(xs) { for x in xs { return x; } }; first([7, 8])", 7},
+		{"let f = fn(xs) { for x in xs { if (x < 3) { continue; } return x; } }; f([1, 2, 3, 4])", 3},
+		{"let f = fn() { for (;;) { break; }; 5 }; f()", 5},
+		{"let i = 0; while (i < 10) { i = i + 1; }; i", 10},
+		{"let i = 0; while (false) { i = i + 1; }; i", 0},
+		{"let sum = 0; for (let i = 1; i < 5; i = i + 1) { sum = sum + i; }; sum", 10},
+		{"let sum = 0; for x in [1, 2, 3] { sum = sum + x; }; sum", 6},
+		{`let s = ""; for k in {"b": 2, "a": 1} { s = s + k; }; s`, "ab"},
+		{"let i = 0; while (true) { i = i + 1; if (i == 3) { break; } }; i", 3},
+		{"let n = 0; for (let i = 0; i < 10; i = i + 1) { if (i > 4) { continue; } n = n + 1; }; n", 5},
+		{"let n = 0; for x in [1, 2] { for y in [10, 20] { if (y == 20) { break; } n = n + x * y; } }; n", 30},
+		{"let inc = fn() { n = n + 1 }; let n = 0; for (;;) { inc(); if (n == 7) { break; } }; n", 7},
+		{"let find = fn(xs) { for x in xs { if (x > 1) { return x; } } }; find([1, 2, 3])", 2},
+		{"break;", "break outside of loop"},
+		{"let f = fn() { continue; }; while (true) { f(); }", "continue outside of loop"},
+		{"x = 1;", "identifier not found: x"},
+		{"for x in 1 { }", "cannot iterate over INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value. got=%q, want=%q", str.Value, expected)
+				}
+				continue
+			}
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`try { 1 } catch (e) { 2 }`, 1},
+		{`try { throw "boom"; 1 } catch (e) { e["message"] }`, "boom"},
+		{`try { 1 + true } catch (e) { e["message"] }`, "type mismatch: INTEGER+BOOLEAN"},
+		{`let f = fn(a) { a }; try { f(1, 2) } catch (e) { e["message"] }`, "wrong number of arguments to f. want=1, got=2"},
+		{`try { len(1) } catch { 3 }`, 3},
+		{`try { [1, 2][-5]; "ok" } catch (e) { e["message"] }`, "ok"},
+		{`let a = 0; try { throw "x" } catch { a = 1 } finally { a = a + 10 }; a`, 11},
+		{`let a = 0; let r = try { try { throw "x" } finally { a = 5 } } catch (e) { e["message"] }; r + "${a}"`, "x5"},
+		{`try { try { throw "in" } catch (e) { throw e } } catch (e) { e["message"] }`, "in"},
+		{`let f = fn() { try { return 1 } finally { 2 }; 3 }; f()`, 1},
+		{`throw "boom"`, "boom"},
+		{`try { throw "x" } catch (e) { throw e["message"] + "!" }`, "x!"},
+		{`let inner = fn() { 1 + "a" }; let outer = fn() { inner() + 1 }; try { outer() } catch (e) { len(e["stack"]) }`, 3},
+		{`let inner = fn() { 1 + "a" }; let outer = fn() { inner() + 1 }; try { outer() } catch (e) { "${e["stack"]}" }`, "[inner at 1:20, outer at 1:50, <main> at 1:71]"},
+		{`let f = fn() { throw "x" }; let g = fn() { try { f() } catch (e) { len(e["stack"]) } }; g()`, 3},
+		{`try { throw "x" } catch (e) { "${e["stack"]}" }`, "[<main> at 1:7]"},
+		{`try { throw "x" } catch (e) { e.message }`, "field access not supported: ERROR_OBJ"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value. got=%q, want=%q", str.Value, expected)
+				}
+				continue
+			}
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestStructs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x + p.y`, 3},
+		{`struct Point { x, y }; let p = Point{y: 2, x: 1}; "${p}"`, "Point{x: 1, y: 2}"},
+		{`struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x = 10; p.y += 5; p.x + p.y`, 17},
+		{`struct Point { x, y }; let p = Point{x: 1, y: 2}; let q = p; q.x = 5; p.x`, 5},
+		{`struct Line { a, b }; struct P { x }; let l = Line{a: P{x: 1}, b: P{x: 2}}; l.b.x = 3; l.a.x + l.b.x`, 4},
+		{`struct P { x }; P{z: 1}`, "P has no field z"},
+		{`struct P { x }; P{x: 1}.z`, "P has no field z"},
+		{`struct P { x, x }`, "duplicate field x in struct P"},
+		{`let a = [1]; a.x`, "field access not supported: ARRAY"},
+		{`let a = 1; a{x: 1}`, "not a struct: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value. got=%q, want=%q", str.Value, expected)
+				}
+				continue
+			}
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`match (1) { 1 => 10, 2 => 20, _ => 30 }`, 10},
+		{`match (5) { 1 => 10, 2 => 20, _ => 30 }`, 30},
+		{`match (1) { "1" => 1, 1.0 => 2, 1 => 3 }`, 3},
+		{`match (-2) { -2 => 1, _ => 0 }`, 1},
+		{`match (99999999999999999999) { 99999999999999999999 => 1, _ => 0 }`, 1},
+		{`match (-99999999999999999999) { 99999999999999999999 => 1, -99999999999999999999 => 2, _ => 0 }`, 2},
+		{`match ([1, [2, 3]]) { [a, [b, c]] => a + b + c, _ => 0 }`, 6},
+		{`match ([3, 2]) { [1, x] => x * 10, _ => 0 }`, 0},
+		{`match ({"k": 5, "j": 1}) { {"k": v} => v, _ => 0 }`, 5},
+		{`match (7) { n => n * 2 }`, 14},
+		{`let x = 1; match ({"a": 2, "b": 3}) { {"a": x, "b": 4} => x, _ => x }`, 1},
+		{`match (3) { 3 => { let a = 1; a + 1 } _ => 0 }`, 2},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	if evaluated := testEval(`match (1) { 2 => 2 }`); evaluated != NULL {
+		t.Errorf("object is not NULL. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let x = 1; x = 2; x", 2},
+		{"let x = 10; x += 5; x -= 3; x *= 2; x /= 4; x", 6},
+		{"let x = 0; let y = 0; x = y = 3; x + y", 6},
+		{"let counter = fn() { let n = 0; fn() { n += 1 } }; let c = counter(); c(); c(); c()", 3},
+		{"let x = 1; let f = fn() { x = 7 }; f(); x", 7},
+		{"let x = 5; match (3) { x => 0 }; x", 5},
+		{"let x = 5; match ([1, 2]) { [x, y] => x + y }; x", 5},
+		{"let x = 5; for x in [1, 2] { }; x", 5},
+		{"let e = 1; try { throw \"x\" } catch (e) { 0 }; e", 1},
+		{"let f = fn() { let x = 5; for x in [1, 2] { x += 10 }; x }; f()", 5},
+		{"let y = 0; for x in [1, 2] { y = x }; y", 2},
+		{"let y = 0; match (4) { x => y = x }; y", 4},
+		{"len = 1", "cannot assign to builtin len"},
+		{"let x = 1; x += true", "type mismatch: INTEGER+BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestIndexAssignments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = [1, 2, 3]; a[1] = 5; a[1]", 5},
+		{"let a = [1, 2, 3]; a[-1] = 9; a[2]", 9},
+		{"let a = [1, 2, 3]; a[2] += 10; a[2]", 13},
+		{"let a = [1, 2]; a[-1] += 2; a[1]", 4},
+		{"let a = [1, 2]; a[-3] += 2;", "index out of range: -3 (length 2)"},
+		{`let h = {"k": 2}; h["k"] *= 21; h["k"]`, 42},
+		{"let f = fn(xs) { xs[0] = 1 }; let a = [0]; f(a); a[0]", 1},
+		{"let a = [1]; a[1] = 2;", "index out of range: 1 (length 1)"},
+		{`let h = {}; h[[1]] = 2;`, "unusable as hash key: ARRAY"},
+		{`let s = "abc"; s[0] = "x";`, "index assignment not supported: STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -209,6 +511,25 @@ func TestFunctionApplication(t *testing.T) {
 	}
 }
 
+func TestFunctionArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let add = fn(a, b = 10) { a + b }; add(1)", 11},
+		{"let add = fn(a, b = 10) { a + b }; add(1, 2)", 3},
+		{"let add = fn(a, b = 10) { a + b }; add(b: 3, a: 2)", 5},
+		{"let f = fn(a, b = a * 2) { b }; f(4)", 8},
+		{"let f = fn(a = 1, b = 2, c = 3) { a * 100 + b * 10 + c }; f(c: 9)", 129},
+		{"let f = fn(a, ...rest) { len(rest) }; f(1, 2, 3)", 2},
+		{"let f = fn(a, ...rest) { rest[1] }; f(1, 2, 3)", 3},
+		{"let f = fn(a, b = 2, ...rest) { a + b + len(rest) }; f(1, b: 5)", 6},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestClosures(t *testing.T) {
 	input := `
 			let newAdder = fn(x) {
@@ -220,6 +541,26 @@ func TestClosures(t *testing.T) {
 	testIntegerObject(t, testEval(input), 4)
 }
 
+func TestLoopClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let fs = []; for i in 0..3 { fs = push(fs, fn() { i }) }; [fs[0](), fs[1](), fs[2]()]", "[0, 1, 2]"},
+		{"let make = fn() { let fs = []; for i in 0..3 { fs = push(fs, fn() { i }) }; fs }; let fs = make(); [fs[0](), fs[1](), fs[2]()]", "[0, 1, 2]"},
+		{"let fs = []; for i in 0..2 { fs = push(fs, fn() { i }); i *= 10 }; [fs[0](), fs[1]()]", "[0, 10]"},
+		{"let fs = []; for i in 0..2 { let inc = fn() { i += 5 }; inc(); fs = push(fs, fn() { i }) }; [fs[0](), fs[1]()]", "[5, 6]"},
+		{"let fs = []; for v in [1, 2] { match (v) { x => fs = push(fs, fn() { x }) } }; [fs[0](), fs[1]()]", "[1, 2]"},
+		{`let fs = []; for v in [1, 2] { try { throw v } catch (e) { fs = push(fs, fn() { e["message"] }) } }; fs[0]() + fs[1]()`, "12"},
+	}
+
+	for _, tt := range tests {
+		if evaluated := testEval(tt.input); evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"Hello World!"`
 	evaluated := testEval(input)
@@ -244,6 +585,29 @@ func TestStringConcatenation(t *testing.T) {
 	}
 }
 
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let count = 3; let price = 5; "total: ${count * price}"`, "total: 15"},
+		{`"${1.5} ${true} ${"nested ${1 + 1}"}"`, "1.5 true nested 2"},
+		{`"${[1, 2]}"`, "[1, 2]"},
+		{`let f = fn(name) { "hi ${name}!" }; f("you")`, "hi you!"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+		}
+		if str.Value != tt.expected {
+			t.Errorf("String has wrong value. want=%q, got=%q", tt.expected, str.Value)
+		}
+	}
+}
+
 func TestBuiltInFunctions(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -318,6 +682,150 @@ func TestArrayIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestSliceAndRangeExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3, 4][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4][:-1]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4][2:]", "[3, 4]"},
+		{`"hello"[1:3]`, "el"},
+		{"1..4", "1..4"},
+		{"range(0, 10, 3)[1:]", "range(3, 12, 3)"},
+		{"(1..10)[-1]", "9"},
+		{"len(range(5))", "5"},
+		{"let s = 0; for i in 1..5 { s += i }; s", "10"},
+		{"[1][\"a\":]", "slice bounds must be INTEGER, got STRING"},
+		{"range(1, 2, 0)", "range step cannot be zero"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		var got string
+		if errObj, ok := evaluated.(*object.Error); ok {
+			got = errObj.Message
+		} else {
+			got = evaluated.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. want=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`len("héllo")`, "5"},
+		{`"日本語"[1]`, "本"},
+		{`"日本語"[-1]`, "語"},
+		{`"héllo wörld"[1:4]`, "éll"},
+		{`let s = ""; for c in "añb" { s = c + s }; s`, "bña"},
+		{`let 名前 = "Zoë"; "こんにちは ${名前}!"`, "こんにちは Zoë!"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestConstants(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`const x = 5; x * 2`, "10"},
+		{`const x = 1; let g = fn() { let x = 2; x }; g() + x`, "3"},
+		{`const x = 1; x = 2`, "ERROR:cannot assign to constant x"},
+		{`const x = 1; let f = fn() { x += 1 }; f()`, "ERROR:cannot assign to constant x"},
+		{`const x = 1; let x = 2`, "ERROR:cannot redefine constant x"},
+		{`const x = 1; for x in [5] { }; x`, "ERROR:cannot redefine constant x"},
+		{`const x = 1; match (5) { x => x }`, "ERROR:cannot redefine constant x"},
+		{`const x = 1; match ([5]) { [1] => 0, [x] => x }`, "ERROR:cannot redefine constant x"},
+		{`const e = 1; try { throw "x" } catch (e) { 0 }`, "ERROR:cannot redefine constant e"},
+		{`const x = 1; let f = fn() { for x in [5] { }; 2 }; f()`, "2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestEnums(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`enum Status { Pending, Done }; Status.Done`, "Status.Done"},
+		{`enum Status { Pending, Done }; Status.Done == Status.Done`, "true"},
+		{`enum Status { Pending, Done }; Status.Done == "Status.Done"`, "false"},
+		{`enum Status { Pending, Done }; Status.Done.ordinal`, "1"},
+		{`enum Status { Pending, Done }; {Status.Done: "d"}[Status.Done]`, "d"},
+		{`enum Status { Pending, Done }; match (Status.Done) { Status.Pending => 1, Status.Done => 2 }`, "2"},
+		{`enum Status { Pending, Done }; Status.Lost`, "ERROR:Status has no variant Lost"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let g = fn*() { yield 1 }; g", "ERROR:generators are not supported by the evaluator"},
+		{"yield 1", "ERROR:yield outside of a generator function"},
+		{"spawn fn() { 1 }", "ERROR:spawn is not supported by the evaluator"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`9223372036854775807 + 1`, "9223372036854775808"},
+		{`-9223372036854775807 - 2`, "-9223372036854775809"},
+		{`let m = -9223372036854775807 - 1; -m`, "9223372036854775808"},
+		{`9223372036854775807 + 1 - 1`, "9223372036854775807"},
+		{`9223372036854775807 + 1 > 9223372036854775807`, "true"},
+		{`let h = {9223372036854775807 + 1: "big"}; h[9223372036854775807 + 1]`, "big"},
+		{`try { 1 / 0 } catch (e) { e["message"] }`, "division by zero"},
+		{`7 % 0`, "ERROR:division by zero"},
+		{`let n = 99999999999999999999; n`, "99999999999999999999"},
+		{`99999999999999999999 - 99999999999999999998`, "1"},
+		{`-9223372036854775808`, "-9223372036854775808"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `let two = "two"; { "one": 10 - 9, two: 1 + 1, "thr" + "ee": 6 / 2,4: 4, true: 5, false: 6 }`
 	evaluated := testEval(input)
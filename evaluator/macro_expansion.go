@@ -2,6 +2,7 @@ package evaluator
 
 import (
 	"zetsu/ast"
+	"zetsu/errrs"
 	"zetsu/object"
 )
 
@@ -51,10 +52,15 @@ func addMacro(stmt ast.Statement, env *object.Environment) {
 	env.Set(letStatement.Name.Value, macro)
 }
 
-func ExpandMacros(program ast.Node, env *object.Environment) ast.Node {
-	return ast.Modify(program, func(node ast.Node) ast.Node {
+// ExpandMacros replaces every call to a macro defined in env with the
+// AST the macro returns. Macros that fail to evaluate or don't return
+// a quote are reported as a Diagnostic pointing at the call
+func ExpandMacros(program ast.Node, env *object.Environment) (ast.Node, error) {
+	var err error
+
+	expanded := ast.Modify(program, func(node ast.Node) ast.Node {
 		callExpression, ok := node.(*ast.CallExpression)
-		if !ok {
+		if !ok || err != nil {
 			return node
 		}
 
@@ -63,18 +69,39 @@ func ExpandMacros(program ast.Node, env *object.Environment) ast.Node {
 			return node
 		}
 
+		name := callExpression.Function.String()
+		if len(callExpression.Arguments) != len(macro.Parameters) {
+			err = errrs.Errorf(callExpression.Span(), "wrong number of arguments to macro %s. want=%d, got=%d",
+				name, len(macro.Parameters), len(callExpression.Arguments))
+			return node
+		}
+
 		args := quoteArgs(callExpression)
 		evalEnv := extendMacroEnv(macro, args)
 
-		evaluated := Eval(macro.Body, evalEnv)
+		evaluated := unwrapReturnValue(Eval(macro.Body, evalEnv))
+		if evaluated == nil {
+			evaluated = NULL
+		}
+		if isError(evaluated) {
+			err = errrs.Errorf(callExpression.Span(), "macro %s: %s", name, evaluated.(*object.Error).Message)
+			return node
+		}
 
 		quote, ok := evaluated.(*object.Quote)
 		if !ok {
-			panic("we only support returning AST-nodes from macros")
+			err = errrs.Errorf(callExpression.Span(), "macro %s must return a quote, got %s", name, evaluated.Type())
+			return node
+		}
+		if quote.Node == nil {
+			err = errrs.Errorf(callExpression.Span(), "macro %s expanded to nothing", name)
+			return node
 		}
 
 		return quote.Node
 	})
+
+	return expanded, err
 }
 
 func isMacroCall(exp *ast.CallExpression, env *object.Environment) (*object.Macro, bool) {
@@ -3,6 +3,7 @@ package evaluator
 import (
 	"testing"
 	"zetsu/ast"
+	"zetsu/errrs"
 	"zetsu/lexer"
 	"zetsu/object"
 	"zetsu/parser"
@@ -107,7 +108,10 @@ func TestExpandMacros(t *testing.T) {
 
 		env := object.NewEnvironment()
 		DefineMacros(program, env)
-		expanded := ExpandMacros(program, env)
+		expanded, err := ExpandMacros(program, env)
+		if err != nil {
+			t.Fatalf("macro expansion failed: %s", err)
+		}
 
 		if expanded.String() != expected.String() {
 			t.Errorf("not equal. want=%q, got=%q",
@@ -116,6 +120,60 @@ func TestExpandMacros(t *testing.T) {
 	}
 }
 
+func TestExpandMacrosErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`let number = macro() { 1 }; number();`,
+			"macro number must return a quote, got INTEGER",
+		},
+		{
+			`let broken = macro() { 1 + true }; broken();`,
+			"macro broken: type mismatch: INTEGER+BOOLEAN",
+		},
+		{
+			`let pair = macro(a, b) { quote(unquote(a)) }; pair(1);`,
+			"wrong number of arguments to macro pair. want=2, got=1",
+		},
+		{
+			`let m = macro() { quote(unquote(fn() { 1 })) }; m();`,
+			"macro m: cannot unquote FUNCTION",
+		},
+		{
+			`let m = macro() { quote(1 + unquote([1, fn() { 1 }])) }; m();`,
+			"macro m: cannot unquote ARRAY",
+		},
+		{
+			`let m = macro() { quote(unquote(1 + true)) }; m();`,
+			"macro m: type mismatch: INTEGER+BOOLEAN",
+		},
+	}
+
+	for _, tt := range tests {
+		program := testParseProgram(tt.input)
+
+		env := object.NewEnvironment()
+		DefineMacros(program, env)
+		_, err := ExpandMacros(program, env)
+		if err == nil {
+			t.Fatalf("expected an error for %q, got none", tt.input)
+		}
+
+		diag, ok := err.(*errrs.Diagnostic)
+		if !ok {
+			t.Fatalf("error is not *errrs.Diagnostic. got=%T", err)
+		}
+		if diag.Msg != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%q", tt.expected, diag.Msg)
+		}
+		if !diag.Span.Start.IsValid() {
+			t.Errorf("error for %q has no position", tt.input)
+		}
+	}
+}
+
 func testParseProgram(input string) *ast.Program {
 	l := lexer.New(input)
 	p := parser.New(l)
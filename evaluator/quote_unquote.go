@@ -8,13 +8,20 @@ import (
 )
 
 func quote(node ast.Node, env *object.Environment) object.Object {
-	node = evalUnquoteCalls(node, env)
+	node, err := evalUnquoteCalls(node, env)
+	if err != nil {
+		return err
+	}
 	return &object.Quote{Node: node}
 }
 
-func evalUnquoteCalls(quoted ast.Node, env *object.Environment) ast.Node {
-	return ast.Modify(quoted, func(node ast.Node) ast.Node {
-		if !isUnquoteCall(node) {
+// evalUnquoteCalls replaces the unquote calls in quoted with the AST of
+// what they evaluate to, the error is set when one of them fails or
+// gives a value that has no literal
+func evalUnquoteCalls(quoted ast.Node, env *object.Environment) (ast.Node, object.Object) {
+	var err object.Object
+	node := ast.Modify(quoted, func(node ast.Node) ast.Node {
+		if err != nil || !isUnquoteCall(node) {
 			return node
 		}
 
@@ -28,8 +35,18 @@ func evalUnquoteCalls(quoted ast.Node, env *object.Environment) ast.Node {
 		}
 
 		unquoted := Eval(call.Arguments[0], env)
-		return convertObjectToASTNode(unquoted)
+		if isError(unquoted) {
+			err = unquoted
+			return node
+		}
+		converted := convertObjectToASTNode(unquoted)
+		if converted == nil {
+			err = newError("cannot unquote %s", unquoted.Type())
+			return node
+		}
+		return converted
 	})
+	return node, err
 }
 
 func isUnquoteCall(node ast.Node) bool {
@@ -49,6 +66,18 @@ func convertObjectToASTNode(obj object.Object) ast.Node {
 			Literal: fmt.Sprintf("%d", obj.Value),
 		}
 		return &ast.IntegerLiteral{Token: t, Value: obj.Value}
+	case *object.BigInteger:
+		t := token.Token{
+			Type:    token.INT,
+			Literal: obj.Inspect(),
+		}
+		return &ast.BigIntegerLiteral{Token: t, Value: obj.Value}
+	case *object.Float:
+		t := token.Token{
+			Type:    token.FLOAT,
+			Literal: obj.Inspect(),
+		}
+		return &ast.FloatLiteral{Token: t, Value: obj.Value}
 	case *object.Boolean:
 		var t token.Token
 		if obj.Value {
@@ -57,6 +86,36 @@ func convertObjectToASTNode(obj object.Object) ast.Node {
 			t = token.Token{Type: token.FALSE, Literal: "false"}
 		}
 		return &ast.Boolean{Token: t, Value: obj.Value}
+	case *object.String:
+		t := token.Token{Type: token.STRING, Literal: obj.Value}
+		return &ast.StringLiteral{Token: t, Value: obj.Value}
+	case *object.Array:
+		lit := &ast.ArrayLiteral{Token: token.Token{Type: token.LSQUARE, Literal: "["}}
+		for _, el := range obj.Elements {
+			node, ok := convertObjectToASTNode(el).(ast.Expression)
+			if !ok {
+				return nil
+			}
+			lit.Elements = append(lit.Elements, node)
+		}
+		return lit
+	case *object.Hash:
+		lit := &ast.HashLiteral{
+			Token: token.Token{Type: token.LBRACE, Literal: "{"},
+			Pairs: make(map[ast.Expression]ast.Expression),
+		}
+		for _, pair := range obj.Pairs {
+			key, ok := convertObjectToASTNode(pair.Key).(ast.Expression)
+			if !ok {
+				return nil
+			}
+			value, ok := convertObjectToASTNode(pair.Value).(ast.Expression)
+			if !ok {
+				return nil
+			}
+			lit.Pairs[key] = value
+		}
+		return lit
 	case *object.Quote:
 		return obj.Node
 	default:
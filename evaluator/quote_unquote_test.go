@@ -46,6 +46,9 @@ func TestQuoteUnquote(t *testing.T) {
 		{`quote(unquote(true))`, `true`},
 		{`quote(unquote(true == false))`, `false`},
 		{`quote(unquote(quote(4 + 4)))`, `(4 + 4)`},
+		{`quote(unquote("a" + "b"))`, `ab`},
+		{`quote(unquote([1, "a", [true]]))`, `[1, a, [true]]`},
+		{`quote(unquote({"k": 2.5}))`, `{k:2.5}`},
 		{`let quotedInfixExpression = quote(4 + 4); quote(unquote(4 + 4) + unquote(quotedInfixExpression))`, `(8 + (4 + 4))`},
 	}
 
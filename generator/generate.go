@@ -8,24 +8,27 @@ import (
 	"zetsu/builtin"
 	"zetsu/compiler"
 	"zetsu/errrs"
+	"zetsu/evaluator"
 	"zetsu/global"
 	"zetsu/lexer"
 	"zetsu/mutil"
 	"zetsu/object"
 	"zetsu/parser"
+	"zetsu/resolver"
 	"zetsu/security"
 )
 
-// Generate function takes a `string`, it's the path for the source code
-func Generate(srcpath, dstpath, goos, goarch string, release bool) (error, errrs.ErrorType, []string) {
+// Generate function takes a `string`, it's the path for the source code.
+// When it succeeds the diagnostics it returns are compiler warnings
+func Generate(srcpath, dstpath, goos, goarch string, release bool) (error, errrs.ErrorType, []*errrs.Diagnostic) {
 	data, err := os.ReadFile(srcpath)
 	if err != nil {
 		return err, errrs.ERROR, nil
 	}
 
-	bytecode, err, errtype, errors := compile(data)
+	bytecode, err, errtype, diags := compile(data, srcpath)
 	if err != nil {
-		return err, errtype, errors
+		return err, errtype, diags
 	}
 
 	if release {
@@ -38,17 +41,17 @@ func Generate(srcpath, dstpath, goos, goarch string, release bool) (error, errrs
 			return err, errrs.ERROR, nil
 		}
 
-		return nil, "", nil
+		return nil, "", diags
 	}
 
 	if err := os.WriteFile(dstpath+global.ZetsuByteCodeCompiledFileExtension, bytecode, 0644); err != nil {
 		return err, errrs.ERROR, nil
 	}
 
-	return nil, "", nil
+	return nil, "", diags
 }
 
-func compile(data []byte) ([]byte, error, errrs.ErrorType, []string) {
+func compile(data []byte, srcpath string) ([]byte, error, errrs.ErrorType, []*errrs.Diagnostic) {
 	constants := []object.Object{}
 	symbolTable := compiler.NewSymbolTable()
 	for i, v := range builtin.Builtins {
@@ -60,12 +63,25 @@ func compile(data []byte) ([]byte, error, errrs.ErrorType, []string) {
 	program := p.ParseProgram()
 
 	if len(p.Errors()) != 0 {
-		return nil, fmt.Errorf("pareser error"), errrs.PARSER_ERROR, p.Errors()
+		return nil, fmt.Errorf("pareser error"), errrs.PARSER_ERROR, p.Diagnostics()
+	}
+
+	macroEnv := object.NewEnvironment()
+	evaluator.DefineMacros(program, macroEnv)
+	if _, err := evaluator.ExpandMacros(program, macroEnv); err != nil {
+		return nil, err, errrs.MACRO_ERROR, nil
+	}
+
+	modules, err, errtype, errors := resolver.Resolve(program, srcpath)
+	if err != nil {
+		return nil, err, errtype, errors
 	}
 
 	comp := compiler.NewWithState(symbolTable, constants)
-	if err := comp.Compile(program); err != nil {
-		return nil, err, errrs.COMPILER_ERROR, nil
+	for _, m := range modules {
+		if err := comp.CompileModule(m); err != nil {
+			return nil, err, errrs.COMPILER_ERROR, nil
+		}
 	}
 
 	encodedByteCode, err := encode(comp.ByteCode())
@@ -73,7 +89,7 @@ func compile(data []byte) ([]byte, error, errrs.ErrorType, []string) {
 		return nil, err, errrs.ERROR, nil
 	}
 
-	return encodedByteCode, nil, "", nil
+	return encodedByteCode, nil, "", comp.Warnings()
 }
 
 func encode(compByteCode *compiler.ByteCode) ([]byte, error) {
@@ -103,6 +119,7 @@ func encryptCode(b64ByteCode []byte) ([]byte, error) {
 
 func registerTypes() {
 	gob.Register(&object.Integer{})
+	gob.Register(&object.Float{})
 	gob.Register(&object.Boolean{})
 	gob.Register(&object.Null{})
 	gob.Register(&object.ReturnValue{})
@@ -117,4 +134,10 @@ func registerTypes() {
 	gob.Register(&object.CompiledFunction{})
 	gob.Register(&object.Closure{})
 	gob.Register(&object.Encrypted{})
+	gob.Register(&object.StructType{})
+	gob.Register(&object.Struct{})
+	gob.Register(&object.Range{})
+	gob.Register(&object.BigInteger{})
+	gob.Register(&object.EnumType{})
+	gob.Register(&object.EnumValue{})
 }
@@ -1,7 +1,11 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 	"zetsu/token"
 )
 
@@ -12,24 +16,52 @@ type Lexer struct {
 	position     int // current character index
 	readPosition int // next character index
 	ch           rune
+	line         int // line of the current character
+	column       int // column of the current character
+	base         int // offset of input within the enclosing source
+	file         string
 }
 
 // New function initializes our lexer, takes input as a string
 // that input is the source code
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
+	l.readRune()
+	return l
+}
+
+// NewFile initializes a lexer for the source code of an imported
+// module, the spans it produces name file
+func NewFile(input, file string) *Lexer {
+	l := New(input)
+	l.file = file
+	return l
+}
+
+// NewAt initializes a lexer for input that starts at the given position
+// of a larger source, such as the ${...} parts of an interpolated
+// string, so the spans it produces point into that source
+func NewAt(input, file string, start token.Position) *Lexer {
+	l := &Lexer{input: input, line: start.Line, column: start.Column - 1, base: start.Offset, file: file}
 	l.readRune()
 	return l
 }
 
 // NextToken method makes use of lexer data structure
 // Uses switch cases to identify whether a certain character
-// in source code is legal or not. Zetsu language only
-// supports ascii characters
+// in source code is legal or not. Source code is read as
+// UTF-8, identifiers may use letters of any language
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
-	l.skipWhiteSpace()
+	comments, ok := l.skipTrivia()
+	start := l.pos()
+	if !ok {
+		last := comments[len(comments)-1]
+		tok = token.Token{Type: token.ILLEGAL, Literal: "unterminated block comment", Comments: comments[:len(comments)-1]}
+		tok.Span = last.Span
+		return tok
+	}
 
 	switch l.ch {
 	case '=':
@@ -37,23 +69,33 @@ func (l *Lexer) NextToken() token.Token {
 			ch := string(l.ch)
 			l.readRune()
 			tok = token.Token{Type: token.EQUALITY, Literal: ch + string(l.ch)}
+		} else if l.peekRune() == '>' {
+			ch := string(l.ch)
+			l.readRune()
+			tok = token.Token{Type: token.ARROW, Literal: ch + string(l.ch)}
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		tok = l.readOperator(token.PLUS, token.PLUS_ASSIGN)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		tok = l.readOperator(token.MINUS, token.MINUS_ASSIGN)
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		tok = l.readOperator(token.ASTERISK, token.ASTERISK_ASSIGN)
 	case '/':
-		tok = newToken(token.FSLASH, l.ch)
+		tok = l.readOperator(token.FSLASH, token.FSLASH_ASSIGN)
 	case '\\':
 		tok = newToken(token.FSLASH, l.ch)
+	case '%':
+		tok = l.readOperator(token.PERCENT, token.PERCENT_ASSIGN)
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		tok = l.readOperator(token.LT, token.LTE)
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		tok = l.readOperator(token.GT, token.GTE)
+	case '&':
+		tok = l.readDoubled(token.AND)
+	case '|':
+		tok = l.readDoubled(token.OR)
 	case '!':
 		if l.peekRune() == '=' {
 			ch := string(l.ch)
@@ -78,51 +120,312 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.COMMA, l.ch)
 	case ':':
 		tok = newToken(token.COLON, l.ch)
+	case '.':
+		if l.peekRune() == '.' && l.peekRuneAt(2) == '.' {
+			l.readRune()
+			l.readRune()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else if l.peekRune() == '.' {
+			l.readRune()
+			tok = token.Token{Type: token.DOTDOT, Literal: ".."}
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case 0:
 		tok = newToken(token.EOF, l.ch)
 	case '"':
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		tok = l.readString()
+	case '`':
+		tok = l.readRawString()
 	default:
-		if unicode.IsLetter(l.ch) {
+		if unicode.IsLetter(l.ch) || l.ch == '_' {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Span = token.Span{File: l.file, Start: start, End: l.pos()}
+			tok.Comments = comments
 			return tok
-		} else if unicode.IsNumber(l.ch) {
-			tok.Literal = l.readNumber()
-			tok.Type = token.INT
+		} else if isDigit(l.ch) {
+			tok.Type, tok.Literal = l.readNumber()
+			tok.Span = token.Span{File: l.file, Start: start, End: l.pos()}
+			tok.Comments = comments
 			return tok
 		}
 		tok = newToken(token.ILLEGAL, l.ch)
 	}
 
 	l.readRune()
+	tok.Span = token.Span{File: l.file, Start: start, End: l.pos()}
+	tok.Comments = comments
 
 	return tok
 }
 
+// readRune moves to the next character, decoding it from UTF-8.
+// position and readPosition are byte offsets into the input while
+// column counts characters
 func (l *Lexer) readRune() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+
+	width := 1
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
-		l.ch = rune(l.input[l.readPosition])
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += width
+	l.column++
+}
+
+// pos returns the position of the current character
+func (l *Lexer) pos() token.Position {
+	offset := l.position
+	if offset > len(l.input) {
+		offset = len(l.input)
+	}
+	return token.Position{Offset: l.base + offset, Line: l.line, Column: l.column}
 }
 
-func (l *Lexer) readString() string {
+// readString reads a double quoted string and resolves its escape
+// sequences. Malformed strings come back as an ILLEGAL token whose
+// literal describes the problem, the parser reports it as is.
+// Strings holding ${...} come back as a TEMPLATE token carrying the
+// raw text between the quotes, see SplitTemplate
+func (l *Lexer) readString() token.Token {
+	var out strings.Builder
 	position := l.position + 1
+	problem := ""
+	template := false
+
 	for {
 		l.readRune()
-		if l.ch == '"' || l.ch == 0 {
-			break
+		switch l.ch {
+		case '"':
+			if problem != "" {
+				return token.Token{Type: token.ILLEGAL, Literal: problem}
+			}
+			if template {
+				return token.Token{Type: token.TEMPLATE, Literal: l.input[position:l.position]}
+			}
+			return token.Token{Type: token.STRING, Literal: out.String()}
+		case 0:
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated string"}
+		case '\\':
+			l.readRune()
+			if msg := l.readEscape(&out); msg != "" && problem == "" {
+				problem = msg
+			}
+		case '$':
+			if l.peekRune() != '{' {
+				out.WriteByte('$')
+				continue
+			}
+			l.readRune()
+			if !l.skipInterpolation() {
+				return token.Token{Type: token.ILLEGAL, Literal: "unterminated string interpolation"}
+			}
+			template = true
+		default:
+			out.WriteRune(l.ch)
 		}
 	}
-	return l.input[position:l.position]
+}
+
+// skipInterpolation moves from the { of a ${ to its matching }, strings
+// nested in the expression may hold braces and interpolations of
+// their own. It reports false when the input ends first
+func (l *Lexer) skipInterpolation() bool {
+	depth := 1
+	for depth > 0 {
+		l.readRune()
+		switch l.ch {
+		case 0:
+			return false
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '"':
+			if !l.skipNestedString() {
+				return false
+			}
+		case '`':
+			for l.readRune(); l.ch != '`'; l.readRune() {
+				if l.ch == 0 {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// skipNestedString moves to the closing quote of a string found
+// inside an interpolation, its contents are lexed later on
+func (l *Lexer) skipNestedString() bool {
+	for {
+		l.readRune()
+		switch l.ch {
+		case 0:
+			return false
+		case '"':
+			return true
+		case '\\':
+			l.readRune()
+		case '$':
+			if l.peekRune() == '{' {
+				l.readRune()
+				if !l.skipInterpolation() {
+					return false
+				}
+			}
+		}
+	}
+}
+
+// TemplatePart is a piece of an interpolated string. Text is the
+// resolved text of a literal part, or the source of a ${...} part
+// when Expr is set, Start is where that text begins in the source
+type TemplatePart struct {
+	Text  string
+	Expr  bool
+	Start token.Position
+}
+
+// SplitTemplate splits the raw literal of a TEMPLATE token into its
+// literal and ${...} parts, start is the position of the first
+// character after the opening quote. Escape errors were already
+// reported by readString, so they are not repeated here
+func SplitTemplate(raw string, start token.Position) []TemplatePart {
+	parts := []TemplatePart{}
+	l := NewAt(raw, "", start)
+
+	var out strings.Builder
+	textStart := l.pos()
+	for l.ch != 0 {
+		switch {
+		case l.ch == '\\':
+			l.readRune()
+			l.readEscape(&out)
+		case l.ch == '$' && l.peekRune() == '{':
+			if out.Len() > 0 {
+				parts = append(parts, TemplatePart{Text: out.String(), Start: textStart})
+				out.Reset()
+			}
+			l.readRune()
+			position := l.position + 1
+			exprStart := token.Position{Offset: l.base + position, Line: l.line, Column: l.column + 1}
+			l.skipInterpolation()
+			parts = append(parts, TemplatePart{Text: raw[position:l.position], Expr: true, Start: exprStart})
+			l.readRune()
+			textStart = l.pos()
+			continue
+		default:
+			out.WriteRune(l.ch)
+		}
+		l.readRune()
+	}
+	if out.Len() > 0 {
+		parts = append(parts, TemplatePart{Text: out.String(), Start: textStart})
+	}
+
+	return parts
+}
+
+// readEscape writes the character escaped by the backslash before
+// l.ch to out, it returns a message when the escape is not valid
+func (l *Lexer) readEscape(out *strings.Builder) string {
+	switch l.ch {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case 'r':
+		out.WriteByte('\r')
+	case '"':
+		out.WriteByte('"')
+	case '\\':
+		out.WriteByte('\\')
+	case '$':
+		out.WriteByte('$')
+	case 'u':
+		return l.readUnicodeEscape(out)
+	case 0:
+		// the string is unterminated, readString reports that
+	default:
+		return fmt.Sprintf("invalid escape sequence \\%c", l.ch)
+	}
+	return ""
+}
+
+// readUnicodeEscape reads the {hex} part of a \u{hex} escape, the
+// closing quote of the string is never consumed here
+func (l *Lexer) readUnicodeEscape(out *strings.Builder) string {
+	if l.peekRune() != '{' {
+		return "invalid unicode escape, expected \\u{...}"
+	}
+	l.readRune()
+
+	digits := ""
+	for isHexDigit(l.peekRune()) {
+		l.readRune()
+		digits += string(l.ch)
+	}
+	if l.peekRune() != '}' || digits == "" || len(digits) > 6 {
+		return "invalid unicode escape, expected \\u{...}"
+	}
+	l.readRune()
+
+	code, _ := strconv.ParseUint(digits, 16, 32)
+	if !utf8.ValidRune(rune(code)) {
+		return fmt.Sprintf("invalid unicode code point U+%s", strings.ToUpper(digits))
+	}
+	out.WriteRune(rune(code))
+	return ""
+}
+
+// readRawString reads a backtick string, raw strings have no escape
+// sequences and may span several lines
+func (l *Lexer) readRawString() token.Token {
+	position := l.position + 1
+	for {
+		l.readRune()
+		switch l.ch {
+		case '`':
+			return token.Token{Type: token.STRING, Literal: l.input[position:l.position]}
+		case 0:
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated raw string"}
+		}
+	}
+}
+
+// readOperator returns an op token, or its two character form
+// (<= or a compound assignment like +=) when the operator is
+// immediately followed by =
+func (l *Lexer) readOperator(op, opEqual token.TokenType) token.Token {
+	if l.peekRune() == '=' {
+		ch := string(l.ch)
+		l.readRune()
+		return token.Token{Type: opEqual, Literal: ch + string(l.ch)}
+	}
+	return newToken(op, l.ch)
+}
+
+// readDoubled reads operators such as && that are only legal when
+// their character is repeated
+func (l *Lexer) readDoubled(op token.TokenType) token.Token {
+	if l.peekRune() != l.ch {
+		return newToken(token.ILLEGAL, l.ch)
+	}
+	ch := string(l.ch)
+	l.readRune()
+	return token.Token{Type: op, Literal: ch + string(l.ch)}
 }
 
 func newToken(tokenType token.TokenType, ch rune) token.Token {
@@ -142,12 +445,107 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer or a floating point literal, floats need
+// digits on both sides of the dot and may carry an exponent (1.5e-3)
+func (l *Lexer) readNumber() (token.TokenType, string) {
 	position := l.position
-	for unicode.IsDigit(l.ch) {
+	tokType := token.TokenType(token.INT)
+
+	l.readDigits()
+
+	if l.ch == '.' && isDigit(l.peekRune()) {
+		tokType = token.FLOAT
 		l.readRune()
+		l.readDigits()
 	}
-	return l.input[position:l.position]
+
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekRune()
+		if (next == '+' || next == '-') && isDigit(l.peekRuneAt(2)) {
+			l.readRune()
+			next = l.peekRune()
+		}
+		if isDigit(next) {
+			tokType = token.FLOAT
+			l.readRune()
+			l.readDigits()
+		}
+	}
+
+	return tokType, l.input[position:l.position]
+}
+
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) {
+		l.readRune()
+	}
+}
+
+func isDigit(ch rune) bool { return '0' <= ch && ch <= '9' }
+
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F')
+}
+
+// skipTrivia skips white space and comments, the comments are returned
+// so they can be attached to the token that follows them. ok is false
+// when a block comment is still open at the end of the input
+func (l *Lexer) skipTrivia() (comments []token.Comment, ok bool) {
+	for {
+		l.skipWhiteSpace()
+		if l.ch != '/' {
+			return comments, true
+		}
+
+		switch l.peekRune() {
+		case '/':
+			comments = append(comments, l.readLineComment())
+		case '*':
+			comment, closed := l.readBlockComment()
+			comments = append(comments, comment)
+			if !closed {
+				return comments, false
+			}
+		default:
+			return comments, true
+		}
+	}
+}
+
+func (l *Lexer) readLineComment() token.Comment {
+	start := l.pos()
+	for l.ch != '\n' && l.ch != 0 {
+		l.readRune()
+	}
+	end := l.pos()
+	return token.Comment{Text: l.input[start.Offset-l.base : end.Offset-l.base], Span: token.Span{File: l.file, Start: start, End: end}}
+}
+
+// readBlockComment reads a /* */ comment, block comments nest so that
+// code which already contains comments can be commented out
+func (l *Lexer) readBlockComment() (token.Comment, bool) {
+	start := l.pos()
+	depth := 0
+	closed := false
+
+	for l.ch != 0 {
+		if l.ch == '/' && l.peekRune() == '*' {
+			depth++
+			l.readRune()
+		} else if l.ch == '*' && l.peekRune() == '/' {
+			depth--
+			l.readRune()
+		}
+		l.readRune()
+
+		if depth == 0 {
+			closed = true
+			break
+		}
+	}
+
+	end := l.pos()
+	return token.Comment{Text: l.input[start.Offset-l.base : end.Offset-l.base], Span: token.Span{File: l.file, Start: start, End: end}}, closed
 }
 
 func (l *Lexer) skipWhiteSpace() {
@@ -156,9 +554,18 @@ func (l *Lexer) skipWhiteSpace() {
 	}
 }
 
-func (l *Lexer) peekRune() rune {
-	if l.readPosition >= len(l.input) {
+func (l *Lexer) peekRune() rune { return l.peekRuneAt(1) }
+
+// peekRuneAt looks n characters ahead of the current one
+func (l *Lexer) peekRuneAt(n int) rune {
+	index := l.readPosition
+	for ; n > 1 && index < len(l.input); n-- {
+		_, width := utf8.DecodeRuneInString(l.input[index:])
+		index += width
+	}
+	if index >= len(l.input) {
 		return 0
 	}
-	return rune(l.input[l.readPosition])
+	ch, _ := utf8.DecodeRuneInString(l.input[index:])
+	return ch
 }
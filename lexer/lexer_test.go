@@ -16,7 +16,7 @@ func TestNextToken(t *testing.T) {
 	};
 
 	let result = add(five, ten);
-	!-/*5; 5 < 10 > 5;
+	!-/ *5; 5 < 10 > 5;
 
 	if (5 < 10) { return true; } else { return false; }
 
@@ -165,3 +165,519 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  x + \"ab\""
+
+	tests := []struct {
+		expectedType token.TokenType
+		start        token.Position
+		end          token.Position
+	}{
+		{token.LET, token.Position{Offset: 0, Line: 1, Column: 1}, token.Position{Offset: 3, Line: 1, Column: 4}},
+		{token.IDENT, token.Position{Offset: 4, Line: 1, Column: 5}, token.Position{Offset: 5, Line: 1, Column: 6}},
+		{token.ASSIGN, token.Position{Offset: 6, Line: 1, Column: 7}, token.Position{Offset: 7, Line: 1, Column: 8}},
+		{token.INT, token.Position{Offset: 8, Line: 1, Column: 9}, token.Position{Offset: 9, Line: 1, Column: 10}},
+		{token.SEMICOLON, token.Position{Offset: 9, Line: 1, Column: 10}, token.Position{Offset: 10, Line: 1, Column: 11}},
+		{token.IDENT, token.Position{Offset: 13, Line: 2, Column: 3}, token.Position{Offset: 14, Line: 2, Column: 4}},
+		{token.PLUS, token.Position{Offset: 15, Line: 2, Column: 5}, token.Position{Offset: 16, Line: 2, Column: 6}},
+		{token.STRING, token.Position{Offset: 17, Line: 2, Column: 7}, token.Position{Offset: 21, Line: 2, Column: 11}},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Span.Start != tt.start {
+			t.Errorf("tests[%d] - start wrong. expected = %+v, got = %+v", i, tt.start, tok.Span.Start)
+		}
+		if tok.Span.End != tt.end {
+			t.Errorf("tests[%d] - end wrong. expected = %+v, got = %+v", i, tt.end, tok.Span.End)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading note
+	let x = 10; // trailing note
+	/* block /* nested */ still comment */ x / 2;
+	x // at the end`
+
+	tests := []struct {
+		expectedType     token.TokenType
+		expectedLiteral  string
+		expectedComments []string
+	}{
+		{token.LET, "let", []string{"// leading note"}},
+		{token.IDENT, "x", nil},
+		{token.ASSIGN, "=", nil},
+		{token.INT, "10", nil},
+		{token.SEMICOLON, ";", nil},
+		{token.IDENT, "x", []string{"// trailing note", "/* block /* nested */ still comment */"}},
+		{token.FSLASH, "/", nil},
+		{token.INT, "2", nil},
+		{token.SEMICOLON, ";", nil},
+		{token.IDENT, "x", nil},
+		{token.EOF, "\x00", []string{"// at the end"}},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if len(tok.Comments) != len(tt.expectedComments) {
+			t.Fatalf("tests[%d] - wrong number of comments. expected = %d, got = %d", i, len(tt.expectedComments), len(tok.Comments))
+		}
+		for j, comment := range tt.expectedComments {
+			if tok.Comments[j].Text != comment {
+				t.Errorf("tests[%d] - comment %d wrong. expected = %q, got = %q", i, j, comment, tok.Comments[j].Text)
+			}
+		}
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	l := New("let x = 1; /* /* */ never closed")
+
+	for i := 0; i < 5; i++ {
+		l.NextToken()
+	}
+
+	tok := l.NextToken()
+	if tok.Type != token.ILLEGAL {
+		t.Fatalf("tokentype wrong. expected = %q, got = %q", token.ILLEGAL, tok.Type)
+	}
+	if tok.Span.Start.Column != 12 {
+		t.Errorf("unterminated comment reported at wrong column. expected = 12, got = %d", tok.Span.Start.Column)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := `3 3.14 0.5e3 2E-2 1e+9 7.x 5e`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "3"},
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "0.5e3"},
+		{token.FLOAT, "2E-2"},
+		{token.FLOAT, "1e+9"},
+		{token.INT, "7"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.INT, "5"},
+		{token.IDENT, "e"},
+		{token.EOF, "\x00"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	input := `x += 1; x -= 2; x *= 3; x /= 4; x + -1`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.FSLASH_ASSIGN, "/="},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.PLUS, "+"},
+		{token.MINUS, "-"},
+		{token.INT, "1"},
+		{token.EOF, "\x00"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestComparisonAndLogicalOperators(t *testing.T) {
+	input := `a <= b >= c % d && e || f %= 2 & |`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LTE, "<="},
+		{token.IDENT, "b"},
+		{token.GTE, ">="},
+		{token.IDENT, "c"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "d"},
+		{token.AND, "&&"},
+		{token.IDENT, "e"},
+		{token.OR, "||"},
+		{token.IDENT, "f"},
+		{token.PERCENT_ASSIGN, "%="},
+		{token.INT, "2"},
+		{token.ILLEGAL, "&"},
+		{token.ILLEGAL, "|"},
+		{token.EOF, "\x00"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	input := "\"a\\tb\\n\\\"c\\\"\\\\\" \"\\u{48}\\u{e9}\\u{1F600}\" `raw \\n\nline` \"\\x\" \"open"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "a\tb\n\"c\"\\"},
+		{token.STRING, "H\u00e9\U0001F600"},
+		{token.STRING, "raw \\n\nline"},
+		{token.ILLEGAL, "invalid escape sequence \\x"},
+		{token.ILLEGAL, "unterminated string"},
+		{token.EOF, "\x00"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTemplateStrings(t *testing.T) {
+	input := "\"total: ${count * price}\" \"${\"}\"}\" \"cost \\${x}\" \"${a\""
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.TEMPLATE, "total: ${count * price}"},
+		{token.TEMPLATE, "${\"}\"}"},
+		{token.STRING, "cost ${x}"},
+		{token.ILLEGAL, "unterminated string interpolation"},
+		{token.EOF, "\x00"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestSplitTemplate(t *testing.T) {
+	start := token.Position{Offset: 1, Line: 1, Column: 2}
+	parts := SplitTemplate("a\\t${x + 1} is ${y}", start)
+
+	expected := []TemplatePart{
+		{Text: "a\t", Start: token.Position{Offset: 1, Line: 1, Column: 2}},
+		{Text: "x + 1", Expr: true, Start: token.Position{Offset: 6, Line: 1, Column: 7}},
+		{Text: " is ", Start: token.Position{Offset: 12, Line: 1, Column: 13}},
+		{Text: "y", Expr: true, Start: token.Position{Offset: 18, Line: 1, Column: 19}},
+	}
+
+	if len(parts) != len(expected) {
+		t.Fatalf("wrong number of parts. want=%d, got=%d (%+v)", len(expected), len(parts), parts)
+	}
+	for i, want := range expected {
+		if parts[i] != want {
+			t.Errorf("parts[%d] wrong. want=%+v, got=%+v", i, want, parts[i])
+		}
+	}
+}
+
+func TestCommentsInInterpolation(t *testing.T) {
+	tests := []struct {
+		template string
+		comment  string
+	}{
+		{"${ 1 /* c */ }", "/* c */"},
+		{"${ x // c\n }", "// c"},
+	}
+
+	for _, tt := range tests {
+		parts := SplitTemplate(tt.template, token.Position{Offset: 10, Line: 1, Column: 11})
+		l := NewAt(parts[0].Text, "", parts[0].Start)
+
+		var comments []token.Comment
+		for tok := l.NextToken(); ; tok = l.NextToken() {
+			comments = append(comments, tok.Comments...)
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+
+		if len(comments) != 1 {
+			t.Fatalf("wrong number of comments in %q. expected = 1, got = %d", tt.template, len(comments))
+		}
+		if comments[0].Text != tt.comment {
+			t.Errorf("comment wrong. expected = %q, got = %q", tt.comment, comments[0].Text)
+		}
+		if offset := comments[0].Span.Start.Offset; offset != 15 {
+			t.Errorf("comment at wrong offset. expected = 15, got = %d", offset)
+		}
+	}
+}
+
+func TestModuleTokens(t *testing.T) {
+	input := `import "lib/strings.zeta" as s; export let x = s.trim;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IMPORT, "import"},
+		{token.STRING, "lib/strings.zeta"},
+		{token.AS, "as"},
+		{token.IDENT, "s"},
+		{token.SEMICOLON, ";"},
+		{token.EXPORT, "export"},
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "s"},
+		{token.DOT, "."},
+		{token.IDENT, "trim"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, "\x00"},
+	}
+
+	l := NewFile(input, "main.zeta")
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Span.File != "main.zeta" {
+			t.Fatalf("tests[%d] - span file wrong. expected = %q, got = %q", i, "main.zeta", tok.Span.File)
+		}
+	}
+}
+
+func TestMatchTokens(t *testing.T) {
+	input := `match (x) { _a => 1, _ => 2 }`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "_a"},
+		{token.ARROW, "=>"},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.INT, "2"},
+		{token.RBRACE, "}"},
+		{token.EOF, "\x00"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestParameterTokens(t *testing.T) {
+	input := `fn(a, b = 1, ...rest) {}; f(a.b, c: 2)`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.IDENT, "b"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.DOT, "."},
+		{token.IDENT, "b"},
+		{token.COMMA, ","},
+		{token.IDENT, "c"},
+		{token.COLON, ":"},
+		{token.INT, "2"},
+		{token.RPAREN, ")"},
+		{token.EOF, "\x00"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestRangeTokens(t *testing.T) {
+	input := `1..n; a[1:]; 1.5..2; x...`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "1"},
+		{token.DOTDOT, ".."},
+		{token.IDENT, "n"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.LSQUARE, "["},
+		{token.INT, "1"},
+		{token.COLON, ":"},
+		{token.RSQUARE, "]"},
+		{token.SEMICOLON, ";"},
+		{token.FLOAT, "1.5"},
+		{token.DOTDOT, ".."},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ELLIPSIS, "..."},
+		{token.EOF, "\x00"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnicodeInput(t *testing.T) {
+	input := "let 名前 = \"héllo, 世界\"; größe + `日本`; \"\\u{1F600}😀\""
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		start           token.Position
+	}{
+		{token.LET, "let", token.Position{Offset: 0, Line: 1, Column: 1}},
+		{token.IDENT, "名前", token.Position{Offset: 4, Line: 1, Column: 5}},
+		{token.ASSIGN, "=", token.Position{Offset: 11, Line: 1, Column: 8}},
+		{token.STRING, "héllo, 世界", token.Position{Offset: 13, Line: 1, Column: 10}},
+		{token.SEMICOLON, ";", token.Position{Offset: 29, Line: 1, Column: 21}},
+		{token.IDENT, "größe", token.Position{Offset: 31, Line: 1, Column: 23}},
+		{token.PLUS, "+", token.Position{Offset: 39, Line: 1, Column: 29}},
+		{token.STRING, "日本", token.Position{Offset: 41, Line: 1, Column: 31}},
+		{token.SEMICOLON, ";", token.Position{Offset: 49, Line: 1, Column: 35}},
+		{token.STRING, "😀😀", token.Position{Offset: 51, Line: 1, Column: 37}},
+		{token.EOF, "\x00", token.Position{Offset: 66, Line: 1, Column: 49}},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected = %q, got = %q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected = %q, got = %q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Span.Start != tt.start {
+			t.Errorf("tests[%d] - start wrong. expected = %+v, got = %+v", i, tt.start, tok.Span.Start)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package mutil
 import (
 	"encoding/binary"
 	"errors"
+	"math"
 	"strconv"
 	"strings"
 	"zetsu/compiler"
@@ -46,6 +47,17 @@ func EncryptObject(obj object.Object, length int) (object.Object, error) {
 			Value:   bite,
 		}
 
+	case object.FLOAT_OBJ:
+		val := obj.(*object.Float).Value
+		bite := make([]byte, 8)
+		binary.LittleEndian.PutUint64(bite, math.Float64bits(val))
+		bite = security.XOR(bite, length)
+
+		encObj = &object.Encrypted{
+			EncType: object.FLOAT_OBJ,
+			Value:   bite,
+		}
+
 	case object.STRING_OBJ:
 		val := obj.(*object.String).Value
 		bite := security.XOR([]byte(val), length)
@@ -87,6 +99,10 @@ func DecryptObject(obj object.Object, length int) (object.Object, error) {
 			val := binary.LittleEndian.Uint64(bite)
 			decObj = &object.Integer{Value: int64(val)}
 
+		case object.FLOAT_OBJ:
+			val := binary.LittleEndian.Uint64(bite)
+			decObj = &object.Float{Value: math.Float64frombits(val)}
+
 		case object.STRING_OBJ:
 			decObj = &object.String{Value: string(bite)}
 
@@ -7,6 +7,15 @@ import (
 
 type Array struct{ Elements []Object }
 
+// Position resolves index against the elements of the array, negative
+// indexes count from the end. ok is false when it is out of range
+func (ao *Array) Position(index int64) (pos int64, ok bool) {
+	if index < 0 {
+		index += int64(len(ao.Elements))
+	}
+	return index, index >= 0 && index < int64(len(ao.Elements))
+}
+
 func (ao *Array) Type() ObjectType { return ARRAY_OBJ }
 func (ao *Array) Inspect() string {
 	var out bytes.Buffer
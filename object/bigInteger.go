@@ -0,0 +1,151 @@
+package object
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// BigInteger holds the integers that don't fit in an Integer. Integer
+// arithmetic promotes its result to a BigInteger when it overflows and
+// turns BigInteger results that fit back into an Integer, so a value
+// always has a single representation
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (bi *BigInteger) Type() ObjectType { return BIG_INTEGER_OBJ }
+func (bi *BigInteger) Inspect() string  { return bi.Value.String() }
+
+// ErrDivisionByZero is returned by integer division and modulo when the
+// right operand is zero
+var ErrDivisionByZero = errors.New("division by zero")
+
+// IsInteger reports whether obj is an Integer or a BigInteger
+func IsInteger(obj Object) bool {
+	switch obj.(type) {
+	case *Integer, *BigInteger:
+		return true
+	default:
+		return false
+	}
+}
+
+// IntegerArithmetic applies op, one of + - * / %, to two integers.
+// Division truncates towards zero and % takes the sign of left, as
+// they do for int64
+func IntegerArithmetic(op string, left, right Object) (Object, error) {
+	l, lok := left.(*Integer)
+	r, rok := right.(*Integer)
+	if lok && rok {
+		if result, ok := int64Arithmetic(op, l.Value, r.Value); ok {
+			return &Integer{Value: result}, nil
+		}
+	}
+
+	lval, rval := toBig(left), toBig(right)
+	if (op == "/" || op == "%") && rval.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+
+	result := new(big.Int)
+	switch op {
+	case "+":
+		result.Add(lval, rval)
+	case "-":
+		result.Sub(lval, rval)
+	case "*":
+		result.Mul(lval, rval)
+	case "/":
+		result.Quo(lval, rval)
+	case "%":
+		result.Rem(lval, rval)
+	default:
+		return nil, errors.New("unknown integer operator: " + op)
+	}
+	return normalize(result), nil
+}
+
+// int64Arithmetic is the fast path of IntegerArithmetic, ok is false
+// when the result overflows or the operation needs a closer look
+func int64Arithmetic(op string, l, r int64) (int64, bool) {
+	switch op {
+	case "+":
+		result := l + r
+		return result, (l^result)&(r^result) >= 0
+	case "-":
+		result := l - r
+		return result, (l^r)&(l^result) >= 0
+	case "*":
+		if l == 0 || r == 0 {
+			return 0, true
+		}
+		result := l * r
+		return result, result/r == l && !(l == -1 && r == math.MinInt64) && !(r == -1 && l == math.MinInt64)
+	case "/":
+		if r == 0 || (l == math.MinInt64 && r == -1) {
+			return 0, false
+		}
+		return l / r, true
+	case "%":
+		if r == 0 {
+			return 0, false
+		}
+		return l % r, true
+	default:
+		return 0, false
+	}
+}
+
+// NegateInteger returns -obj, obj has to be an integer
+func NegateInteger(obj Object) Object {
+	if i, ok := obj.(*Integer); ok && i.Value != math.MinInt64 {
+		return &Integer{Value: -i.Value}
+	}
+	return normalize(new(big.Int).Neg(toBig(obj)))
+}
+
+// CompareIntegers returns -1, 0 or 1 as left is less than, equal to or
+// greater than right, both have to be integers
+func CompareIntegers(left, right Object) int {
+	l, lok := left.(*Integer)
+	r, rok := right.(*Integer)
+	if lok && rok {
+		switch {
+		case l.Value < r.Value:
+			return -1
+		case l.Value > r.Value:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return toBig(left).Cmp(toBig(right))
+}
+
+// IntegerToFloat converts an integer for int/float promotion
+func IntegerToFloat(obj Object) float64 {
+	if i, ok := obj.(*Integer); ok {
+		return float64(i.Value)
+	}
+	f, _ := new(big.Float).SetInt(toBig(obj)).Float64()
+	return f
+}
+
+func toBig(obj Object) *big.Int {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value)
+	case *BigInteger:
+		return obj.Value
+	default:
+		return new(big.Int)
+	}
+}
+
+func normalize(value *big.Int) Object {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+	return &BigInteger{Value: value}
+}
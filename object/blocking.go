@@ -0,0 +1,118 @@
+package object
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrDeadlock is what a send, receive, select or wait fails with once
+// every goroutine running code is blocked, nothing is left that could
+// wake them
+var ErrDeadlock = errors.New("deadlock: every task is blocked")
+
+// blocking counts the goroutines that run code, see StartRunning, and
+// the waiters of the ones that are blocked. Its lock guards channels
+// and tasks too, so a goroutine handed a value is counted as running
+// again before the one handing it over can block in turn
+var blocking struct {
+	mu      sync.Mutex
+	running int
+	blocked map[*waiter]bool
+}
+
+// StartRunning counts a goroutine that runs code, the main vm and the
+// tasks it spawns, StopRunning is called once it is done. Blocking only
+// fails with ErrDeadlock while some goroutine is counted
+func StartRunning() {
+	blocking.mu.Lock()
+	blocking.running++
+	blocking.mu.Unlock()
+}
+
+func StopRunning() {
+	blocking.mu.Lock()
+	blocking.running--
+	failDeadlock()
+	blocking.mu.Unlock()
+}
+
+// waiter is a goroutine blocked on channels or a task, whoever wakes it
+// fills in the outcome first
+type waiter struct {
+	wake  chan struct{}
+	done  bool
+	value Object
+	ok    bool
+	index int
+	err   error
+}
+
+func newWaiter() *waiter { return &waiter{wake: make(chan struct{})} }
+
+// waiting is a waiter in the queue of a channel, index is the position
+// of the channel in a select and value what a sender sends
+type waiting struct {
+	w     *waiter
+	index int
+	value Object
+}
+
+// block waits until w is woken, blocking.mu is held when it is called
+// and released once it returns
+func (w *waiter) block() {
+	if blocking.blocked == nil {
+		blocking.blocked = map[*waiter]bool{}
+	}
+	blocking.blocked[w] = true
+	failDeadlock()
+	blocking.mu.Unlock()
+	<-w.wake
+}
+
+// resolve wakes w with an outcome, it reports false when w was woken
+// already. blocking.mu has to be held
+func (w *waiter) resolve(value Object, ok bool, index int, err error) bool {
+	if w.done {
+		return false
+	}
+	w.done = true
+	w.value, w.ok, w.index, w.err = value, ok, index, err
+	delete(blocking.blocked, w)
+	close(w.wake)
+	return true
+}
+
+// failDeadlock wakes every waiter with ErrDeadlock once all counted
+// goroutines are blocked. blocking.mu has to be held
+func failDeadlock() {
+	if blocking.running == 0 || len(blocking.blocked) < blocking.running {
+		return
+	}
+	for w := range blocking.blocked {
+		w.resolve(nil, false, 0, ErrDeadlock)
+	}
+}
+
+// enqueue adds entry to q, dropping the waiters that were woken by
+// something else while they waited in it
+func enqueue(q []waiting, entry waiting) []waiting {
+	kept := q[:0]
+	for _, e := range q {
+		if !e.w.done {
+			kept = append(kept, e)
+		}
+	}
+	return append(kept, entry)
+}
+
+// dequeue takes the first entry of q whose waiter is still blocked
+func dequeue(q *[]waiting) (waiting, bool) {
+	for len(*q) > 0 {
+		entry := (*q)[0]
+		*q = (*q)[1:]
+		if !entry.w.done {
+			return entry, true
+		}
+	}
+	return waiting{}, false
+}
@@ -0,0 +1,125 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSendOnClosed  = errors.New("send on closed channel")
+	ErrCloseOfClosed = errors.New("close of closed channel")
+)
+
+// Channel passes values between tasks, sends block until a receiver
+// takes the value or, when the channel is buffered, until there is
+// room in the buffer. Its state is guarded by the lock of blocking
+type Channel struct {
+	size   int
+	buffer []Object
+	closed bool
+	recvq  []waiting
+	sendq  []waiting
+}
+
+// NewChannel makes a channel that buffers size values
+func NewChannel(size int) *Channel { return &Channel{size: size} }
+
+func (c *Channel) Type() ObjectType { return CHANNEL_OBJ }
+func (c *Channel) Inspect() string  { return fmt.Sprintf("Channel[%p]", c) }
+
+// Send hands obj to a receiver, sending on a closed channel, or on one
+// that is closed while the send waits, is an error
+func (c *Channel) Send(obj Object) error {
+	blocking.mu.Lock()
+	if c.closed {
+		blocking.mu.Unlock()
+		return ErrSendOnClosed
+	}
+	if r, ok := dequeue(&c.recvq); ok {
+		r.w.resolve(obj, true, r.index, nil)
+		blocking.mu.Unlock()
+		return nil
+	}
+	if len(c.buffer) < c.size {
+		c.buffer = append(c.buffer, obj)
+		blocking.mu.Unlock()
+		return nil
+	}
+
+	w := newWaiter()
+	c.sendq = enqueue(c.sendq, waiting{w: w, value: obj})
+	w.block()
+	return w.err
+}
+
+// Receive waits for a value, ok is false once the channel is closed
+// and every value sent before has been received
+func (c *Channel) Receive() (Object, bool, error) {
+	blocking.mu.Lock()
+	if obj, ok, ready := c.take(); ready {
+		blocking.mu.Unlock()
+		return obj, ok, nil
+	}
+
+	w := newWaiter()
+	c.recvq = enqueue(c.recvq, waiting{w: w})
+	w.block()
+	return w.value, w.ok, w.err
+}
+
+// take receives without blocking, ready is false when Receive would
+// have to wait. blocking.mu has to be held
+func (c *Channel) take() (obj Object, ok, ready bool) {
+	if len(c.buffer) > 0 {
+		obj, c.buffer = c.buffer[0], c.buffer[1:]
+		if s, found := dequeue(&c.sendq); found {
+			c.buffer = append(c.buffer, s.value)
+			s.w.resolve(nil, true, 0, nil)
+		}
+		return obj, true, true
+	}
+	if s, found := dequeue(&c.sendq); found {
+		s.w.resolve(nil, true, 0, nil)
+		return s.value, true, true
+	}
+	return nil, false, c.closed
+}
+
+// Close makes receives on the channel stop waiting once its buffer is
+// drained, a channel can only be closed once
+func (c *Channel) Close() error {
+	blocking.mu.Lock()
+	defer blocking.mu.Unlock()
+	if c.closed {
+		return ErrCloseOfClosed
+	}
+	c.closed = true
+	for _, r := range c.recvq {
+		r.w.resolve(nil, false, r.index, nil)
+	}
+	for _, s := range c.sendq {
+		s.w.resolve(nil, false, 0, ErrSendOnClosed)
+	}
+	c.recvq, c.sendq = nil, nil
+	return nil
+}
+
+// Select waits until one of chans has a value, or is closed, and
+// receives from it. It returns the index of that channel along with
+// what Receive would have
+func Select(chans []*Channel) (int, Object, bool, error) {
+	blocking.mu.Lock()
+	for i, c := range chans {
+		if obj, ok, ready := c.take(); ready {
+			blocking.mu.Unlock()
+			return i, obj, ok, nil
+		}
+	}
+
+	w := newWaiter()
+	for i, c := range chans {
+		c.recvq = enqueue(c.recvq, waiting{w: w, index: i})
+	}
+	w.block()
+	return w.index, w.value, w.ok, w.err
+}
@@ -4,8 +4,52 @@ import "fmt"
 
 type Closure struct {
 	Fn   *CompiledFunction
-	Free []Object
+	Free []*Upvalue
 }
 
 func (c *Closure) Type() ObjectType { return CLOSURE_OBJ }
 func (c *Closure) Inspect() string  { return fmt.Sprintf("Closure[%p]", c) }
+
+// Upvalue is a variable a closure captured. It is open while the
+// function that defines the variable runs, reads and writes then go to
+// the stack slot of the variable, and closed once that function
+// returns, the upvalue then holds the variable itself. Closures that
+// capture the same variable share its upvalue
+type Upvalue struct {
+	slot  *Object
+	value Object
+	// Index is the stack slot of an open upvalue
+	Index int
+}
+
+func (u *Upvalue) Type() ObjectType { return UPVALUE_OBJ }
+func (u *Upvalue) Inspect() string  { return fmt.Sprintf("Upvalue[%p]", u) }
+
+// NewUpvalue opens an upvalue on the stack slot at index
+func NewUpvalue(slot *Object, index int) *Upvalue {
+	return &Upvalue{slot: slot, Index: index}
+}
+
+// ClosedUpvalue makes an upvalue that holds value from the start
+func ClosedUpvalue(value Object) *Upvalue {
+	u := &Upvalue{value: value}
+	u.slot = &u.value
+	return u
+}
+
+func (u *Upvalue) Get() Object    { return *u.slot }
+func (u *Upvalue) Set(obj Object) { *u.slot = obj }
+
+// Close moves the variable off the stack into the upvalue
+func (u *Upvalue) Close() {
+	u.value = *u.slot
+	u.slot = &u.value
+}
+
+// Reopen moves a closed variable back onto the stack slot at index
+func (u *Upvalue) Reopen(slot *Object, index int) {
+	*slot = u.value
+	u.value = nil
+	u.slot = slot
+	u.Index = index
+}
@@ -6,9 +6,29 @@ import (
 )
 
 type CompiledFunction struct {
+	Name         string // empty for anonymous functions
 	Instructions code.Instructions
 	NumLocals    int
 	NumParams    int
+	// Params names the parameters so calls can pass arguments by name,
+	// the last NumDefaults of them have a default value
+	Params      []string
+	NumDefaults int
+	// Rest is set when the function packs its extra arguments into an
+	// array, it is kept in the local right after the parameters
+	Rest bool
+	// Generator is set for `fn*` functions, calling one suspends its
+	// frame into a generator straight away
+	Generator bool
+	SourceMap code.SourceMap
+}
+
+// DisplayName returns the name used for the function in error messages
+func (cf *CompiledFunction) DisplayName() string {
+	if cf.Name == "" {
+		return "fn"
+	}
+	return cf.Name
 }
 
 func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FN_OBJ }
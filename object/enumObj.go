@@ -0,0 +1,52 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnumType is what an `enum Status { Pending, Done }` declaration
+// binds Status to, Variants are kept in declaration order
+type EnumType struct {
+	Name     string
+	Variants []string
+}
+
+func (et *EnumType) Type() ObjectType { return ENUM_TYPE_OBJ }
+func (et *EnumType) Inspect() string {
+	return "enum " + et.Name + " { " + strings.Join(et.Variants, ", ") + " }"
+}
+
+// Variant returns the value of the variant called name
+func (et *EnumType) Variant(name string) (*EnumValue, error) {
+	for i, v := range et.Variants {
+		if v == name {
+			return &EnumValue{Enum: et.Name, Name: v, Ordinal: i}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no variant %s", et.Name, name)
+}
+
+// EnumValue is a variant of an enum type, Ordinal is its position in
+// the declaration. Values of a variant are equal however they were
+// reached, so they compare and hash by Enum and Ordinal
+type EnumValue struct {
+	Enum    string
+	Name    string
+	Ordinal int
+}
+
+func (ev *EnumValue) Type() ObjectType { return ENUM_OBJ }
+func (ev *EnumValue) Inspect() string  { return ev.Enum + "." + ev.Name }
+
+// Get reads the name or the ordinal of the variant
+func (ev *EnumValue) Get(field string) (Object, error) {
+	switch field {
+	case "name":
+		return &String{Value: ev.Name}, nil
+	case "ordinal":
+		return &Integer{Value: int64(ev.Ordinal)}, nil
+	default:
+		return nil, fmt.Errorf("%s has no field %s", ev.Inspect(), field)
+	}
+}
@@ -1,13 +1,29 @@
 package object
 
+import "zetsu/token"
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store  map[string]Object
+	consts map[string]bool
+	outer  *Environment
+	// block marks the environment of a single block, see
+	// NewBlockEnvironment
+	block bool
+	// call is set on the environment of a function call, see Call
+	call *Call
+}
+
+// Call is a function call the evaluator is in. Site is where it was
+// made from and Caller the call that made it, nil for the top level
+type Call struct {
+	Name   string
+	Site   token.Span
+	Caller *Call
 }
 
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	return &Environment{store: s, consts: map[string]bool{}, outer: nil}
 }
 
 func NewEnclosedEnvironement(outer *Environment) *Environment {
@@ -16,6 +32,37 @@ func NewEnclosedEnvironement(outer *Environment) *Environment {
 	return env
 }
 
+// NewBlockEnvironment encloses outer for one block, the variable of a
+// for-in loop, the names of a match pattern or a catch parameter. Only
+// the names bound with Bind live in it, the rest is defined in outer
+func NewBlockEnvironment(outer *Environment) *Environment {
+	env := NewEnclosedEnvironement(outer)
+	env.block = true
+	return env
+}
+
+// EnterCall marks e as the environment call runs in
+func (e *Environment) EnterCall(call *Call) {
+	e.call = call
+}
+
+// Call returns the innermost call e is evaluated in, nil at the top
+// level
+func (e *Environment) Call() *Call {
+	for env := e; env != nil; env = env.outer {
+		if env.call != nil {
+			return env.call
+		}
+	}
+	return nil
+}
+
+// Bind defines name in this environment even when it is a block
+func (e *Environment) Bind(name string, val Object) Object {
+	e.store[name] = val
+	return val
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
 	if (!ok) && (e.outer != nil) {
@@ -25,6 +72,56 @@ func (e *Environment) Get(name string) (Object, bool) {
 }
 
 func (e *Environment) Set(name string, val Object) Object {
+	if e.block && !e.binds(name) {
+		return e.outer.Set(name, val)
+	}
 	e.store[name] = val
 	return val
 }
+
+// Assign overwrites name in the environment that defines it, it
+// reports false when name was never defined
+func (e *Environment) Assign(name string, val Object) bool {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		return true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return false
+}
+
+// SetConst defines name as a constant of this environment
+func (e *Environment) SetConst(name string, val Object) Object {
+	if e.block && !e.binds(name) {
+		return e.outer.SetConst(name, val)
+	}
+	e.consts[name] = true
+	return e.Set(name, val)
+}
+
+// DefinesConst reports whether name is a constant of this environment,
+// leaving out the environments it is enclosed by
+func (e *Environment) DefinesConst(name string) bool {
+	if e.block && !e.binds(name) {
+		return e.outer.DefinesConst(name)
+	}
+	return e.consts[name]
+}
+
+func (e *Environment) binds(name string) bool {
+	_, ok := e.store[name]
+	return ok
+}
+
+// IsConst reports whether the binding name refers to is a constant
+func (e *Environment) IsConst(name string) bool {
+	if _, ok := e.store[name]; ok {
+		return e.consts[name]
+	}
+	if e.outer != nil {
+		return e.outer.IsConst(name)
+	}
+	return false
+}
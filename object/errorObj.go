@@ -1,6 +1,27 @@
 package object
 
-type Error struct{ Message string }
+// Error is raised by runtime faults, failing builtins and throw. Stack
+// lists the calls that were active when it was raised, innermost first
+type Error struct {
+	Message string
+	Stack   []string
+}
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR:" + e.Message }
+
+// Field looks up the parts of an error scripts can read with
+// e["message"] and e["stack"]
+func (e *Error) Field(name string) (Object, bool) {
+	switch name {
+	case "message":
+		return &String{Value: e.Message}, true
+	case "stack":
+		frames := make([]Object, len(e.Stack))
+		for i, frame := range e.Stack {
+			frames[i] = &String{Value: frame}
+		}
+		return &Array{Elements: frames}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,45 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// Inspect always shows a fraction or an exponent so that floats can
+// be told apart from integers when printed
+func (f *Float) Inspect() string {
+	str := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(str, ".eIN") {
+		str += ".0"
+	}
+	return str
+}
+
+// IsNumber reports whether obj takes part in int/float promotion, the
+// vm and the evaluator both go through it so the rules stay the same
+func IsNumber(obj Object) bool {
+	switch obj.(type) {
+	case *Integer, *BigInteger, *Float:
+		return true
+	default:
+		return false
+	}
+}
+
+// ToFloat converts a number for int/float promotion, see IsNumber
+func ToFloat(obj Object) float64 {
+	switch obj := obj.(type) {
+	case *Integer, *BigInteger:
+		return IntegerToFloat(obj)
+	case *Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
@@ -7,19 +7,37 @@ import (
 )
 
 type Function struct {
+	Name       string // empty for anonymous functions
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression // see ast.FunctionLiteral.Defaults
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
 
+// DisplayName returns the name used for the function in error messages
+func (f *Function) DisplayName() string {
+	if f.Name == "" {
+		return "fn"
+	}
+	return f.Name
+}
+
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 	params := []string{}
 
-	for _, p := range f.Parameters {
+	for i, p := range f.Parameters {
+		if i < len(f.Defaults) && f.Defaults[i] != nil {
+			params = append(params, p.String()+" = "+f.Defaults[i].String())
+			continue
+		}
 		params = append(params, p.String())
 	}
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
+	}
 
 	out.WriteString("fn")
 	out.WriteString("(")
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"strings"
 )
 
@@ -35,12 +36,36 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+func (bi *BigInteger) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write(bi.Value.Bytes())
+	value := h.Sum64()
+	if bi.Value.Sign() < 0 {
+		value = ^value
+	}
+	return HashKey{Type: bi.Type(), Value: value}
+}
+
+func (f *Float) HashKey() HashKey {
+	value := f.Value
+	if value == 0 {
+		value = 0 // -0 and 0 are the same key
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(value)}
+}
+
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(s.Value))
 	return HashKey{Type: s.Type(), Value: h.Sum64()}
 }
 
+func (ev *EnumValue) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(ev.Enum))
+	return HashKey{Type: ev.Type(), Value: h.Sum64() ^ uint64(ev.Ordinal)}
+}
+
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
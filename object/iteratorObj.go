@@ -0,0 +1,76 @@
+package object
+
+import "sort"
+
+// Iterator hands out the items of a collection one at a time, it
+// backs for-in loops in both the evaluator and the vm
+type Iterator struct {
+	next func() (Object, bool)
+	err  error
+}
+
+func (it *Iterator) Type() ObjectType { return ITERATOR_OBJ }
+func (it *Iterator) Inspect() string  { return "iterator" }
+
+// Next returns the following item, ok is false once the
+// collection is exhausted
+func (it *Iterator) Next() (Object, bool) { return it.next() }
+
+// Err is what stopped the iterator before the collection was
+// exhausted, a receive that failed
+func (it *Iterator) Err() error { return it.err }
+
+// NewIterator walks the elements of an array, the characters of a
+// string, the items of a range, the keys of a hash or the values sent
+// on a channel until it is closed, hash keys are visited in sorted
+// order so that loops over hashes are deterministic
+func NewIterator(obj Object) (*Iterator, bool) {
+	switch obj := obj.(type) {
+	case *Array:
+		i := 0
+		return &Iterator{next: func() (Object, bool) {
+			if i >= len(obj.Elements) {
+				return nil, false
+			}
+			i++
+			return obj.Elements[i-1], true
+		}}, true
+	case *String:
+		chars := []rune(obj.Value)
+		i := 0
+		return &Iterator{next: func() (Object, bool) {
+			if i >= len(chars) {
+				return nil, false
+			}
+			i++
+			return &String{Value: string(chars[i-1])}, true
+		}}, true
+	case *Range:
+		i := int64(0)
+		return &Iterator{next: func() (Object, bool) {
+			item, ok := obj.At(i)
+			if !ok {
+				return nil, false
+			}
+			i++
+			return item, true
+		}}, true
+	case *Channel:
+		it := &Iterator{}
+		it.next = func() (Object, bool) {
+			item, ok, err := obj.Receive()
+			it.err = err
+			return item, ok && err == nil
+		}
+		return it, true
+	case *Hash:
+		keys := make([]Object, 0, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			keys = append(keys, pair.Key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Inspect() < keys[j].Inspect() })
+		return NewIterator(&Array{Elements: keys})
+	default:
+		return nil, false
+	}
+}
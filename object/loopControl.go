@@ -0,0 +1,14 @@
+package object
+
+// Break and Continue are only ever seen by the evaluator, they
+// unwind the statements of a loop body just like ReturnValue does
+// for function bodies
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
@@ -4,6 +4,8 @@ type ObjectType string
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	BIG_INTEGER_OBJ  = "BIG_INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
@@ -18,6 +20,18 @@ const (
 	COMPILED_FN_OBJ  = "COMPILED_FN_OBJ"
 	CLOSURE_OBJ      = "CLOSURE"
 	ENCRYPTED_OBJ    = "ENCRYPTED"
+	ITERATOR_OBJ     = "ITERATOR"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
+	STRUCT_TYPE_OBJ  = "STRUCT_TYPE"
+	STRUCT_OBJ       = "STRUCT"
+	RANGE_OBJ        = "RANGE"
+	ENUM_TYPE_OBJ    = "ENUM_TYPE"
+	ENUM_OBJ         = "ENUM"
+	GENERATOR_OBJ    = "GENERATOR"
+	TASK_OBJ         = "TASK"
+	CHANNEL_OBJ      = "CHANNEL"
+	UPVALUE_OBJ      = "UPVALUE"
 )
 
 type Object interface {
@@ -1,6 +1,44 @@
 package object
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestFloatHashKey(t *testing.T) {
+	half1 := &Float{Value: 0.5}
+	half2 := &Float{Value: 0.5}
+	quarter := &Float{Value: 0.25}
+
+	if half1.HashKey() != half2.HashKey() {
+		t.Errorf("floats with same value have different hash keys")
+	}
+	if half1.HashKey() == quarter.HashKey() {
+		t.Errorf("floats with different values have same hash keys")
+	}
+	if (&Float{Value: 1}).HashKey() == (&Integer{Value: 1}).HashKey() {
+		t.Errorf("float and integer share a hash key")
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{2, "2.0"},
+		{2.5, "2.5"},
+		{-0.125, "-0.125"},
+		{1e21, "1e+21"},
+	}
+
+	for _, tt := range tests {
+		if got := (&Float{Value: tt.value}).Inspect(); got != tt.expected {
+			t.Errorf("wrong Inspect for %g. want=%q, got=%q", tt.value, tt.expected, got)
+		}
+	}
+}
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -19,3 +57,270 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestStructs(t *testing.T) {
+	point := &StructType{Name: "Point", Fields: []string{"x", "y"}}
+	if point.Inspect() != "struct Point { x, y }" {
+		t.Errorf("wrong struct type inspect. got=%q", point.Inspect())
+	}
+
+	p, err := point.New([]string{"y"}, []Object{&Integer{Value: 2}}, &Null{})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+	if p.Values[0].Type() != NULL_OBJ {
+		t.Errorf("missing field is not null. got=%s", p.Values[0].Type())
+	}
+
+	if err := p.Set("x", &Integer{Value: 1}); err != nil {
+		t.Fatalf("Set returned error: %s", err)
+	}
+	if p.Inspect() != "Point{x: 1, y: 2}" {
+		t.Errorf("wrong struct inspect. got=%q", p.Inspect())
+	}
+	if x, _ := p.Get("x"); x.Inspect() != "1" {
+		t.Errorf("wrong field x. got=%s", x.Inspect())
+	}
+
+	if _, err := point.New([]string{"z"}, []Object{&Integer{Value: 3}}, &Null{}); err == nil || err.Error() != "Point has no field z" {
+		t.Errorf("wrong error for unknown field. got=%v", err)
+	}
+	if _, err := p.Get("z"); err == nil {
+		t.Errorf("expected error reading unknown field")
+	}
+}
+
+func TestRanges(t *testing.T) {
+	tests := []struct {
+		rng     *Range
+		inspect string
+		items   []int64
+	}{
+		{&Range{Start: 1, End: 5, Step: 1}, "1..5", []int64{1, 2, 3, 4}},
+		{&Range{Start: 0, End: 10, Step: 3}, "range(0, 10, 3)", []int64{0, 3, 6, 9}},
+		{&Range{Start: 5, End: 0, Step: -2}, "range(5, 0, -2)", []int64{5, 3, 1}},
+		{&Range{Start: 5, End: 5, Step: 1}, "5..5", []int64{}},
+		{&Range{Start: 5, End: 1, Step: 1}, "5..1", []int64{}},
+	}
+
+	for _, tt := range tests {
+		if tt.rng.Inspect() != tt.inspect {
+			t.Errorf("wrong inspect. want=%q, got=%q", tt.inspect, tt.rng.Inspect())
+		}
+		if tt.rng.Len() != int64(len(tt.items)) {
+			t.Errorf("wrong length for %s. want=%d, got=%d", tt.inspect, len(tt.items), tt.rng.Len())
+		}
+		for i, want := range tt.items {
+			if got, ok := tt.rng.At(int64(i)); !ok || got.Value != want {
+				t.Errorf("wrong item %d of %s. want=%d, got=%v", i, tt.inspect, want, got)
+			}
+		}
+		if _, ok := tt.rng.At(int64(len(tt.items))); ok {
+			t.Errorf("item past the end of %s reported ok", tt.inspect)
+		}
+	}
+
+	if _, err := NewRange(&Integer{Value: 1}, &Integer{Value: 2}, &Integer{Value: 0}); err == nil || err.Error() != "range step cannot be zero" {
+		t.Errorf("wrong error for zero step. got=%v", err)
+	}
+	if _, err := NewRange(&Integer{Value: 1}, &String{Value: "a"}, &Integer{Value: 1}); err == nil || err.Error() != "range bounds must be INTEGER, got STRING" {
+		t.Errorf("wrong error for string bound. got=%v", err)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	null := &Null{}
+	arr := &Array{Elements: []Object{&Integer{Value: 1}, &Integer{Value: 2}, &Integer{Value: 3}, &Integer{Value: 4}}}
+	tests := []struct {
+		obj        Object
+		start, end Object
+		expected   string
+	}{
+		{arr, &Integer{Value: 1}, &Integer{Value: 3}, "[2, 3]"},
+		{arr, null, &Integer{Value: 2}, "[1, 2]"},
+		{arr, &Integer{Value: 2}, null, "[3, 4]"},
+		{arr, &Integer{Value: -3}, &Integer{Value: -1}, "[2, 3]"},
+		{arr, &Integer{Value: -10}, &Integer{Value: 10}, "[1, 2, 3, 4]"},
+		{arr, &Integer{Value: 3}, &Integer{Value: 1}, "[]"},
+		{&String{Value: "hello"}, &Integer{Value: 1}, &Integer{Value: -1}, "ell"},
+		{&String{Value: "日本語です"}, &Integer{Value: 1}, &Integer{Value: 3}, "本語"},
+		{&Range{Start: 0, End: 10, Step: 2}, &Integer{Value: 1}, &Integer{Value: 3}, "range(2, 6, 2)"},
+		{&Range{Start: 0, End: 10, Step: 1}, &Integer{Value: -3}, null, "7..10"},
+	}
+
+	for _, tt := range tests {
+		slice, err := Slice(tt.obj, tt.start, tt.end)
+		if err != nil {
+			t.Fatalf("Slice returned error: %s", err)
+		}
+		if slice.Inspect() != tt.expected {
+			t.Errorf("wrong slice of %s[%s:%s]. want=%q, got=%q", tt.obj.Inspect(), tt.start.Inspect(), tt.end.Inspect(), tt.expected, slice.Inspect())
+		}
+	}
+
+	if _, err := Slice(arr, &String{Value: "a"}, null); err == nil || err.Error() != "slice bounds must be INTEGER, got STRING" {
+		t.Errorf("wrong error for string bound. got=%v", err)
+	}
+	if _, err := Slice(&Integer{Value: 1}, null, null); err == nil || err.Error() != "slice operator not supported: INTEGER" {
+		t.Errorf("wrong error for integer. got=%v", err)
+	}
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	maxInt := &Integer{Value: math.MaxInt64}
+	minInt := &Integer{Value: math.MinInt64}
+	huge := &BigInteger{Value: new(big.Int).Add(big.NewInt(math.MaxInt64), big.NewInt(1))}
+	tests := []struct {
+		op           string
+		left, right  Object
+		expected     string
+		expectedType ObjectType
+	}{
+		{"+", &Integer{Value: 2}, &Integer{Value: 3}, "5", INTEGER_OBJ},
+		{"+", maxInt, &Integer{Value: 1}, "9223372036854775808", BIG_INTEGER_OBJ},
+		{"-", minInt, &Integer{Value: 1}, "-9223372036854775809", BIG_INTEGER_OBJ},
+		{"*", maxInt, &Integer{Value: 2}, "18446744073709551614", BIG_INTEGER_OBJ},
+		{"*", minInt, &Integer{Value: -1}, "9223372036854775808", BIG_INTEGER_OBJ},
+		{"/", minInt, &Integer{Value: -1}, "9223372036854775808", BIG_INTEGER_OBJ},
+		{"/", &Integer{Value: -7}, &Integer{Value: 2}, "-3", INTEGER_OBJ},
+		{"%", &Integer{Value: -7}, &Integer{Value: 2}, "-1", INTEGER_OBJ},
+		{"-", huge, &Integer{Value: 1}, "9223372036854775807", INTEGER_OBJ},
+		{"%", huge, &Integer{Value: 10}, "8", INTEGER_OBJ},
+	}
+
+	for _, tt := range tests {
+		result, err := IntegerArithmetic(tt.op, tt.left, tt.right)
+		if err != nil {
+			t.Fatalf("IntegerArithmetic returned error: %s", err)
+		}
+		if result.Type() != tt.expectedType || result.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s %s %s. want=%s (%s), got=%s (%s)", tt.left.Inspect(), tt.op, tt.right.Inspect(),
+				tt.expected, tt.expectedType, result.Inspect(), result.Type())
+		}
+	}
+
+	for _, op := range []string{"/", "%"} {
+		if _, err := IntegerArithmetic(op, huge, &Integer{Value: 0}); err != ErrDivisionByZero {
+			t.Errorf("wrong error for %s by zero. got=%v", op, err)
+		}
+	}
+}
+
+func TestBigIntegerHashKey(t *testing.T) {
+	one := &BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 70)}
+	two := &BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 70)}
+	neg := &BigInteger{Value: new(big.Int).Neg(one.Value)}
+	other := &BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 71)}
+
+	if one.HashKey() != two.HashKey() {
+		t.Errorf("big integers with same value have different hash keys")
+	}
+	if one.HashKey() == neg.HashKey() {
+		t.Errorf("big integers with opposite signs have same hash keys")
+	}
+	if one.HashKey() == other.HashKey() {
+		t.Errorf("big integers with different values have same hash keys")
+	}
+}
+
+func TestEnumValueHashKey(t *testing.T) {
+	status := &EnumType{Name: "Status", Variants: []string{"Pending", "Done"}}
+	done1, _ := status.Variant("Done")
+	done2 := &EnumValue{Enum: "Status", Name: "Done", Ordinal: 1}
+	pending, _ := status.Variant("Pending")
+	other := &EnumValue{Enum: "Color", Name: "Done", Ordinal: 1}
+
+	if done1.HashKey() != done2.HashKey() {
+		t.Errorf("enum values of the same variant have different hash keys")
+	}
+	if done1.HashKey() == pending.HashKey() {
+		t.Errorf("enum values of different variants have same hash keys")
+	}
+	if done1.HashKey() == other.HashKey() {
+		t.Errorf("enum values of different enums have same hash keys")
+	}
+	if done1.Inspect() != "Status.Done" {
+		t.Errorf("wrong Inspect. got=%q", done1.Inspect())
+	}
+	if _, err := status.Variant("Lost"); err == nil || err.Error() != "Status has no variant Lost" {
+		t.Errorf("wrong error for unknown variant. got=%v", err)
+	}
+}
+
+func TestChannels(t *testing.T) {
+	ch := NewChannel(2)
+	if err := ch.Send(&Integer{Value: 1}); err != nil {
+		t.Fatalf("send failed: %s", err)
+	}
+	ch.Send(&Integer{Value: 2})
+	if err := ch.Close(); err != nil {
+		t.Fatalf("close failed: %s", err)
+	}
+	if err := ch.Close(); err != ErrCloseOfClosed {
+		t.Errorf("closing twice gave %v", err)
+	}
+	if err := ch.Send(&Integer{Value: 3}); err != ErrSendOnClosed {
+		t.Errorf("sending on a closed channel gave %v", err)
+	}
+
+	it, ok := NewIterator(ch)
+	if !ok {
+		t.Fatalf("channels are not iterable")
+	}
+	for _, want := range []int64{1, 2} {
+		obj, ok := it.Next()
+		if !ok || obj.(*Integer).Value != want {
+			t.Errorf("wrong item. want=%d, got=%v", want, obj)
+		}
+	}
+	if _, ok := it.Next(); ok {
+		t.Errorf("iterating a drained closed channel didn't stop")
+	}
+
+	idle, ready := NewChannel(0), NewChannel(1)
+	ready.Send(&String{Value: "x"})
+	index, obj, ok, err := Select([]*Channel{idle, ready})
+	if index != 1 || !ok || err != nil || obj.Inspect() != "x" {
+		t.Errorf("wrong select. got=%d, %v, %t, %v", index, obj, ok, err)
+	}
+}
+
+func TestDeadlock(t *testing.T) {
+	StartRunning()
+	defer StopRunning()
+
+	if _, _, err := NewChannel(0).Receive(); err != ErrDeadlock {
+		t.Errorf("receiving with no sender gave %v", err)
+	}
+	if err := NewChannel(1).Send(&Integer{Value: 1}); err != nil {
+		t.Errorf("sending into a buffer failed: %s", err)
+	}
+	if err := NewChannel(0).Send(&Integer{Value: 1}); err != ErrDeadlock {
+		t.Errorf("sending with no receiver gave %v", err)
+	}
+	if _, _, _, err := Select([]*Channel{NewChannel(0)}); err != ErrDeadlock {
+		t.Errorf("selecting with no sender gave %v", err)
+	}
+	if _, err := NewTask().Wait(); err != ErrDeadlock {
+		t.Errorf("waiting for a task that never runs gave %v", err)
+	}
+}
+
+func TestTasks(t *testing.T) {
+	task := NewTask()
+	go task.Finish(&Integer{Value: 4}, nil)
+	result, err := task.Wait()
+	if err != nil || result.(*Integer).Value != 4 {
+		t.Errorf("wrong task result. got=%v, %v", result, err)
+	}
+
+	failed := NewTask()
+	failed.Finish(nil, ErrDivisionByZero)
+	if failed.Unobserved() != ErrDivisionByZero {
+		t.Errorf("error of a task no one waited for is not reported")
+	}
+	failed.Wait()
+	if failed.Unobserved() != nil {
+		t.Errorf("error of a task that was waited for is reported")
+	}
+}
@@ -0,0 +1,61 @@
+package object
+
+import "fmt"
+
+// Range is the lazy sequence start, start+step, ... up to, but not
+// including, End. It is what `1..10` and the range builtin evaluate to,
+// its items are only worked out as they are asked for
+type Range struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("%d..%d", r.Start, r.End)
+	}
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.End, r.Step)
+}
+
+// NewRange builds the range from start to end counting by step, all
+// three have to be integers and step can't be zero
+func NewRange(start, end, step Object) (*Range, error) {
+	bounds := make([]int64, 3)
+	for i, obj := range []Object{start, end, step} {
+		integer, ok := obj.(*Integer)
+		if !ok {
+			return nil, fmt.Errorf("range bounds must be INTEGER, got %s", obj.Type())
+		}
+		bounds[i] = integer.Value
+	}
+	if bounds[2] == 0 {
+		return nil, fmt.Errorf("range step cannot be zero")
+	}
+	return &Range{Start: bounds[0], End: bounds[1], Step: bounds[2]}, nil
+}
+
+// Len returns the number of items in the range
+func (r *Range) Len() int64 {
+	if r.Step > 0 && r.End > r.Start {
+		return (r.End - r.Start + r.Step - 1) / r.Step
+	}
+	if r.Step < 0 && r.End < r.Start {
+		return (r.Start - r.End - r.Step - 1) / -r.Step
+	}
+	return 0
+}
+
+// At returns the i-th item of the range, negative indexes count from
+// the end. ok is false when i is out of range
+func (r *Range) At(i int64) (*Integer, bool) {
+	n := r.Len()
+	if i < 0 {
+		i += n
+	}
+	if i < 0 || i >= n {
+		return nil, false
+	}
+	return &Integer{Value: r.Start + i*r.Step}, true
+}
@@ -0,0 +1,74 @@
+package object
+
+import "fmt"
+
+// Slice returns the part of an array, string or range that a[start:end]
+// selects. A null bound stands for a bound that was left out, negative
+// bounds count from the end and bounds past either end are clamped, so
+// slicing never fails on the values of its bounds. Strings are sliced
+// by character, arrays and strings are copied and slicing a range gives
+// back another range
+func Slice(obj, start, end Object) (Object, error) {
+	switch obj := obj.(type) {
+	case *Array:
+		lo, hi, err := sliceBounds(start, end, int64(len(obj.Elements)))
+		if err != nil {
+			return nil, err
+		}
+		elements := make([]Object, hi-lo)
+		copy(elements, obj.Elements[lo:hi])
+		return &Array{Elements: elements}, nil
+	case *String:
+		chars := []rune(obj.Value)
+		lo, hi, err := sliceBounds(start, end, int64(len(chars)))
+		if err != nil {
+			return nil, err
+		}
+		return &String{Value: string(chars[lo:hi])}, nil
+	case *Range:
+		lo, hi, err := sliceBounds(start, end, obj.Len())
+		if err != nil {
+			return nil, err
+		}
+		return &Range{Start: obj.Start + lo*obj.Step, End: obj.Start + hi*obj.Step, Step: obj.Step}, nil
+	default:
+		return nil, fmt.Errorf("slice operator not supported: %s", obj.Type())
+	}
+}
+
+// sliceBounds turns the bounds of a slice into indexes into a sequence
+// of n items, lo is never past hi
+func sliceBounds(start, end Object, n int64) (lo, hi int64, err error) {
+	if lo, err = sliceBound(start, 0, n); err != nil {
+		return 0, 0, err
+	}
+	if hi, err = sliceBound(end, n, n); err != nil {
+		return 0, 0, err
+	}
+	if lo > hi {
+		hi = lo
+	}
+	return lo, hi, nil
+}
+
+func sliceBound(bound Object, missing, n int64) (int64, error) {
+	if bound.Type() == NULL_OBJ {
+		return missing, nil
+	}
+	integer, ok := bound.(*Integer)
+	if !ok {
+		return 0, fmt.Errorf("slice bounds must be INTEGER, got %s", bound.Type())
+	}
+
+	i := integer.Value
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		return 0, nil
+	}
+	if i > n {
+		return n, nil
+	}
+	return i, nil
+}
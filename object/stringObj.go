@@ -4,3 +4,19 @@ type String struct{ Value string }
 
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return s.Value }
+
+// At returns the i-th character of the string, negative indexes count
+// from the end. Strings are indexed by character, not by byte, so
+// multi-byte UTF-8 characters come back whole. ok is false when i is
+// out of range
+func (s *String) At(i int64) (*String, bool) {
+	chars := []rune(s.Value)
+	n := int64(len(chars))
+	if i < 0 {
+		i += n
+	}
+	if i < 0 || i >= n {
+		return nil, false
+	}
+	return &String{Value: string(chars[i])}, true
+}
@@ -0,0 +1,83 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// StructType is what a `struct Point { x, y }` declaration binds
+// Point to, Fields are kept in declaration order
+type StructType struct {
+	Name   string
+	Fields []string
+}
+
+func (st *StructType) Type() ObjectType { return STRUCT_TYPE_OBJ }
+func (st *StructType) Inspect() string {
+	return "struct " + st.Name + " { " + strings.Join(st.Fields, ", ") + " }"
+}
+
+// FieldIndex returns the position of field in the declaration
+func (st *StructType) FieldIndex(field string) (int, bool) {
+	for i, f := range st.Fields {
+		if f == field {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// New builds a value of the struct type, fields left out are null
+func (st *StructType) New(fields []string, values []Object, null Object) (*Struct, error) {
+	s := &Struct{Def: st, Values: make([]Object, len(st.Fields))}
+	for i := range s.Values {
+		s.Values[i] = null
+	}
+	for i, field := range fields {
+		index, ok := st.FieldIndex(field)
+		if !ok {
+			return nil, fmt.Errorf("%s has no field %s", st.Name, field)
+		}
+		s.Values[index] = values[i]
+	}
+	return s, nil
+}
+
+// Struct is a value of a struct type, Values line up with Def.Fields
+type Struct struct {
+	Def    *StructType
+	Values []Object
+}
+
+func (s *Struct) Type() ObjectType { return STRUCT_OBJ }
+func (s *Struct) Inspect() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for i, f := range s.Def.Fields {
+		fields = append(fields, f+": "+s.Values[i].Inspect())
+	}
+
+	out.WriteString(s.Def.Name)
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+func (s *Struct) Get(field string) (Object, error) {
+	index, ok := s.Def.FieldIndex(field)
+	if !ok {
+		return nil, fmt.Errorf("%s has no field %s", s.Def.Name, field)
+	}
+	return s.Values[index], nil
+}
+
+func (s *Struct) Set(field string, value Object) error {
+	index, ok := s.Def.FieldIndex(field)
+	if !ok {
+		return fmt.Errorf("%s has no field %s", s.Def.Name, field)
+	}
+	s.Values[index] = value
+	return nil
+}
@@ -0,0 +1,72 @@
+package object
+
+import "fmt"
+
+// Task is what spawn evaluates to, the spawned call hands its result,
+// or the error it failed with, to the task once it is done. Its state
+// is guarded by the lock of blocking
+type Task struct {
+	done    bool
+	waiters []*waiter
+	result  Object
+	err     error
+	waited  bool
+}
+
+func NewTask() *Task { return &Task{} }
+
+func (t *Task) Type() ObjectType { return TASK_OBJ }
+func (t *Task) Inspect() string  { return fmt.Sprintf("Task[%p]", t) }
+
+// Finish records the outcome of the spawned call, it must be called
+// exactly once
+func (t *Task) Finish(result Object, err error) {
+	blocking.mu.Lock()
+	defer blocking.mu.Unlock()
+	t.result, t.err, t.done = result, err, true
+	for _, w := range t.waiters {
+		w.resolve(nil, true, 0, nil)
+	}
+	t.waiters = nil
+}
+
+// Wait blocks until the task is done and returns its outcome, it only
+// fails itself with ErrDeadlock
+func (t *Task) Wait() (Object, error) {
+	if err := t.block(); err != nil {
+		return nil, err
+	}
+	blocking.mu.Lock()
+	defer blocking.mu.Unlock()
+	t.waited = true
+	return t.result, t.err
+}
+
+// Unobserved returns the error a finished task failed with when no
+// one waited for it, errors of tasks that were waited for have been
+// seen already. A deadlock fails the tasks blocking this one, so it
+// keeps waiting for them to finish
+func (t *Task) Unobserved() error {
+	for t.block() == ErrDeadlock {
+		// the tasks blocking this one failed, they finish soon after
+	}
+	blocking.mu.Lock()
+	defer blocking.mu.Unlock()
+	if t.waited {
+		return nil
+	}
+	return t.err
+}
+
+// block waits until the task is done
+func (t *Task) block() error {
+	blocking.mu.Lock()
+	if t.done {
+		blocking.mu.Unlock()
+		return nil
+	}
+	w := newWaiter()
+	t.waiters = append(t.waiters, w)
+	w.block()
+	return w.err
+}
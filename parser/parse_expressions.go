@@ -34,6 +34,170 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return exp
 }
 
+// parseTryExpression parses `try { } catch (e) { } finally { }`, the
+// catch parameter is optional and so are both clauses, as long as one
+// of them is there
+func (p *Parser) parseTryExpression() ast.Expression {
+	exp := &ast.TryExpression{Token: p.curToken}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	exp.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			exp.Param = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		exp.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		exp.Finally = p.parseBlockStatement()
+	}
+
+	if exp.Catch == nil && exp.Finally == nil {
+		p.errorf(exp.Token.Span, "try needs a catch or a finally block")
+		return nil
+	}
+
+	return exp
+}
+
+// parseMatchExpression parses `match (value) { pattern => body, ... }`.
+// A body is a block when it starts with a brace, a single expression
+// otherwise, the comma after a block may be left out
+func (p *Parser) parseMatchExpression() ast.Expression {
+	exp := &ast.MatchExpression{Token: p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	exp.Subject = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := &ast.MatchArm{Pattern: p.parseExpression(LOWEST)}
+		if !p.checkPattern(arm.Pattern) {
+			return nil
+		}
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+
+		p.nextToken()
+		block := p.curTokenIs(token.LBRACE)
+		if block {
+			arm.Body = p.parseBlockStatement()
+		} else {
+			tok := p.curToken
+			stmt := &ast.ExpressionStatement{Token: tok, Expression: p.parseExpression(LOWEST)}
+			arm.Body = &ast.BlockStatement{Token: tok, Statements: []ast.Statement{stmt}}
+		}
+		exp.Arms = append(exp.Arms, arm)
+
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+		} else if !block && !p.peekTokenIs(token.RBRACE) {
+			p.peekError(token.COMMA)
+			return nil
+		}
+	}
+	p.nextToken()
+
+	return exp
+}
+
+// checkPattern reports an error unless pattern is made of literals,
+// identifiers, enum variants and array and hash literals holding
+// patterns, hash keys have to be literals
+func (p *Parser) checkPattern(pattern ast.Expression) bool {
+	switch pattern := pattern.(type) {
+	case nil:
+		return false
+	case *ast.Identifier, *ast.IntegerLiteral, *ast.BigIntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean:
+		return true
+	case *ast.MemberExpression:
+		if _, ok := pattern.Object.(*ast.Identifier); ok {
+			return true
+		}
+	case *ast.PrefixExpression:
+		switch pattern.Right.(type) {
+		case *ast.IntegerLiteral, *ast.BigIntegerLiteral, *ast.FloatLiteral:
+			if pattern.Operator == "-" {
+				return true
+			}
+		}
+	case *ast.ArrayLiteral:
+		for _, el := range pattern.Elements {
+			if !p.checkPattern(el) {
+				return false
+			}
+		}
+		return true
+	case *ast.HashLiteral:
+		for key, value := range pattern.Pairs {
+			switch key.(type) {
+			case *ast.Identifier, *ast.ArrayLiteral, *ast.HashLiteral:
+				p.errorf(key.Span(), "hash pattern keys must be literals, got %s", key.String())
+				return false
+			}
+			if !p.checkPattern(key) {
+				return false
+			}
+			if !p.checkPattern(value) {
+				return false
+			}
+		}
+		return true
+	}
+	p.errorf(pattern.Span(), "invalid pattern: %s", pattern.String())
+	return false
+}
+
+// parseYieldExpression parses `yield value`, the value is left out
+// when the yield ends the statement or the list it is in
+func (p *Parser) parseYieldExpression() ast.Expression {
+	exp := &ast.YieldExpression{Token: p.curToken}
+	switch p.peekToken.Type {
+	case token.SEMICOLON, token.RBRACE, token.RPAREN, token.RSQUARE, token.COMMA, token.EOF:
+		return exp
+	}
+
+	p.nextToken()
+	exp.Value = p.parseExpression(LOWEST)
+	return exp
+}
+
+// parseSpawnExpression parses `spawn call`, the call binds tighter
+// than spawn so `spawn work(1)` spawns the call to work
+func (p *Parser) parseSpawnExpression() ast.Expression {
+	exp := &ast.SpawnExpression{Token: p.curToken}
+	p.nextToken()
+	exp.Call = p.parseExpression(PREFIX)
+	return exp
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	// defer untrace(trace("parsePrefixExpression"))
 
@@ -64,7 +228,37 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// parseAssignExpression is right associative, so that a = b = 1
+// assigns 1 to both a and b
+func (p *Parser) parseAssignExpression(left ast.Expression) ast.Expression {
+	tok := p.curToken
+
+	switch left := left.(type) {
+	case *ast.Identifier:
+		expression := &ast.AssignExpression{Token: tok, Name: left, Operator: tok.Literal}
+		p.nextToken()
+		expression.Value = p.parseExpression(ASSIGN - 1)
+		return expression
+	case *ast.IndexExpression:
+		expression := &ast.IndexAssignExpression{Token: tok, Target: left, Operator: tok.Literal}
+		p.nextToken()
+		expression.Value = p.parseExpression(ASSIGN - 1)
+		return expression
+	case *ast.MemberExpression:
+		expression := &ast.FieldAssignExpression{Token: tok, Target: left, Operator: tok.Literal}
+		p.nextToken()
+		expression.Value = p.parseExpression(ASSIGN - 1)
+		return expression
+	case nil:
+		return nil
+	default:
+		p.errorf(tok.Span, "cannot assign to %s", left.String())
+		return nil
+	}
+}
+
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	defer p.allowStructLiterals()()
 	p.nextToken()
 	exp := p.parseExpression(LOWEST)
 	if !p.expectPeek(token.RPAREN) {
@@ -111,11 +305,12 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 
 func (p *Parser) parseCallExpression(fun ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: fun}
-	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	exp.Arguments = p.parseCallArguments()
 	return exp
 }
 
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	defer p.allowStructLiterals()()
 	list := []ast.Expression{}
 
 	if p.peekTokenIs(end) {
@@ -139,12 +334,85 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return list
 }
 
+// parseIndexExpression parses `a[i]`, and the slices `a[start:end]`
+// where either bound can be left out
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+	defer p.allowStructLiterals()()
+	tok := p.curToken
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		index = p.parseExpression(LOWEST)
+	}
+
+	if !p.peekTokenIs(token.COLON) {
+		if index == nil || !p.expectPeek(token.RSQUARE) {
+			return nil
+		}
+		return &ast.IndexExpression{Token: tok, Left: left, Index: index}
+	}
+
 	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: index}
+	if !p.peekTokenIs(token.RSQUARE) {
+		p.nextToken()
+		if exp.End = p.parseExpression(LOWEST); exp.End == nil {
+			return nil
+		}
+	}
 	if !p.expectPeek(token.RSQUARE) {
 		return nil
 	}
 	return exp
 }
+
+// parseRangeExpression parses `start..end`, it binds looser than
+// arithmetic so 0..n - 1 ends at n - 1
+func (p *Parser) parseRangeExpression(left ast.Expression) ast.Expression {
+	exp := &ast.RangeExpression{Token: p.curToken, Start: left}
+	precedence := p.curPrecedence()
+	p.nextToken()
+	exp.End = p.parseExpression(precedence)
+	return exp
+}
+
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.curToken, Object: left}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Member = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	return exp
+}
+
+// parseStructLiteral parses `Point{x: 1, y: 2}`, the struct is named
+// by an identifier or by a member of an imported module
+func (p *Parser) parseStructLiteral(left ast.Expression) ast.Expression {
+	lit := &ast.StructLiteral{Token: p.curToken, Struct: left}
+	switch left.(type) {
+	case *ast.Identifier, *ast.MemberExpression:
+	default:
+		p.errorf(p.curToken.Span, "cannot build a struct from %s", left.String())
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		lit.Fields = append(lit.Fields, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		lit.Values = append(lit.Values, p.parseExpression(LOWEST))
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return lit
+}
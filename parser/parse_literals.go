@@ -1,9 +1,11 @@
 package parser
 
 import (
-	"fmt"
+	"errors"
+	"math/big"
 	"strconv"
 	"zetsu/ast"
+	"zetsu/lexer"
 	"zetsu/token"
 )
 
@@ -13,9 +15,27 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 
+	if errors.Is(err, strconv.ErrRange) {
+		if value, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			return &ast.BigIntegerLiteral{Token: p.curToken, Value: value}
+		}
+	}
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorf(p.curToken.Span, "could not parse %q as integer", p.curToken.Literal)
+		return nil
+	}
+
+	lit.Value = value
+
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+
+	if err != nil {
+		p.errorf(p.curToken.Span, "could not parse %q as float", p.curToken.Literal)
 		return nil
 	}
 
@@ -28,14 +48,56 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseInterpolatedString desugars a TEMPLATE token into its literal
+// parts and expressions, each ${...} is parsed by a parser of its own
+// positioned where the expression sits in the source
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.curToken}
+
+	start := p.curToken.Span.Start
+	start.Offset++
+	start.Column++
+
+	for _, part := range lexer.SplitTemplate(p.curToken.Literal, start) {
+		if !part.Expr {
+			tok := token.Token{Type: token.STRING, Literal: part.Text, Span: p.curToken.Span}
+			str.Parts = append(str.Parts, &ast.StringLiteral{Token: tok, Value: part.Text})
+			continue
+		}
+
+		sub := New(lexer.NewAt(part.Text, p.curToken.Span.File, part.Start))
+		if sub.curTokenIs(token.EOF) {
+			p.errorf(p.curToken.Span, "empty string interpolation")
+			return nil
+		}
+		expr := sub.parseExpression(LOWEST)
+		if len(sub.errors) == 0 && !sub.peekTokenIs(token.EOF) {
+			sub.errorf(sub.peekToken.Span, "expected } after interpolated expression, got %s", sub.peekToken.Type)
+		}
+		if len(sub.errors) > 0 {
+			p.errors = append(p.errors, sub.errors...)
+			return nil
+		}
+		str.Parts = append(str.Parts, expr)
+	}
+
+	return str
+}
+
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.curToken}
+	if p.peekTokenIs(token.ASTERISK) {
+		p.nextToken()
+		lit.Generator = true
+	}
 
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionSignature(lit) {
+		return nil
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -46,6 +108,58 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
+// parseFunctionSignature parses the parameters of a function literal,
+// `(a, b = 10, ...rest)`. Once a parameter has a default value every
+// parameter after it needs one too, and ...rest has to come last
+func (p *Parser) parseFunctionSignature(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []*ast.Identifier{}
+	hasDefaults, missingDefault := false, false
+
+	for !p.peekTokenIs(token.RPAREN) {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+			lit.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			break
+		}
+
+		if !p.expectPeek(token.IDENT) {
+			return false
+		}
+		param := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		var def ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			if def = p.parseExpression(LOWEST); def == nil {
+				return false
+			}
+			hasDefaults = true
+		} else if hasDefaults && !missingDefault {
+			// the signature is still well formed, so keep parsing the
+			// literal to report this once instead of erroring on the body
+			p.errorf(param.Span(), "parameter %s needs a default value, it follows a parameter that has one", param.Value)
+			missingDefault = true
+		}
+
+		lit.Parameters = append(lit.Parameters, param)
+		lit.Defaults = append(lit.Defaults, def)
+
+		if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+			return false
+		}
+	}
+
+	if !hasDefaults {
+		lit.Defaults = nil
+	}
+
+	return p.expectPeek(token.RPAREN)
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 	array.Elements = p.parseExpressionList(token.RSQUARE)
@@ -53,6 +167,7 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 }
 
 func (p *Parser) parseHashLiteral() ast.Expression {
+	defer p.allowStructLiterals()()
 	hash := &ast.HashLiteral{Token: p.curToken}
 	hash.Pairs = make(map[ast.Expression]ast.Expression)
 
@@ -2,7 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"unicode/utf8"
 	"zetsu/ast"
+	"zetsu/errrs"
 	"zetsu/lexer"
 	"zetsu/token"
 )
@@ -10,8 +12,12 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN
+	LOGICAL_OR
+	LOGICAL_AND
 	EQUALS
 	LESSGREATER
+	RANGE
 	SUM
 	PRODUCT
 	PREFIX
@@ -20,16 +26,30 @@ const (
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQUALITY:   EQUALS,
-	token.INEQUALITY: EQUALS,
-	token.LT:         LESSGREATER,
-	token.GT:         LESSGREATER,
-	token.PLUS:       SUM,
-	token.MINUS:      SUM,
-	token.FSLASH:     PRODUCT,
-	token.ASTERISK:   PRODUCT,
-	token.LPAREN:     CALL,
-	token.LSQUARE:    INDEX,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.FSLASH_ASSIGN:   ASSIGN,
+	token.PERCENT_ASSIGN:  ASSIGN,
+	token.OR:              LOGICAL_OR,
+	token.AND:             LOGICAL_AND,
+	token.EQUALITY:        EQUALS,
+	token.INEQUALITY:      EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LTE:             LESSGREATER,
+	token.GTE:             LESSGREATER,
+	token.DOTDOT:          RANGE,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.FSLASH:          PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.LPAREN:          CALL,
+	token.LBRACE:          CALL,
+	token.LSQUARE:         INDEX,
+	token.DOT:             INDEX,
 }
 
 type (
@@ -41,25 +61,35 @@ type Parser struct {
 	l              *lexer.Lexer
 	curToken       token.Token
 	peekToken      token.Token
-	errors         []string
+	errors         []*errrs.Diagnostic
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
+	// noStructLiteral is set while parsing an expression that a block
+	// follows, where `xs {` starts the block rather than a struct
+	noStructLiteral bool
 }
 
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l, errors: []string{}}
+	p := &Parser{l: l, errors: []*errrs.Diagnostic{}}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.YIELD, p.parseYieldExpression)
+	p.registerPrefix(token.SPAWN, p.parseSpawnExpression)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.TEMPLATE, p.parseInterpolatedString)
 	p.registerPrefix(token.LSQUARE, p.parseArrayLiteral)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -71,8 +101,22 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.INEQUALITY, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LTE, p.parseInfixExpression)
+	p.registerInfix(token.GTE, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.FSLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PERCENT_ASSIGN, p.parseAssignExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.DOTDOT, p.parseRangeExpression)
 	p.registerInfix(token.LSQUARE, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+	p.registerInfix(token.LBRACE, p.parseStructLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
 
@@ -101,12 +145,38 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
-func (p *Parser) Errors() []string { return p.errors }
+func (p *Parser) Errors() []string {
+	msgs := make([]string, len(p.errors))
+	for i, d := range p.errors {
+		msgs[i] = d.Msg
+	}
+	return msgs
+}
+
+// Diagnostics returns the same errors as Errors, along with the
+// source span each of them refers to
+func (p *Parser) Diagnostics() []*errrs.Diagnostic { return p.errors }
+
+func (p *Parser) errorf(span token.Span, format string, a ...interface{}) {
+	p.errors = append(p.errors, errrs.Errorf(span, format, a...))
+}
 
 func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseIllegal reports an ILLEGAL token. The lexer describes
+// malformed constructs such as unterminated strings in the literal,
+// a single character literal is a character it did not recognise
+func (p *Parser) parseIllegal() ast.Expression {
+	msg := p.curToken.Literal
+	if utf8.RuneCountInString(msg) == 1 {
+		msg = fmt.Sprintf("illegal character %q", msg)
+	}
+	p.errorf(p.curToken.Span, "%s", msg)
+	return nil
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
@@ -139,21 +209,43 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	return identifiers
 }
 
+// parseCallArguments parses the arguments of a call, arguments written
+// as `name: value` are passed by parameter name and have to come after
+// the positional ones
 func (p *Parser) parseCallArguments() []ast.Expression {
+	defer p.allowStructLiterals()()
 	args := []ast.Expression{}
+	named := map[string]bool{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
 		return args
 	}
 
-	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
-
-	for p.peekTokenIs(token.COMMA) {
+	for {
 		p.nextToken()
+		arg := p.parseCallArgument()
+		switch arg := arg.(type) {
+		case nil:
+			return nil
+		case *ast.NamedArgument:
+			if named[arg.Name.Value] {
+				p.errorf(arg.Name.Span(), "duplicate argument %s", arg.Name.Value)
+				return nil
+			}
+			named[arg.Name.Value] = true
+		default:
+			if len(named) > 0 {
+				p.errorf(arg.Span(), "positional argument %s follows a named argument", arg.String())
+				return nil
+			}
+		}
+		args = append(args, arg)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
 	}
 
 	if !p.expectPeek(token.RPAREN) {
@@ -163,6 +255,22 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 	return args
 }
 
+func (p *Parser) parseCallArgument() ast.Expression {
+	if !p.curTokenIs(token.IDENT) || !p.peekTokenIs(token.COLON) {
+		return p.parseExpression(LOWEST)
+	}
+
+	arg := &ast.NamedArgument{Token: p.curToken}
+	arg.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.nextToken()
+	p.nextToken()
+	if arg.Value = p.parseExpression(LOWEST); arg.Value == nil {
+		return nil
+	}
+
+	return arg
+}
+
 func (p *Parser) expectPeek(tokenType token.TokenType) bool {
 	if p.peekTokenIs(tokenType) {
 		p.nextToken()
@@ -175,11 +283,22 @@ func (p *Parser) expectPeek(tokenType token.TokenType) bool {
 func (p *Parser) peekTokenIs(tokenType token.TokenType) bool { return p.peekToken.Type == tokenType }
 func (p *Parser) curTokenIs(tokenType token.TokenType) bool  { return p.curToken.Type == tokenType }
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, but got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.errorf(p.peekToken.Span, "expected next token to be %s, but got %s instead", t, p.peekToken.Type)
+}
+
+// allowStructLiterals clears noStructLiteral until the returned func
+// puts it back, inside brackets a `{` can't start the block that
+// follows
+func (p *Parser) allowStructLiterals() func() {
+	saved := p.noStructLiteral
+	p.noStructLiteral = false
+	return func() { p.noStructLiteral = saved }
 }
 
 func (p *Parser) peekPrecedence() int {
+	if p.noStructLiteral && p.peekTokenIs(token.LBRACE) {
+		return LOWEST
+	}
 	if prec, ok := precedences[p.peekToken.Type]; ok {
 		return prec
 	}
@@ -202,6 +321,5 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 }
 
 func (p *Parser) notPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.errorf(p.curToken.Span, "no prefix parse function for %s found", t)
 }
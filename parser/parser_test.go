@@ -42,6 +42,64 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 5;", "const x = 5;"},
+		{"const greeting = \"hi\" + name;", "const greeting = (hi + name);"},
+		{"export const limit = 10;", "export const limit = 10;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program. want=%q, got=%q", tt.expected, program.String())
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if exp, isExport := program.Statements[0].(*ast.ExportStatement); isExport {
+			stmt, ok = exp.Statement, true
+		}
+		if !ok || !stmt.IsConst() {
+			t.Errorf("%q is not a const statement. got=%T", tt.input, program.Statements[0])
+		}
+	}
+}
+
+func TestEnumStatements(t *testing.T) {
+	input := "enum Status { Pending, Running, Done };"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.EnumStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.EnumStatement. got=%T", program.Statements[0])
+	}
+	if !testIdentifier(t, stmt.Name, "Status") {
+		return
+	}
+	for i, name := range []string{"Pending", "Running", "Done"} {
+		if !testIdentifier(t, stmt.Variants[i], name) {
+			return
+		}
+	}
+	if stmt.String() != "enum Status { Pending, Running, Done }" {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	input :=
 		`
@@ -112,6 +170,31 @@ func TestIdentifierExpression(t *testing.T) {
 	}
 }
 
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"99999999999999999999;", "99999999999999999999"},
+		{"9223372036854775808;", "9223372036854775808"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.BigIntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.BigIntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value.String() != tt.expected {
+			t.Errorf("literal.Value not %s. got=%s", tt.expected, literal.Value)
+		}
+	}
+}
+
 func TestIntegerLiteralExpression(t *testing.T) {
 	input := "5;"
 	l := lexer.New(input)
@@ -146,6 +229,35 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestFloatLiteralExpression(t *testing.T) {
+	input := "2.5e1;"
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. Got = %d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	literal, ok := stmt.Expression.(*ast.FloatLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != 25 {
+		t.Errorf("literal.Value not %f. got=%f", 25.0, literal.Value)
+	}
+	if literal.TokenLiteral() != "2.5e1" {
+		t.Errorf("literal.TokenLiteral not %s. got=%s", "2.5e1", literal.TokenLiteral())
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -268,6 +380,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
 		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1, 2, 3, 4][(b * c)])) * d)"},
 		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))"},
+		{"a % b * c", "((a % b) * c)"},
+		{"a + b % c", "(a + (b % c))"},
+		{"a <= b == b >= a", "((a <= b) == (b >= a))"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a && b || c", "((a && b) || c)"},
+		{"a == b && c != d", "((a == b) && (c != d))"},
+		{"!a || b < c", "((!a) || (b < c))"},
+		{"x = a || b", "x = (a || b)"},
 	}
 
 	for _, tt := range tests {
@@ -379,6 +499,354 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < y) { x; break; continue; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(stmt.Body.Statements) != 3 {
+		t.Fatalf("body is not 3 statements. got=%d\n", len(stmt.Body.Statements))
+	}
+	if _, ok := stmt.Body.Statements[1].(*ast.BreakStatement); !ok {
+		t.Errorf("Statements[1] is not ast.BreakStatement. got=%T", stmt.Body.Statements[1])
+	}
+	if _, ok := stmt.Body.Statements[2].(*ast.ContinueStatement); !ok {
+		t.Errorf("Statements[2] is not ast.ContinueStatement. got=%T", stmt.Body.Statements[2])
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`try { x } catch (e) { e }`, "try x catch (e) e"},
+		{`try { x } catch { y }`, "try x catch y"},
+		{`try { x } finally { y }`, "try x finally y"},
+		{`let a = try { x } catch (e) { y } finally { z };`, "let a = try x catch (e) y finally z;"},
+		{`throw "boom";`, `throw boom;`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	program := New(lexer.New(`try { 1 } catch (err) { throw err; }`)).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.TryExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.TryExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifier(t, exp.Param, "err") {
+		return
+	}
+	throw, ok := exp.Catch.Statements[0].(*ast.ThrowStatement)
+	if !ok {
+		t.Fatalf("exp.Catch.Statements[0] is not ast.ThrowStatement. got=%T", exp.Catch.Statements[0])
+	}
+	testIdentifier(t, throw.Value, "err")
+}
+
+func TestTryExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`try { 1 }`, "try needs a catch or a finally block"},
+		{`try { 1 } catch (1) { 2 }`, "expected next token to be IDENT, but got INT instead"},
+		{`try 1 catch { 2 }`, "expected next token to be {, but got INT instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestStructs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`struct Point { x, y }`, "struct Point { x, y }"},
+		{`struct Empty {};`, "struct Empty {  }"},
+		{`let p = Point{x: 1, y: 2 + 3};`, "let p = Point{x: 1, y: (2 + 3)};"},
+		{`geo.Point{x: 1}`, "geo.Point{x: 1}"},
+		{`p.x = p.y * 2`, "p.x = (p.y * 2)"},
+		{`p.x += 1`, "p.x += 1"},
+		{`a.b.c`, "a.b.c"},
+		{`for p in points { p.x }`, "for p in points p.x"},
+		{`for p in [P{x: 1}] { p.x }`, "for p in [P{x: 1}] p.x"},
+		{`for p in (P{x: 1}).items { p }`, "for p in P{x: 1}.items p"},
+		{`for p in f(P{x: 1}, {"k": P{x: 2}}) { p }`, "for p in f(P{x: 1}, {k:P{x: 2}}) p"},
+		{`for p in ps[P{x: 1}.x] { p }`, "for p in (ps[P{x: 1}.x]) p"},
+		{`for p in fn() { P{x: 1} }() { p }`, "for p in fn() P{x: 1}() p"},
+		{`if (x) { y }`, "ifx y"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	program := New(lexer.New(`Point{x: 1, y: 2}`)).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	lit, ok := stmt.Expression.(*ast.StructLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.StructLiteral. got=%T", stmt.Expression)
+	}
+	if !testIdentifier(t, lit.Struct, "Point") {
+		return
+	}
+	if len(lit.Fields) != 2 || lit.Fields[0].Value != "x" || lit.Fields[1].Value != "y" {
+		t.Fatalf("wrong fields. got=%v", lit.Fields)
+	}
+	testIntegerLiteral(t, lit.Values[1], 2)
+}
+
+func TestStructErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`struct { x }`, "expected next token to be IDENT, but got { instead"},
+		{`struct P { x y }`, "expected next token to be ,, but got IDENT instead"},
+		{`P{1: 2}`, "expected next token to be IDENT, but got INT instead"},
+		{`f(){x: 1}`, "cannot build a struct from f()"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match (x) { 1 => "one", _ => "many" }`, `match (x) { 1 => one, _ => many }`},
+		{`match (x) { -1 => a, 2.5 => b, true => c, }`, `match (x) { (-1) => a, 2.5 => b, true => c }`},
+		{`match (x) { [a, [b, _]] => a + b, y => y }`, `match (x) { [a, [b, _]] => (a + b), y => y }`},
+		{`match (x) { {"k": v} => { let w = v; w } _ => 0 }`, `match (x) { {k:v} => let w = v;w, _ => 0 }`},
+		{`let y = match (f(x)) { _ => 1 };`, `let y = match (f(x)) { _ => 1 };`},
+		{`match (x) { 99999999999999999999 => a, -99999999999999999999 => b }`, `match (x) { 99999999999999999999 => a, (-99999999999999999999) => b }`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	program := New(lexer.New(`match (x) { [a, 1] => a }`)).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifier(t, exp.Subject, "x") {
+		return
+	}
+	if len(exp.Arms) != 1 || exp.Arms[0].IsCatchAll() {
+		t.Fatalf("wrong arms. got=%v", exp.Arms)
+	}
+	if _, ok := exp.Arms[0].Pattern.(*ast.ArrayLiteral); !ok {
+		t.Errorf("pattern is not ast.ArrayLiteral. got=%T", exp.Arms[0].Pattern)
+	}
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match x { _ => 1 }`, "expected next token to be (, but got IDENT instead"},
+		{`match (x) { 1 + 2 => 1 }`, "invalid pattern: (1 + 2)"},
+		{`match (x) { f(y) => 1 }`, "invalid pattern: f(y)"},
+		{`match (x) { a.b.c => 1 }`, "invalid pattern: a.b.c"},
+		{`match (x) { {k: v} => 1 }`, "hash pattern keys must be literals, got k"},
+		{`match (x) { 1 => 1 2 => 2 }`, "expected next token to be ,, but got INT instead"},
+		{`match (x) { 1, 2 }`, "expected next token to be =>, but got , instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (let i = 0; i < 10; i = i + 1) { i }", "for (let i = 0; (i < 10); i = (i + 1)) i"},
+		{"for (let i = 0; i < 10; i + 1) { i }", "for (let i = 0; (i < 10); (i + 1)) i"},
+		{"for (;;) { }", "for (; ; ) "},
+		{"for (i = 0; ; ) { }", "for (i = 0 ; ) "},
+		{"for x in [1, 2] { x }", "for x in [1, 2] x"},
+		{"for c in \"abc\" { c }; 1", "for c in abc c1"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestAssignExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 5;", "x = 5"},
+		{"x = y = 1 + 2;", "x = y = (1 + 2)"},
+		{"x = y == z;", "x = (y == z)"},
+		{"x += 1 * 2;", "x += (1 * 2)"},
+		{"x -= 1; x *= 2; x /= 3", "x -= 1x *= 2x /= 3"},
+		{"x = y += 1", "x = y += 1"},
+		{"a[0] = 1", "(a[0]) = 1"},
+		{`h["k"] += a[1] = 2`, "(h[k]) += (a[1]) = 2"},
+		{"f()[i + 1] = 3", "(f()[(i + 1)]) = 3"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	p := New(lexer.New("1 = 2;"))
+	p.ParseProgram()
+	if errs := p.Errors(); len(errs) == 0 || errs[0] != "cannot assign to 1" {
+		t.Errorf("wrong errors for assignment to a literal. got=%q", errs)
+	}
+}
+
+func TestGeneratorParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn*(x) { yield x; }", "fn*(x) (yield x)"},
+		{"let g = fn*() { let v = yield; yield v + 1 }", "let g = fn*<g>() let v = (yield);(yield (v + 1));"},
+		{"[yield, yield 2]", "[(yield), (yield 2)]"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program. want=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("fn*() { 1 }")).ParseProgram()
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if !fn.Generator {
+		t.Errorf("fn* literal is not a generator")
+	}
+}
+
+func TestSpawnParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"spawn work(1, 2)", "(spawn work(1, 2))"},
+		{"spawn fn() { x }", "(spawn fn() x)"},
+		{"let t = spawn f(a)[0]", "let t = (spawn (f(a)[0]));"},
+		{"wait(spawn f()) + 1", "(wait((spawn f())) + 1)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program. want=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `fn(x, y) { x + y; }`
 
@@ -456,6 +924,103 @@ func TestFunctionParameterParsing(t *testing.T) {
 	}
 }
 
+func TestFunctionSignatureParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a, b = 10) { a }", "fn(a, b = 10) a"},
+		{"fn(a = 1, b = a + 1) { b }", "fn(a = 1, b = (a + 1)) b"},
+		{"fn(a, ...rest) { rest }", "fn(a, ...rest) rest"},
+		{"fn(...rest) { rest }", "fn(...rest) rest"},
+		{"f(1, b: 2, c: 3)", "f(1, b: 2, c: 3)"},
+		{"f(b: fn(x = 1) { x })", "f(b: fn(x = 1) x)"},
+		{"f({a: 1}, b)", "f({a:1}, b)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program for %q. want=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestFunctionSignatureErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a = 1, b) { b }", "parameter b needs a default value, it follows a parameter that has one"},
+		{"fn(...rest, a) { a }", "expected next token to be ), but got , instead"},
+		{"fn(1) { 1 }", "expected next token to be IDENT, but got INT instead"},
+		{"f(a: 1, 2)", "positional argument 2 follows a named argument"},
+		{"f(a: 1, a: 2)", "duplicate argument a"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestMissingDefaultReportedOnce(t *testing.T) {
+	tests := []string{
+		"fn(a = 1, b) {}",
+		"let f = fn(a = 1, b, c) { a + b + c }; f(1)",
+		"let f = fn*(a = 1, b) { yield a + b };",
+	}
+
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		if errors := p.Errors(); len(errors) != 1 {
+			t.Errorf("expected 1 parser error for %q, got %d: %q", input, len(errors), errors)
+		}
+	}
+}
+
+func TestSliceAndRangeExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:3]", "(a[1:3])"},
+		{"a[:n]", "(a[:n])"},
+		{"a[n:]", "(a[n:])"},
+		{"a[:]", "(a[:])"},
+		{"a[-2:len(a) - 1]", "(a[(-2):(len(a) - 1)])"},
+		{"a[1:][0]", "((a[1:])[0])"},
+		{"1..10", "(1..10)"},
+		{"0..n - 1", "(0..(n - 1))"},
+		{"1..10 == r", "((1..10) == r)"},
+		{"(1..10)[2:]", "((1..10)[2:])"},
+		{"for x in 1..n { x }", "for x in (1..n) x"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program for %q. want=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 
@@ -567,6 +1132,65 @@ func TestLiteralStringExpression(t *testing.T) {
 	}
 }
 
+func TestInterpolatedStringExpression(t *testing.T) {
+	input := `"total: ${count * price}!";`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	str, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+	}
+	if len(str.Parts) != 3 {
+		t.Fatalf("str.Parts does not contain 3 parts. got=%d", len(str.Parts))
+	}
+
+	if lit, ok := str.Parts[0].(*ast.StringLiteral); !ok || lit.Value != "total: " {
+		t.Errorf("str.Parts[0] not %q. got=%s", "total: ", str.Parts[0])
+	}
+	testInfixExpression(t, str.Parts[1], "count", "*", "price")
+	if lit, ok := str.Parts[2].(*ast.StringLiteral); !ok || lit.Value != "!" {
+		t.Errorf("str.Parts[2] not %q. got=%s", "!", str.Parts[2])
+	}
+
+	span := str.Parts[1].Span()
+	if got := input[span.Start.Offset:span.End.Offset]; got != "count * price" {
+		t.Errorf("interpolation span covers wrong source. want=%q, got=%q", "count * price", got)
+	}
+}
+
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		column   int
+	}{
+		{`"empty ${}"`, "empty string interpolation", 1},
+		{`"two ${a b}"`, "expected } after interpolated expression, got IDENT", 10},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		diags := p.Diagnostics()
+		if len(diags) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if diags[0].Msg != tt.expected {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expected, diags[0].Msg)
+		}
+		if diags[0].Span.Start.Column != tt.column {
+			t.Errorf("wrong error column for %q. want=%d, got=%d", tt.input, tt.column, diags[0].Span.Start.Column)
+		}
+	}
+}
+
 func TestParsingArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 	l := lexer.New(input)
@@ -766,3 +1390,151 @@ func TestFunctionLiteralWithName(t *testing.T) {
 			function.Name)
 	}
 }
+
+func TestImportAndExportStatements(t *testing.T) {
+	input := `import "lib/strings.zeta" as s;
+export let twice = fn(x) { s.wrap(x) };`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	imp, ok := program.Statements[0].(*ast.ImportStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.ImportStatement. got=%T", program.Statements[0])
+	}
+	if imp.Path != "lib/strings.zeta" {
+		t.Errorf("imp.Path not %q. got=%q", "lib/strings.zeta", imp.Path)
+	}
+	if !testIdentifier(t, imp.Alias, "s") {
+		return
+	}
+
+	exp, ok := program.Statements[1].(*ast.ExportStatement)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not *ast.ExportStatement. got=%T", program.Statements[1])
+	}
+	if !testLetStmt(t, exp.Statement, "twice") {
+		return
+	}
+
+	fn := exp.Statement.Value.(*ast.FunctionLiteral)
+	call := fn.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	member, ok := call.Function.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("call.Function is not *ast.MemberExpression. got=%T", call.Function)
+	}
+	testIdentifier(t, member.Object, "s")
+	testIdentifier(t, member.Member, "wrap")
+}
+
+func TestModuleStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import strings as s;`, "expected next token to be STRING, but got IDENT instead"},
+		{`import "a.zeta" s;`, "expected next token to be AS, but got IDENT instead"},
+		{`export fn() {};`, "expected next token to be LET, but got FUNCTION instead"},
+		{`s.1`, "expected next token to be IDENT, but got INT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"let x 5;", 1, 7},
+		{"let a = 1;\nlet = 2;", 2, 5},
+		{"add(1,\n  2;", 2, 4},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		diags := p.Diagnostics()
+		if len(diags) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+
+		start := diags[0].Span.Start
+		if start.Line != tt.line || start.Column != tt.column {
+			t.Errorf("wrong error position for %q. want=%d:%d, got=%s", tt.input, tt.line, tt.column, start)
+		}
+	}
+}
+
+func TestIllegalTokenErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		line     int
+		column   int
+	}{
+		{`let s = "never closed;`, "unterminated string", 1, 9},
+		{"let s = 1;\nlet r = `raw\n", "unterminated raw string", 2, 9},
+		{`let s = "bad \q escape";`, "invalid escape sequence \\q", 1, 9},
+		{`"\u{110000}"`, "invalid unicode code point U+110000", 1, 1},
+		{"let x = #;", "illegal character \"#\"", 1, 9},
+		{"/* open", "unterminated block comment", 1, 1},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		diags := p.Diagnostics()
+		if len(diags) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+
+		if diags[0].Msg != tt.expected {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expected, diags[0].Msg)
+		}
+
+		start := diags[0].Span.Start
+		if start.Line != tt.line || start.Column != tt.column {
+			t.Errorf("wrong error position for %q. want=%d:%d, got=%s", tt.input, tt.line, tt.column, start)
+		}
+	}
+}
+
+func TestNodeSpans(t *testing.T) {
+	input := "let total = price * count;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.LetStatement)
+	span := stmt.Value.Span()
+
+	if got := input[span.Start.Offset:span.End.Offset]; got != "price * count" {
+		t.Errorf("infix span covers wrong source. want=%q, got=%q", "price * count", got)
+	}
+}
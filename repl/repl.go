@@ -11,11 +11,13 @@ import (
 	"zetsu/builtin"
 	"zetsu/compiler"
 	"zetsu/errrs"
+	"zetsu/evaluator"
 	"zetsu/global"
 	"zetsu/lexer"
 	"zetsu/mutil"
 	"zetsu/object"
 	"zetsu/parser"
+	"zetsu/resolver"
 	"zetsu/vm"
 )
 
@@ -41,8 +43,7 @@ const PROMPT = ">> "
 func Start(in io.Reader, out io.Writer) {
 	welcome()
 	scanner := bufio.NewScanner(in)
-	// env := object.NewEnvironment()
-	// macroEnv := object.NewEnvironment()
+	macroEnv := object.NewEnvironment()
 
 	constants := []object.Object{}
 	globals := make([]object.Object, global.GlobalSize)
@@ -63,27 +64,48 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 
+		source := errrs.Source{Name: "repl", Text: line}
 		l := lexer.New(line)
 		p := parser.New(l)
 		program := p.ParseProgram()
 
 		if len(p.Errors()) != 0 {
-			errrs.PrintParseErrors(out, p.Errors())
+			errrs.PrintParseErrors(out, source, p.Diagnostics())
+			continue
+		}
+
+		evaluator.DefineMacros(program, macroEnv)
+		if len(program.Statements) == 0 {
+			// the line only defined macros
+			continue
+		}
+		if _, err := evaluator.ExpandMacros(program, macroEnv); err != nil {
+			errrs.PrintMacroError(out, source, err)
+			continue
+		}
+
+		// imports typed into the REPL are relative to the working directory
+		modules, err, errtype, diags := resolver.Resolve(program, "")
+		if err != nil {
+			printResolveError(out, source, err, errtype, diags)
 			continue
 		}
 
 		comp := compiler.NewWithState(symbolTable, constants)
-		if err := comp.Compile(program); err != nil {
-			errrs.PrintCompilerError(out, err.Error())
+		if err := compileModules(comp, modules); err != nil {
+			errrs.PrintCompilerError(out, source, err)
 			continue
 		}
+		if warnings := comp.Warnings(); len(warnings) > 0 {
+			errrs.PrintWarnings(out, source, warnings)
+		}
 
 		byteCode := comp.ByteCode()
 		byteCode = mutil.EncryptByteCode(byteCode)
 
 		machine := vm.NewWithGlobalStore(byteCode, globals)
 		if err := machine.Run(); err != nil {
-			errrs.PrintMachineError(out, err.Error())
+			errrs.PrintMachineError(out, source, err)
 			continue
 		}
 
@@ -93,6 +115,26 @@ func Start(in io.Reader, out io.Writer) {
 	}
 }
 
+func compileModules(comp *compiler.Compiler, modules []*compiler.Module) error {
+	for _, m := range modules {
+		if err := comp.CompileModule(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printResolveError(out io.Writer, src errrs.Source, err error, errtype errrs.ErrorType, diags []*errrs.Diagnostic) {
+	switch errtype {
+	case errrs.PARSER_ERROR:
+		errrs.PrintParseErrors(out, src, diags)
+	case errrs.MACRO_ERROR:
+		errrs.PrintMacroError(out, src, err)
+	default:
+		errrs.PrintImportError(out, src, err)
+	}
+}
+
 func welcome() {
 	fmt.Print(banner)
 
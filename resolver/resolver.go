@@ -0,0 +1,120 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"zetsu/ast"
+	"zetsu/compiler"
+	"zetsu/errrs"
+	"zetsu/evaluator"
+	"zetsu/lexer"
+	"zetsu/object"
+	"zetsu/parser"
+)
+
+type resolver struct {
+	modules  []*compiler.Module
+	resolved map[string]bool
+	visiting []string // modules whose imports are being resolved
+
+	err     error
+	errtype errrs.ErrorType
+	diags   []*errrs.Diagnostic
+}
+
+// Resolve parses, and expands the macros of, every module program
+// imports, directly or through other modules. The modules come back in
+// the order they have to be compiled in, dependencies first, with
+// program itself last and an empty Path. Import paths are relative to
+// the file holding the import, srcpath is the file program was read
+// from, it is empty for code typed into the REPL
+func Resolve(program *ast.Program, srcpath string) ([]*compiler.Module, error, errrs.ErrorType, []*errrs.Diagnostic) {
+	r := &resolver{resolved: map[string]bool{}}
+	if srcpath != "" {
+		r.visiting = []string{filepath.Clean(srcpath)}
+	}
+
+	imports, ok := r.resolveImports(program, filepath.Dir(srcpath))
+	if !ok {
+		return nil, r.err, r.errtype, r.diags
+	}
+
+	main := &compiler.Module{Program: program, Imports: imports}
+	return append(r.modules, main), nil, "", nil
+}
+
+// resolveImports loads the modules imported by the top level of
+// program, nested imports are rejected by the compiler
+func (r *resolver) resolveImports(program *ast.Program, dir string) (map[string]string, bool) {
+	imports := map[string]string{}
+
+	for _, stmt := range program.Statements {
+		imp, ok := stmt.(*ast.ImportStatement)
+		if !ok {
+			continue
+		}
+
+		path := imp.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		path = filepath.Clean(path)
+		imports[imp.Path] = path
+
+		if !r.load(path, imp) {
+			return nil, false
+		}
+	}
+
+	return imports, true
+}
+
+func (r *resolver) load(path string, imp *ast.ImportStatement) bool {
+	if r.resolved[path] {
+		return true
+	}
+
+	for i, visiting := range r.visiting {
+		if visiting == path {
+			cycle := append(append([]string{}, r.visiting[i:]...), path)
+			return r.fail(errrs.Errorf(imp.Span(), "import cycle: %s", strings.Join(cycle, " -> ")))
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r.fail(errrs.Errorf(imp.Span(), "cannot import %q: %s", imp.Path, err))
+	}
+
+	p := parser.New(lexer.NewFile(string(data), path))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		r.err, r.errtype, r.diags = fmt.Errorf("parser error"), errrs.PARSER_ERROR, p.Diagnostics()
+		return false
+	}
+
+	macroEnv := object.NewEnvironment()
+	evaluator.DefineMacros(program, macroEnv)
+	if _, err := evaluator.ExpandMacros(program, macroEnv); err != nil {
+		r.err, r.errtype = err, errrs.MACRO_ERROR
+		return false
+	}
+
+	r.visiting = append(r.visiting, path)
+	imports, ok := r.resolveImports(program, filepath.Dir(path))
+	r.visiting = r.visiting[:len(r.visiting)-1]
+	if !ok {
+		return false
+	}
+
+	r.modules = append(r.modules, &compiler.Module{Path: path, Program: program, Imports: imports})
+	r.resolved[path] = true
+	return true
+}
+
+func (r *resolver) fail(err error) bool {
+	r.err, r.errtype = err, errrs.IMPORT_ERROR
+	return false
+}
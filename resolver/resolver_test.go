@@ -0,0 +1,128 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"zetsu/ast"
+	"zetsu/errrs"
+	"zetsu/lexer"
+	"zetsu/parser"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	return program
+}
+
+func TestResolveOrder(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"lib/a.zeta": `import "b.zeta" as b; import "../c.zeta" as c; export let a = 1;`,
+		"lib/b.zeta": `import "../c.zeta" as c; export let b = 2;`,
+		"c.zeta":     `export let c = 3;`,
+	})
+
+	program := parse(t, `import "lib/a.zeta" as a; import "c.zeta" as c;`)
+	modules, err, _, _ := Resolve(program, filepath.Join(dir, "main.zeta"))
+	if err != nil {
+		t.Fatalf("resolve error: %s", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "c.zeta"),
+		filepath.Join(dir, "lib", "b.zeta"),
+		filepath.Join(dir, "lib", "a.zeta"),
+		"",
+	}
+	if len(modules) != len(expected) {
+		t.Fatalf("wrong number of modules. want=%d, got=%d", len(expected), len(modules))
+	}
+	for i, path := range expected {
+		if modules[i].Path != path {
+			t.Errorf("modules[%d] wrong. want=%q, got=%q", i, path, modules[i].Path)
+		}
+	}
+
+	main := modules[len(modules)-1]
+	if main.Program != program {
+		t.Errorf("main module does not hold the program")
+	}
+	if main.Imports["lib/a.zeta"] != expected[2] {
+		t.Errorf("import of lib/a.zeta resolved to %q", main.Imports["lib/a.zeta"])
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"cycle/a.zeta": `import "b.zeta" as b;`,
+		"cycle/b.zeta": `import "a.zeta" as a;`,
+		"main.zeta":    `import "back.zeta" as b;`,
+		"back.zeta":    `import "main.zeta" as m;`,
+		"broken.zeta":  `let x = ;`,
+		"macro.zeta":   `let m = macro() { 1 }; m();`,
+	})
+
+	tests := []struct {
+		input    string
+		errtype  errrs.ErrorType
+		expected string
+	}{
+		{`import "missing.zeta" as m;`, errrs.IMPORT_ERROR, `cannot import "missing.zeta"`},
+		{`import "cycle/a.zeta" as a;`, errrs.IMPORT_ERROR, "import cycle: " +
+			filepath.Join(dir, "cycle", "a.zeta") + " -> " + filepath.Join(dir, "cycle", "b.zeta") + " -> " + filepath.Join(dir, "cycle", "a.zeta")},
+		{`import "back.zeta" as b;`, errrs.IMPORT_ERROR, "import cycle: " +
+			filepath.Join(dir, "main.zeta") + " -> " + filepath.Join(dir, "back.zeta") + " -> " + filepath.Join(dir, "main.zeta")},
+		{`import "broken.zeta" as b;`, errrs.PARSER_ERROR, "parser error"},
+		{`import "macro.zeta" as m;`, errrs.MACRO_ERROR, "macro m must return a quote, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		_, err, errtype, _ := Resolve(parse(t, tt.input), filepath.Join(dir, "main.zeta"))
+		if err == nil {
+			t.Fatalf("expected an error for %q, got none", tt.input)
+		}
+		if errtype != tt.errtype {
+			t.Errorf("wrong error type for %q. want=%q, got=%q", tt.input, tt.errtype, errtype)
+		}
+		if !strings.HasPrefix(err.Error(), tt.expected) {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestResolveSpansNameFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"broken.zeta": "let ok = 1;\nlet x = ;",
+	})
+
+	_, _, _, diags := Resolve(parse(t, `import "broken.zeta" as b;`), filepath.Join(dir, "main.zeta"))
+	if len(diags) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	span := diags[0].Span
+	if span.File != filepath.Join(dir, "broken.zeta") || span.Start.Line != 2 {
+		t.Errorf("wrong error location. got=%s:%s", span.File, span.Start)
+	}
+}
@@ -76,6 +76,7 @@ func runvm(bytecode *compiler.ByteCode) (error, errrs.ErrorType) {
 
 func registerTypes() {
 	gob.Register(&object.Integer{})
+	gob.Register(&object.Float{})
 	gob.Register(&object.Boolean{})
 	gob.Register(&object.Null{})
 	gob.Register(&object.ReturnValue{})
@@ -90,4 +91,10 @@ func registerTypes() {
 	gob.Register(&object.CompiledFunction{})
 	gob.Register(&object.Closure{})
 	gob.Register(&object.Encrypted{})
+	gob.Register(&object.StructType{})
+	gob.Register(&object.Struct{})
+	gob.Register(&object.Range{})
+	gob.Register(&object.BigInteger{})
+	gob.Register(&object.EnumType{})
+	gob.Register(&object.EnumValue{})
 }
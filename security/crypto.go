@@ -11,6 +11,7 @@ import (
 	"io"
 	mathRand "math/rand"
 	"strings"
+	"sync"
 )
 
 func AESEncrypt(data []byte) (string, error) {
@@ -96,9 +97,17 @@ func XOROne(instruction byte, length int) byte {
 	return instruction ^ key
 }
 
+// keys caches the byte derived from each seed, seeding a new source
+// is far too slow to be done for every instruction the vm decodes
+var keys sync.Map
+
 func randByte(seed int64) byte {
+	if key, ok := keys.Load(seed); ok {
+		return key.(byte)
+	}
 	src := mathRand.NewSource(seed)
 	newrand := mathRand.New(src)
 	number := newrand.Int()
+	keys.Store(seed, byte(number))
 	return byte(number)
 }
@@ -1,10 +1,46 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Span    Span
+	// Comments are the comments found between the previous token and
+	// this one, kept around for tools that want to read them back
+	Comments []Comment
+}
+
+// Comment is a `// line` or `/* block */` comment, Text includes the
+// comment markers
+type Comment struct {
+	Text string
+	Span Span
+}
+
+// Position is a location in the source code. Offset is the byte
+// offset into the input, Line and Column start counting at 1
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// IsValid reports whether the position was produced by the lexer,
+// nodes built by hand (tests, macro expansion) carry a zero Position
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+func (p Position) String() string { return fmt.Sprintf("%d:%d", p.Line, p.Column) }
+
+// Span covers the source text from Start up to, but not including, End.
+// File names the imported module the text belongs to, it is empty for
+// the program being compiled
+type Span struct {
+	File  string
+	Start Position
+	End   Position
 }
 
 const (
@@ -15,7 +51,10 @@ const (
 	// ex: add, foobar, x, y, ....
 	IDENT  = "IDENT"
 	INT    = "INT"
+	FLOAT  = "FLOAT"
 	STRING = "STRING"
+	// TEMPLATE is a string holding ${...} interpolations
+	TEMPLATE = "TEMPLATE"
 
 	// Operators
 	ASSIGN     = "="
@@ -26,10 +65,25 @@ const (
 	BSLASH     = "\\"
 	LT         = "<"
 	GT         = ">"
+	LTE        = "<="
+	GTE        = ">="
+	PERCENT    = "%"
+	AND        = "&&"
+	OR         = "||"
 	BANG       = "!"
 	EQUALITY   = "=="
 	INEQUALITY = "!="
 	COLON      = ":"
+	DOT        = "."
+	ARROW      = "=>"
+	DOTDOT     = ".."
+	ELLIPSIS   = "..."
+
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	FSLASH_ASSIGN   = "/="
+	PERCENT_ASSIGN  = "%="
 
 	// Delimiters
 	COMMA     = ","
@@ -50,17 +104,53 @@ const (
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
 	MACRO    = "MACRO"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
+	AS       = "AS"
+	THROW    = "THROW"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	STRUCT   = "STRUCT"
+	MATCH    = "MATCH"
+	CONST    = "CONST"
+	ENUM     = "ENUM"
+	YIELD    = "YIELD"
+	SPAWN    = "SPAWN"
 )
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"macro":  MACRO,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"macro":    MACRO,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"import":   IMPORT,
+	"export":   EXPORT,
+	"as":       AS,
+	"throw":    THROW,
+	"try":      TRY,
+	"catch":    CATCH,
+	"finally":  FINALLY,
+	"struct":   STRUCT,
+	"match":    MATCH,
+	"const":    CONST,
+	"enum":     ENUM,
+	"yield":    YIELD,
+	"spawn":    SPAWN,
 }
 
 // LookupIdent function takes in an identifier(string)
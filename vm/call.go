@@ -0,0 +1,71 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"zetsu/mutil"
+	"zetsu/object"
+)
+
+// errMaxCallDepth is returned when a call finds no room left for its
+// frame or its locals
+var errMaxCallDepth = errors.New("maximum call depth exceeded")
+
+// callReserve is the number of stack slots a call leaves free for the
+// values its function pushes, so deep recursion runs out of call depth
+// rather than overflowing the stack halfway through an expression
+const callReserve = 256
+
+// bindArguments lays the arguments of a call out in the order fn keeps
+// its parameters. Named arguments, the last len(names) of args, move to
+// the local of their parameter, extra positional ones are packed into
+// the rest array and parameters left without an argument get an empty
+// local for OpSkipDefault to fill in
+func (vm *VM) bindArguments(fn *object.CompiledFunction, args, names []object.Object) ([]object.Object, error) {
+	positional := len(args) - len(names)
+	if positional > fn.NumParams && !fn.Rest {
+		return nil, fmt.Errorf("wrong number of arguments to %s. want=%d, got=%d", fn.DisplayName(), fn.NumParams, len(args))
+	}
+
+	params := make([]object.Object, fn.NumParams, fn.NumParams+1)
+	bound := copy(params, args[:positional])
+	if fn.Rest {
+		rest := make([]object.Object, 0, positional-bound)
+		for _, arg := range args[bound:positional] {
+			if dec, err := mutil.DecryptObject(arg, vm.inslen); err == nil {
+				arg = dec
+			}
+			rest = append(rest, arg)
+		}
+		params = append(params, &object.Array{Elements: rest})
+	}
+
+	for i, name := range names {
+		param := name.(*object.String).Value
+		index := paramIndex(fn, param)
+		if index < 0 {
+			return nil, fmt.Errorf("%s has no parameter named %s", fn.DisplayName(), param)
+		}
+		if params[index] != nil {
+			return nil, fmt.Errorf("%s got more than one value for parameter %s", fn.DisplayName(), param)
+		}
+		params[index] = args[positional+i]
+	}
+
+	for i := 0; i < fn.NumParams-fn.NumDefaults; i++ {
+		if params[i] == nil {
+			return nil, fmt.Errorf("%s is missing an argument for parameter %s", fn.DisplayName(), fn.Params[i])
+		}
+	}
+
+	return params, nil
+}
+
+func paramIndex(fn *object.CompiledFunction, name string) int {
+	for i, param := range fn.Params {
+		if param == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -6,9 +6,21 @@ import (
 )
 
 type Frame struct {
-	cl *object.Closure
-	ip int
-	bp int
+	cl       *object.Closure
+	ip       int
+	bp       int
+	handlers []handler
+	// gen is set on the frame of a generator, exit is where the for-in
+	// loop resuming it goes once it is done, -1 when next() resumed it
+	gen  *Generator
+	exit int
+}
+
+// handler is a try block that is running in the frame, catchPos is
+// where its catch starts and sp the stack height to go back to
+type handler struct {
+	catchPos int
+	sp       int
 }
 
 func NewFrame(cl *object.Closure, basePointer int) *Frame {
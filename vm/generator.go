@@ -0,0 +1,147 @@
+package vm
+
+import (
+	"fmt"
+	"zetsu/builtin"
+	"zetsu/global"
+	"zetsu/object"
+)
+
+// nextBuiltin is the builtin the vm resumes generators for
+var nextBuiltin = builtin.GetBuiltinByName("next")
+
+// Generator is what calling a fn* evaluates to. It holds the frame of
+// the call, suspended at its last yield, and the stack slots the frame
+// had from its base pointer up. Resuming it copies the slots back on
+// top of the stack and runs the frame until the next yield
+type Generator struct {
+	frame    *Frame
+	stack    []object.Object
+	upvalues []*object.Upvalue
+	started  bool
+	running  bool
+	done     bool
+}
+
+func (g *Generator) Type() object.ObjectType { return object.GENERATOR_OBJ }
+
+// Inspect reports the state of the generator rather than its address,
+// so printing one is stable across runs
+func (g *Generator) Inspect() string {
+	switch {
+	case g.done:
+		return "Generator[done]"
+	case g.running:
+		return "Generator[running]"
+	default:
+		return "Generator[suspended]"
+	}
+}
+
+// startGenerator turns the frame just pushed for a call to a fn* into
+// a generator, the body only starts running once it is resumed
+func (vm *VM) startGenerator() error {
+	frame := vm.popFrame()
+	gen := &Generator{frame: frame}
+	frame.gen = gen
+	vm.suspend(frame)
+	return vm.push(gen)
+}
+
+// suspend saves the stack slots of the generator frame that was just
+// popped and drops them from the stack. Handlers keep the stack height
+// they go back to, and closed upvalues their slot, relative to the base
+// pointer while suspended
+func (vm *VM) suspend(frame *Frame) {
+	gen := frame.gen
+	gen.stack = append(gen.stack[:0], vm.stack[frame.bp:vm.stackPointer]...)
+	gen.upvalues = append(gen.upvalues[:0], vm.upvalues[vm.openFrom(frame.bp):]...)
+	vm.closeUpvalues(frame.bp)
+	for _, upvalue := range gen.upvalues {
+		upvalue.Index -= frame.bp
+	}
+	for i := range frame.handlers {
+		frame.handlers[i].sp -= frame.bp
+	}
+	gen.running = false
+	vm.stackPointer = frame.bp - 1
+}
+
+// resume runs gen on top of the current frame until it yields or
+// returns. sent is the value of the yield gen is suspended at, exit
+// is passed on to the frame, see Frame
+func (vm *VM) resume(gen *Generator, sent object.Object, exit int) error {
+	if gen.running {
+		return fmt.Errorf("generator is already running")
+	}
+	if gen.done {
+		return vm.leaveGenerator(exit)
+	}
+
+	bp := vm.stackPointer + 1
+	if bp+len(gen.stack)+callReserve >= global.StackSize || vm.frameIndex >= global.MaxFrames {
+		return errMaxCallDepth
+	}
+	vm.stack[vm.stackPointer] = gen // where a call keeps its callee
+	copy(vm.stack[bp:], gen.stack)
+	vm.stackPointer = bp + len(gen.stack)
+
+	frame := gen.frame
+	frame.bp = bp
+	frame.exit = exit
+	for i := range frame.handlers {
+		frame.handlers[i].sp += bp
+	}
+	for _, upvalue := range gen.upvalues {
+		index := bp + upvalue.Index
+		upvalue.Reopen(&vm.stack[index], index)
+	}
+	vm.upvalues = append(vm.upvalues, gen.upvalues...)
+	if err := vm.pushFrame(frame); err != nil {
+		return err
+	}
+	gen.running = true
+
+	if !gen.started {
+		gen.started = true
+		return nil
+	}
+	return vm.push(sent)
+}
+
+// finishGenerator ends the generator whose frame was just popped by a
+// return, the value it returns is dropped
+func (vm *VM) finishGenerator(frame *Frame) error {
+	frame.gen.stop()
+	vm.stackPointer = frame.bp - 1
+	return vm.leaveGenerator(frame.exit)
+}
+
+// leaveGenerator hands control back from a generator that is done, a
+// for-in loop over it exits and next() evaluates to null
+func (vm *VM) leaveGenerator(exit int) error {
+	if exit >= 0 {
+		vm.currentFrame().ip = exit - 1
+		return nil
+	}
+	return vm.push(global.Null)
+}
+
+// callNext resumes gen for next(gen) and next(gen, value), the value
+// is what the yield gen is suspended at evaluates to
+func (vm *VM) callNext(gen *Generator, numArgs int) error {
+	var sent object.Object = global.Null
+	if numArgs == 2 {
+		sent = vm.pop()
+	}
+	vm.stackPointer -= 2 // the generator and next itself
+	return vm.resume(gen, sent, -1)
+}
+
+// stop marks the generator as done, it can't be resumed anymore
+func (g *Generator) stop() {
+	g.done = true
+	g.running = false
+	g.stack = nil
+	g.upvalues = nil
+}
@@ -0,0 +1,187 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"zetsu/builtin"
+	"zetsu/global"
+	"zetsu/mutil"
+	"zetsu/object"
+)
+
+// scheduler keeps track of the tasks a program spawns, the program is
+// only done once all of them are. Each task runs on a vm of its own
+// that shares the constants of the program, which nothing writes to,
+// and gets a copy of the globals and of the spawned call, see
+// isolation, so tasks never race over a value
+type scheduler struct {
+	mu    sync.Mutex
+	tasks []*object.Task
+}
+
+func newScheduler() *scheduler { return &scheduler{} }
+
+// start runs fn on a goroutine of its own and finishes task with what
+// it returns
+func (s *scheduler) start(task *object.Task, fn func() (object.Object, error)) {
+	s.mu.Lock()
+	s.tasks = append(s.tasks, task)
+	s.mu.Unlock()
+
+	// the task counts as running from here, see object.StartRunning,
+	// and stops once whoever waits for it has been handed the outcome
+	object.StartRunning()
+	go func() {
+		task.Finish(fn())
+		object.StopRunning()
+	}()
+}
+
+// wait blocks until every task is done, tasks can still be spawned
+// while it waits. It returns the error of the first task that failed
+// without anyone waiting for it
+func (s *scheduler) wait() error {
+	var first error
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		if i == len(s.tasks) {
+			s.mu.Unlock()
+			return first
+		}
+		task := s.tasks[i]
+		s.mu.Unlock()
+
+		if err := task.Unobserved(); err != nil && first == nil {
+			first = err
+		}
+	}
+}
+
+// spawn runs the callee below the top numArgs stack slots, called with
+// them as arguments, as a task and pushes the task
+func (vm *VM) spawn(numArgs int) error {
+	base := vm.stackPointer - 1 - numArgs
+	switch callee := vm.stack[base].(type) {
+	case *object.Closure, *builtin.BuiltIn:
+	default:
+		if dec, err := mutil.DecryptObject(callee, vm.inslen); err == nil {
+			callee = dec
+		}
+		return fmt.Errorf("cannot spawn %s", callee.Type())
+	}
+
+	is := isolation{}
+	child := vm.fork(is)
+	for _, obj := range vm.stack[base:vm.stackPointer] {
+		child.stack[child.stackPointer] = is.copy(obj)
+		child.stackPointer++
+	}
+	vm.stackPointer = base
+
+	task := object.NewTask()
+	vm.tasks.start(task, func() (object.Object, error) { return child.runTask(numArgs) })
+	return vm.push(task)
+}
+
+// fork makes the vm a task runs on, it starts out with an empty main
+// frame and a copy of the globals vm has set
+func (vm *VM) fork(is isolation) *VM {
+	globals := make([]object.Object, vm.numGlobals)
+	for i, obj := range vm.globals[:vm.numGlobals] {
+		globals[i] = is.copy(obj)
+	}
+
+	frames := make([]*Frame, global.MaxFrames)
+	frames[0] = NewFrame(&object.Closure{Fn: &object.CompiledFunction{}}, 0)
+
+	return &VM{
+		constants:  vm.constants,
+		stack:      make([]object.Object, global.StackSize),
+		globals:    globals,
+		numGlobals: vm.numGlobals,
+		frames:     frames,
+		frameIndex: 1,
+		inslen:     vm.inslen,
+		tasks:      vm.tasks,
+	}
+}
+
+// runTask makes the call laid out at the bottom of the stack of a
+// forked vm, a task that fails reports the message it was thrown with
+func (vm *VM) runTask(numArgs int) (object.Object, error) {
+	err := vm.executeCall(numArgs, nil)
+	if err == nil {
+		err = vm.exec()
+	}
+	if err != nil {
+		if t, ok := err.(*thrown); ok {
+			err = errors.New(t.err.Message)
+		}
+		return nil, vm.locate(err)
+	}
+
+	result := vm.StackTop()
+	if dec, err := mutil.DecryptObject(result, vm.inslen); err == nil {
+		result = dec
+	}
+	return result, nil
+}
+
+// isolation copies what a task starts out with, arrays, hashes,
+// structs and the variables closures captured are copied deeply so a
+// task never writes to a value another one reads. It maps every value
+// copied so far to its copy, a value reached twice is copied once,
+// which keeps it shared within the task and lets a value hold itself.
+// Channels and tasks stay shared, they are how tasks talk
+type isolation map[object.Object]object.Object
+
+func (is isolation) copy(obj object.Object) object.Object {
+	switch obj.(type) {
+	case *object.Array, *object.Hash, *object.Struct, *object.Closure, *object.Upvalue:
+	default:
+		return obj
+	}
+	if dup, ok := is[obj]; ok {
+		return dup
+	}
+
+	switch obj := obj.(type) {
+	case *object.Array:
+		dup := &object.Array{Elements: make([]object.Object, len(obj.Elements))}
+		is[obj] = dup
+		for i, el := range obj.Elements {
+			dup.Elements[i] = is.copy(el)
+		}
+		return dup
+	case *object.Hash:
+		dup := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair, len(obj.Pairs))}
+		is[obj] = dup
+		for key, pair := range obj.Pairs {
+			dup.Pairs[key] = object.HashPair{Key: pair.Key, Value: is.copy(pair.Value)}
+		}
+		return dup
+	case *object.Struct:
+		dup := &object.Struct{Def: obj.Def, Values: make([]object.Object, len(obj.Values))}
+		is[obj] = dup
+		for i, value := range obj.Values {
+			dup.Values[i] = is.copy(value)
+		}
+		return dup
+	case *object.Closure:
+		// the captured variables may still be open on the stack of
+		// vm, the copies are closed over the values they have now
+		dup := &object.Closure{Fn: obj.Fn, Free: make([]*object.Upvalue, len(obj.Free))}
+		is[obj] = dup
+		for i, upvalue := range obj.Free {
+			dup.Free[i] = is.copy(upvalue).(*object.Upvalue)
+		}
+		return dup
+	default:
+		upvalue := obj.(*object.Upvalue)
+		dup := object.ClosedUpvalue(nil)
+		is[obj] = dup
+		dup.Set(is.copy(upvalue.Get()))
+		return dup
+	}
+}
@@ -0,0 +1,94 @@
+package vm
+
+import (
+	"fmt"
+	"zetsu/object"
+)
+
+// thrown carries an error raised by throw up to Run, where the
+// nearest handler picks it up
+type thrown struct {
+	err *object.Error
+}
+
+func (t *thrown) Error() string { return "uncaught error: " + t.err.Message }
+
+// handle unwinds the frames down to the innermost try that is running
+// and jumps to its catch with err on top of the stack. It reports
+// false when no try is left to catch err
+func (vm *VM) handle(err error) bool {
+	index := vm.frameIndex
+	for index > 0 && len(vm.frames[index-1].handlers) == 0 {
+		index--
+	}
+	if index == 0 {
+		return false
+	}
+
+	var errObj *object.Error
+	if t, ok := err.(*thrown); ok {
+		errObj = t.err
+	} else {
+		errObj = &object.Error{Message: err.Error(), Stack: vm.stackTrace()}
+	}
+
+	// generators whose frames are unwound can't be resumed
+	for _, frame := range vm.frames[index:vm.frameIndex] {
+		if frame.gen != nil {
+			frame.gen.stop()
+		}
+	}
+
+	vm.frameIndex = index
+	frame := vm.currentFrame()
+	h := frame.handlers[len(frame.handlers)-1]
+	frame.handlers = frame.handlers[:len(frame.handlers)-1]
+
+	vm.closeUpvalues(h.sp)
+	vm.stackPointer = h.sp
+	if err := vm.push(errObj); err != nil {
+		return false
+	}
+	frame.ip = h.catchPos - 1
+	return true
+}
+
+// raise turns a thrown value into an error, errors keep the stack
+// they were raised with so rethrowing one doesn't lose where it came from
+func (vm *VM) raise(value object.Object) error {
+	switch value := value.(type) {
+	case *object.Error:
+		return &thrown{err: value}
+	case *object.String:
+		return &thrown{err: &object.Error{Message: value.Value, Stack: vm.stackTrace()}}
+	default:
+		return &thrown{err: &object.Error{Message: value.Inspect(), Stack: vm.stackTrace()}}
+	}
+}
+
+// stackTrace describes the active calls, innermost first
+func (vm *VM) stackTrace() []string {
+	trace := make([]string, 0, vm.frameIndex)
+	for i := vm.frameIndex - 1; i >= 0; i-- {
+		frame := vm.frames[i]
+		name := frame.cl.Fn.Name
+		switch {
+		case i == 0:
+			name = "<main>"
+		case name == "":
+			name = "<fn>"
+		}
+
+		span, ok := frame.cl.Fn.SourceMap.Lookup(frame.ip)
+		if !ok {
+			trace = append(trace, name)
+			continue
+		}
+		if span.File != "" {
+			trace = append(trace, fmt.Sprintf("%s at %s:%s", name, span.File, span.Start))
+		} else {
+			trace = append(trace, fmt.Sprintf("%s at %s", name, span.Start))
+		}
+	}
+	return trace
+}
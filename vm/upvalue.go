@@ -0,0 +1,43 @@
+package vm
+
+import "zetsu/object"
+
+// captureUpvalue returns the open upvalue of the stack slot at index,
+// closures capturing the same slot share one upvalue. vm.upvalues is
+// kept sorted by slot so closing them only looks at its tail
+func (vm *VM) captureUpvalue(index int) *object.Upvalue {
+	i := len(vm.upvalues)
+	for i > 0 && vm.upvalues[i-1].Index >= index {
+		if vm.upvalues[i-1].Index == index {
+			return vm.upvalues[i-1]
+		}
+		i--
+	}
+
+	upvalue := object.NewUpvalue(&vm.stack[index], index)
+	vm.upvalues = append(vm.upvalues, nil)
+	copy(vm.upvalues[i+1:], vm.upvalues[i:])
+	vm.upvalues[i] = upvalue
+	return upvalue
+}
+
+// closeUpvalues closes the open upvalues of the stack slots from level
+// up, it is called before the slots are dropped or reused
+func (vm *VM) closeUpvalues(level int) {
+	i := vm.openFrom(level)
+	for j, upvalue := range vm.upvalues[i:] {
+		upvalue.Close()
+		vm.upvalues[i+j] = nil
+	}
+	vm.upvalues = vm.upvalues[:i]
+}
+
+// openFrom returns the position in vm.upvalues of the first open
+// upvalue of a slot from level up
+func (vm *VM) openFrom(level int) int {
+	i := len(vm.upvalues)
+	for i > 0 && vm.upvalues[i-1].Index >= level {
+		i--
+	}
+	return i
+}
@@ -1,10 +1,14 @@
 package vm
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"zetsu/builtin"
 	"zetsu/code"
 	"zetsu/compiler"
+	"zetsu/errrs"
 	"zetsu/global"
 	"zetsu/mutil"
 	"zetsu/object"
@@ -17,13 +21,17 @@ type VM struct {
 	stack        []object.Object
 	stackPointer int // top of stack is stack[stackPointer-1]
 	globals      []object.Object
+	numGlobals   int // globals below numGlobals may have been set
 	frames       []*Frame
 	frameIndex   int
 	inslen       int
+	tasks        *scheduler
+	upvalues     []*object.Upvalue // the open ones, see captureUpvalue
+	wide         bool              // the instruction being run has the OpWide prefix
 }
 
 func New(bc *compiler.ByteCode) *VM {
-	mainfn := &object.CompiledFunction{Instructions: bc.Instructions}
+	mainfn := &object.CompiledFunction{Instructions: bc.Instructions, SourceMap: bc.SourceMap}
 	frames := make([]*Frame, global.MaxFrames)
 
 	mainClosure := &object.Closure{Fn: mainfn}
@@ -38,16 +46,57 @@ func New(bc *compiler.ByteCode) *VM {
 		frames:       frames,
 		frameIndex:   1,
 		inslen:       len(bc.Instructions),
+		tasks:        newScheduler(),
 	}
 }
 
 func NewWithGlobalStore(bc *compiler.ByteCode, globals []object.Object) *VM {
 	vm := New(bc)
 	vm.globals = globals
+	for i := len(globals) - 1; i >= 0; i-- {
+		if globals[i] != nil {
+			vm.numGlobals = i + 1
+			break
+		}
+	}
 	return vm
 }
 
+// Run executes the bytecode and waits for the tasks it spawned, an
+// uncaught error is reported as a diagnostic at its instruction's source
 func (vm *VM) Run() error {
+	object.StartRunning()
+	defer object.StopRunning()
+	if err := vm.exec(); err != nil {
+		return vm.locate(err)
+	}
+	return vm.tasks.wait()
+}
+
+// exec runs until the frames are done or an error no try catches is
+// raised, the frames are left as they were when it was raised
+func (vm *VM) exec() error {
+	for {
+		err := vm.run()
+		if err == nil {
+			return nil
+		}
+		if !vm.handle(err) {
+			return err
+		}
+	}
+}
+
+func (vm *VM) locate(err error) error {
+	frame := vm.currentFrame()
+	span, ok := frame.cl.Fn.SourceMap.Lookup(frame.ip)
+	if !ok {
+		return err
+	}
+	return &errrs.Diagnostic{Span: span, Msg: err.Error()}
+}
+
+func (vm *VM) run() error {
 	var ip int
 	var ins code.Instructions
 	var op code.Opcode
@@ -57,14 +106,16 @@ func (vm *VM) Run() error {
 
 		ip = vm.currentFrame().ip
 		ins = vm.currentFrame().Instructions()
-		ins[ip] = security.XOROne(ins[ip], vm.inslen)
-		op = code.Opcode(ins[ip])
-		ins[ip] = security.XOROne(ins[ip], vm.inslen)
+		op = code.Opcode(security.XOROne(ins[ip], vm.inslen))
+		vm.wide = op == code.OpWide
+		if vm.wide {
+			vm.currentFrame().ip++
+			op = code.Opcode(security.XOROne(ins[ip+1], vm.inslen))
+		}
 
 		switch op {
 		case code.OpConstant:
-			constIndex := code.ReadUint16(ins[ip+1:], vm.inslen)
-			vm.currentFrame().ip += 2
+			constIndex := vm.readOperand(2)
 
 			if err := vm.push(vm.constants[constIndex]); err != nil {
 				return err
@@ -77,7 +128,7 @@ func (vm *VM) Run() error {
 			if err := vm.executeMinusOperation(); err != nil {
 				return err
 			}
-		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv:
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod:
 			if err := vm.execBinaryOperation(op); err != nil {
 				return err
 			}
@@ -90,15 +141,21 @@ func (vm *VM) Run() error {
 				return err
 			}
 		case code.OpArray:
-			numElements := int(code.ReadUint16(ins[ip+1:], vm.inslen))
-			vm.currentFrame().ip += 2
+			numElements := vm.readOperand(2)
 			array := vm.buildArray(vm.stackPointer-numElements, vm.stackPointer)
+			vm.stackPointer = vm.stackPointer - numElements
 			if err := vm.push(array); err != nil {
 				return err
 			}
+		case code.OpConcat:
+			numParts := vm.readOperand(2)
+			str := vm.buildString(vm.stackPointer-numParts, vm.stackPointer)
+			vm.stackPointer = vm.stackPointer - numParts
+			if err := vm.push(str); err != nil {
+				return err
+			}
 		case code.OpHash:
-			numElements := int(code.ReadUint16(ins[ip+1:], vm.inslen))
-			vm.currentFrame().ip += 2
+			numElements := vm.readOperand(2)
 			hash, err := vm.buildHash(vm.stackPointer-numElements, vm.stackPointer)
 			if err != nil {
 				return err
@@ -107,33 +164,103 @@ func (vm *VM) Run() error {
 			if err := vm.push(hash); err != nil {
 				return err
 			}
-		case code.OpEqual, code.OpUnEqual, code.OpGreater:
+		case code.OpStruct:
+			numElements := vm.readOperand(2)
+			structValue, err := vm.buildStruct(vm.stackPointer-numElements, vm.stackPointer)
+			if err != nil {
+				return err
+			}
+			vm.stackPointer = vm.stackPointer - numElements - 1
+			if err := vm.push(structValue); err != nil {
+				return err
+			}
+		case code.OpGetField:
+			field := vm.pop()
+			left := vm.pop()
+			value, err := vm.getField(left, field)
+			if err != nil {
+				return err
+			}
+			if err := vm.push(value); err != nil {
+				return err
+			}
+		case code.OpSetField:
+			op := code.Opcode(vm.readOperand(1))
+			value := vm.pop()
+			field := vm.pop()
+			left := vm.pop()
+			structValue, ok := left.(*object.Struct)
+			if !ok {
+				return fmt.Errorf("field assignment not supported: %s", left.Type())
+			}
+			if op != 0 { // compound assignment, see compiler
+				current, err := structValue.Get(field.(*object.String).Value)
+				if err != nil {
+					return err
+				}
+				vm.push(current)
+				vm.push(value)
+				if err := vm.execBinaryOperation(op); err != nil {
+					return err
+				}
+				value = vm.pop()
+			}
+			if err := structValue.Set(field.(*object.String).Value, value); err != nil {
+				return err
+			}
+			if err := vm.push(value); err != nil {
+				return err
+			}
+		case code.OpMatchValue:
+			pattern := vm.pop()
+			value := vm.pop()
+			if err := vm.push(nativeBoolToBooleanObject(matchValue(value, pattern))); err != nil {
+				return err
+			}
+		case code.OpMatchArray:
+			length := vm.readOperand(2)
+			array, ok := vm.pop().(*object.Array)
+			if err := vm.push(nativeBoolToBooleanObject(ok && len(array.Elements) == length)); err != nil {
+				return err
+			}
+		case code.OpMatchHash:
+			_, ok := vm.pop().(*object.Hash)
+			if err := vm.push(nativeBoolToBooleanObject(ok)); err != nil {
+				return err
+			}
+		case code.OpMatchKey:
+			key := vm.pop()
+			hash := vm.pop().(*object.Hash)
+			_, ok := hash.Pairs[key.(object.Hashable).HashKey()]
+			if err := vm.push(nativeBoolToBooleanObject(ok)); err != nil {
+				return err
+			}
+		case code.OpEqual, code.OpUnEqual, code.OpGreater, code.OpGreaterEqual:
 			if err := vm.executeComparison(op); err != nil {
 				return err
 			}
 		case code.OpJump:
-			pos := int(code.ReadUint16(ins[ip+1:], vm.inslen))
+			pos := vm.readOperand(2)
 			vm.currentFrame().ip = pos - 1
 		case code.OpJumpFalse:
-			pos := int(code.ReadUint16(ins[ip+1:], vm.inslen))
-			vm.currentFrame().ip += 2
+			pos := vm.readOperand(2)
 			condition := vm.pop()
 			if !isTruthy(condition) {
 				vm.currentFrame().ip = pos - 1
 			}
 		case code.OpSetGlobal:
-			globalIndex := code.ReadUint16(ins[ip+1:], vm.inslen)
-			vm.currentFrame().ip += 2
+			globalIndex := vm.readOperand(2)
+			if globalIndex >= vm.numGlobals {
+				vm.growGlobals(globalIndex)
+			}
 			vm.globals[globalIndex] = vm.pop()
 		case code.OpGetGlobal:
-			globalIndex := code.ReadUint16(ins[ip+1:], vm.inslen)
-			vm.currentFrame().ip += 2
+			globalIndex := vm.readOperand(2)
 			if err := vm.push(vm.globals[globalIndex]); err != nil {
 				return err
 			}
 		case code.OpSetLocal:
-			localIndex := code.ReadUint8(ins[ip+1:], vm.inslen)
-			vm.currentFrame().ip++
+			localIndex := vm.readOperand(1)
 			frame := vm.currentFrame()
 			obj := vm.pop()
 			encObj, err := mutil.EncryptObject(obj, vm.inslen)
@@ -143,36 +270,115 @@ func (vm *VM) Run() error {
 				vm.stack[frame.bp+int(localIndex)] = encObj
 			}
 		case code.OpGetLocal:
-			localIndex := code.ReadUint8(ins[ip+1:], vm.inslen)
-			vm.currentFrame().ip++
+			localIndex := vm.readOperand(1)
 			frame := vm.currentFrame()
 			if err := vm.push(vm.stack[frame.bp+int(localIndex)]); err != nil {
 				return err
 			}
 		case code.OpGetBuiltin:
-			builtinIndex := code.ReadUint8(ins[ip+1:], vm.inslen)
-			vm.currentFrame().ip++
+			builtinIndex := vm.readOperand(1)
 			definition := builtin.Builtins[builtinIndex]
 			if err := vm.push(definition.Builtin); err != nil {
 				return err
 			}
 		case code.OpGetFree:
-			freeIndex := code.ReadUint8(ins[ip+1:], vm.inslen)
-			vm.currentFrame().ip++
+			freeIndex := vm.readOperand(1)
 			currentClosure := vm.currentFrame().cl
-			if err := vm.push(currentClosure.Free[freeIndex]); err != nil {
+			if err := vm.push(currentClosure.Free[freeIndex].Get()); err != nil {
 				return err
 			}
+		case code.OpSetFree:
+			freeIndex := vm.readOperand(1)
+			currentClosure := vm.currentFrame().cl
+			obj := vm.pop()
+			if encObj, err := mutil.EncryptObject(obj, vm.inslen); err == nil {
+				obj = encObj
+			}
+			currentClosure.Free[freeIndex].Set(obj)
+		case code.OpCaptureLocal:
+			localIndex := vm.readOperand(1)
+			upvalue := vm.captureUpvalue(vm.currentFrame().bp + localIndex)
+			if err := vm.push(upvalue); err != nil {
+				return err
+			}
+		case code.OpCaptureFree:
+			freeIndex := vm.readOperand(1)
+			if err := vm.push(vm.currentFrame().cl.Free[freeIndex]); err != nil {
+				return err
+			}
+		case code.OpCloseUpvalues:
+			localIndex := vm.readOperand(1)
+			vm.closeUpvalues(vm.currentFrame().bp + localIndex)
+		case code.OpNewCell:
+			globalIndex := vm.readOperand(2)
+			if globalIndex >= vm.numGlobals {
+				vm.growGlobals(globalIndex)
+			}
+			obj := vm.pop()
+			if encObj, err := mutil.EncryptObject(obj, vm.inslen); err == nil {
+				obj = encObj
+			}
+			vm.globals[globalIndex] = object.ClosedUpvalue(obj)
+		case code.OpGetCell:
+			globalIndex := vm.readOperand(2)
+			if err := vm.push(vm.globals[globalIndex].(*object.Upvalue).Get()); err != nil {
+				return err
+			}
+		case code.OpSetCell:
+			globalIndex := vm.readOperand(2)
+			obj := vm.pop()
+			if encObj, err := mutil.EncryptObject(obj, vm.inslen); err == nil {
+				obj = encObj
+			}
+			vm.globals[globalIndex].(*object.Upvalue).Set(obj)
 		case code.OpIndex:
 			index := vm.pop()
 			left := vm.pop()
 			if err := vm.execIndexOperation(left, index); err != nil {
 				return err
 			}
+		case code.OpSlice:
+			end := vm.pop()
+			start := vm.pop()
+			left := vm.pop()
+			slice, err := object.Slice(left, start, end)
+			if err != nil {
+				return err
+			}
+			if err := vm.push(slice); err != nil {
+				return err
+			}
+		case code.OpRange:
+			end := vm.pop()
+			start := vm.pop()
+			rng, err := object.NewRange(start, end, &object.Integer{Value: 1})
+			if err != nil {
+				return err
+			}
+			if err := vm.push(rng); err != nil {
+				return err
+			}
+		case code.OpSetIndex:
+			op := code.Opcode(vm.readOperand(1))
+			value := vm.pop()
+			index := vm.pop()
+			left := vm.pop()
+			if op != 0 { // compound assignment, see compiler
+				combined, err := vm.execCompoundIndex(op, left, index, value)
+				if err != nil {
+					return err
+				}
+				value = combined
+			}
+			if err := vm.execSetIndex(left, index, value); err != nil {
+				return err
+			}
+			if err := vm.push(value); err != nil {
+				return err
+			}
 		case code.OpClosure:
-			constIndex := code.ReadUint16(ins[ip+1:], vm.inslen)
-			numFree := code.ReadUint8(ins[ip+3:], vm.inslen)
-			vm.currentFrame().ip += 3
+			constIndex := vm.readOperand(2)
+			numFree := vm.readOperand(1)
 			if err := vm.pushClosure(int(constIndex), int(numFree)); err != nil {
 				return err
 			}
@@ -182,28 +388,114 @@ func (vm *VM) Run() error {
 				return err
 			}
 		case code.OpCall:
-			numArgs := code.ReadUint8(ins[ip+1:], vm.inslen)
-			vm.currentFrame().ip++
-			if err := vm.executeCall(int(numArgs)); err != nil {
+			numArgs := vm.readOperand(1)
+			if err := vm.executeCall(int(numArgs), nil); err != nil {
+				return err
+			}
+		case code.OpTailCall:
+			numArgs := vm.readOperand(1)
+			if err := vm.tailCall(int(numArgs)); err != nil {
+				return err
+			}
+		case code.OpCallNamed:
+			numArgs := vm.readOperand(1)
+			namesIndex := vm.readOperand(2)
+			names := vm.constants[namesIndex].(*object.Array)
+			if err := vm.executeCall(int(numArgs), names.Elements); err != nil {
 				return err
 			}
+		case code.OpSkipDefault:
+			localIndex := vm.readOperand(1)
+			pos := vm.readOperand(2)
+			frame := vm.currentFrame()
+			if vm.stack[frame.bp+int(localIndex)] != nil {
+				frame.ip = pos - 1
+			}
 		case code.OpReturnValue:
 			returnValue := vm.pop()
 			frame := vm.popFrame()
+			vm.closeUpvalues(frame.bp)
+			if frame.gen != nil {
+				if err := vm.finishGenerator(frame); err != nil {
+					return err
+				}
+				continue
+			}
 			vm.stackPointer = frame.bp - 1
 			if err := vm.push(returnValue); err != nil {
 				return err
 			}
 		case code.OpReturn:
 			frame := vm.popFrame()
+			vm.closeUpvalues(frame.bp)
+			if frame.gen != nil {
+				if err := vm.finishGenerator(frame); err != nil {
+					return err
+				}
+				continue
+			}
 			vm.stackPointer = frame.bp - 1
 			if err := vm.push(global.Null); err != nil {
 				return err
 			}
+		case code.OpGetIter:
+			iterable := vm.pop()
+			var iterator object.Object = iterable // generators are resumed by OpIterNext
+			if _, ok := iterable.(*Generator); !ok {
+				it, ok := object.NewIterator(iterable)
+				if !ok {
+					return fmt.Errorf("cannot iterate over %s", iterable.Type())
+				}
+				iterator = it
+			}
+			if err := vm.push(iterator); err != nil {
+				return err
+			}
+		case code.OpIterNext:
+			pos := vm.readOperand(2)
+			iterator := vm.pop()
+			if gen, ok := iterator.(*Generator); ok {
+				if err := vm.resume(gen, global.Null, pos); err != nil {
+					return err
+				}
+				continue
+			}
+			it := iterator.(*object.Iterator)
+			next, ok := it.Next()
+			if !ok {
+				if err := it.Err(); err != nil {
+					return err
+				}
+				vm.currentFrame().ip = pos - 1
+				continue
+			}
+			if err := vm.push(next); err != nil {
+				return err
+			}
+		case code.OpYield:
+			value := vm.pop()
+			vm.suspend(vm.popFrame())
+			if err := vm.push(value); err != nil {
+				return err
+			}
+		case code.OpSpawn:
+			numArgs := vm.readOperand(1)
+			if err := vm.spawn(numArgs); err != nil {
+				return err
+			}
 		case code.OpNull:
 			if err := vm.push(global.Null); err != nil {
 				return err
 			}
+		case code.OpTry:
+			pos := vm.readOperand(2)
+			frame := vm.currentFrame()
+			frame.handlers = append(frame.handlers, handler{catchPos: pos, sp: vm.stackPointer})
+		case code.OpEndTry:
+			frame := vm.currentFrame()
+			frame.handlers = frame.handlers[:len(frame.handlers)-1]
+		case code.OpThrow:
+			return vm.raise(vm.pop())
 		case code.OpPop:
 			vm.pop()
 		}
@@ -211,6 +503,36 @@ func (vm *VM) Run() error {
 	return nil
 }
 
+// readOperand reads the next operand of the instruction being run and
+// moves past it, width is the width the instruction defines for it.
+// Operands of instructions prefixed by OpWide are all code.WideWidth
+func (vm *VM) readOperand(width int) int {
+	frame := vm.currentFrame()
+	ins := frame.Instructions()[frame.ip+1:]
+	if vm.wide {
+		width = code.WideWidth
+	}
+	frame.ip += width
+
+	switch width {
+	case 1:
+		return int(code.ReadUint8(ins, vm.inslen))
+	case 2:
+		return int(code.ReadUint16(ins, vm.inslen))
+	default:
+		return int(code.ReadUint32(ins, vm.inslen))
+	}
+}
+
+// growGlobals makes room for global index. The globals of a forked vm
+// only hold the ones in use when it was forked
+func (vm *VM) growGlobals(index int) {
+	vm.numGlobals = index + 1
+	if index >= len(vm.globals) {
+		vm.globals = append(vm.globals, make([]object.Object, index+1-len(vm.globals))...)
+	}
+}
+
 func (vm *VM) StackTop() object.Object {
 	if vm.stackPointer == 0 {
 		return nil
@@ -234,9 +556,16 @@ func (vm *VM) pushClosure(constIndex, numFree int) error {
 		return fmt.Errorf("not a function: %+v", constant)
 	}
 
-	free := make([]object.Object, numFree)
+	// captured variables come as upvalues, other values, like the
+	// closure of the function creating this one, are closed over as is
+	free := make([]*object.Upvalue, numFree)
 	for i := 0; i < numFree; i++ {
-		free[i] = vm.stack[vm.stackPointer-numFree+i]
+		obj := vm.stack[vm.stackPointer-numFree+i]
+		if upvalue, ok := obj.(*object.Upvalue); ok {
+			free[i] = upvalue
+		} else {
+			free[i] = object.ClosedUpvalue(obj)
+		}
 	}
 	vm.stackPointer = vm.stackPointer - numFree
 
@@ -276,13 +605,11 @@ func (vm *VM) execBinaryOperation(op code.Opcode) error {
 	rtype := right.Type()
 	ltype := left.Type()
 
-	if rtype == object.INTEGER_OBJ && ltype == object.INTEGER_OBJ {
-		return vm.execBinaryIntegerOperation(op, left, right)
-	}
-
 	switch {
-	case rtype == object.INTEGER_OBJ && ltype == object.INTEGER_OBJ:
+	case object.IsInteger(left) && object.IsInteger(right):
 		return vm.execBinaryIntegerOperation(op, left, right)
+	case object.IsNumber(left) && object.IsNumber(right):
+		return vm.execBinaryFloatOperation(op, object.ToFloat(left), object.ToFloat(right))
 	case rtype == object.STRING_OBJ && ltype == object.STRING_OBJ:
 		return vm.execBinaryStringOperation(op, left, right)
 	}
@@ -290,10 +617,33 @@ func (vm *VM) execBinaryOperation(op code.Opcode) error {
 	return fmt.Errorf("Unsupported types for binary operation: %s, %s", ltype, rtype)
 }
 
+// integerOperators maps arithmetic opcodes to the operators
+// object.IntegerArithmetic understands
+var integerOperators = map[code.Opcode]string{
+	code.OpAdd: "+",
+	code.OpSub: "-",
+	code.OpMul: "*",
+	code.OpDiv: "/",
+	code.OpMod: "%",
+}
+
+// execBinaryIntegerOperation promotes results that overflow to a big
+// integer, dividing by zero is a runtime error a try can catch
 func (vm *VM) execBinaryIntegerOperation(op code.Opcode, left, right object.Object) error {
-	rval := right.(*object.Integer).Value
-	lval := left.(*object.Integer).Value
-	var result int64
+	operator, ok := integerOperators[op]
+	if !ok {
+		return fmt.Errorf("Unknown integer operator: %d", op)
+	}
+
+	result, err := object.IntegerArithmetic(operator, left, right)
+	if err != nil {
+		return err
+	}
+	return vm.push(result)
+}
+
+func (vm *VM) execBinaryFloatOperation(op code.Opcode, lval, rval float64) error {
+	var result float64
 
 	switch op {
 	case code.OpAdd:
@@ -304,11 +654,13 @@ func (vm *VM) execBinaryIntegerOperation(op code.Opcode, left, right object.Obje
 		result = lval * rval
 	case code.OpDiv:
 		result = lval / rval
+	case code.OpMod:
+		result = math.Mod(lval, rval)
 	default:
-		return fmt.Errorf("Unknown integer operator: %d", op)
+		return fmt.Errorf("Unknown float operator: %d", op)
 	}
 
-	return vm.push(&object.Integer{Value: result})
+	return vm.push(&object.Float{Value: result})
 }
 
 func (vm *VM) execBinaryStringOperation(op code.Opcode, left, right object.Object) error {
@@ -328,37 +680,91 @@ func (vm *VM) execIndexOperation(left, index object.Object) error {
 		return vm.execArrayIndex(left, index)
 	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
 		return vm.execStringIndex(left, index)
+	case left.Type() == object.RANGE_OBJ && index.Type() == object.INTEGER_OBJ:
+		item, ok := left.(*object.Range).At(index.(*object.Integer).Value)
+		if !ok {
+			return vm.push(global.Null)
+		}
+		return vm.push(item)
 	case left.Type() == object.HASH_OBJ:
 		return vm.execHashIndex(left, index)
+	case left.Type() == object.ERROR_OBJ && index.Type() == object.STRING_OBJ:
+		return vm.execErrorIndex(left, index)
 	default:
 		return fmt.Errorf("index operator not supported: %s", left.Type())
 	}
 }
 
+func (vm *VM) execSetIndex(left, index, value object.Object) error {
+	switch left := left.(type) {
+	case *object.Array:
+		i, ok := index.(*object.Integer)
+		if !ok {
+			return fmt.Errorf("array index must be INTEGER, got %s", index.Type())
+		}
+		pos, ok := left.Position(i.Value)
+		if !ok {
+			return fmt.Errorf("index out of range: %d (length %d)", i.Value, len(left.Elements))
+		}
+		left.Elements[pos] = value
+		return nil
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return fmt.Errorf("unusable as hash key: %s", index.Type())
+		}
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: value}
+		return nil
+	default:
+		return fmt.Errorf("index assignment not supported: %s", left.Type())
+	}
+}
+
+// execCompoundIndex combines the element currently stored at index
+// with value, using the arithmetic of a compound assignment
+func (vm *VM) execCompoundIndex(op code.Opcode, left, index, value object.Object) (object.Object, error) {
+	if array, ok := left.(*object.Array); ok {
+		if i, ok := index.(*object.Integer); ok {
+			if _, ok := array.Position(i.Value); !ok {
+				return nil, fmt.Errorf("index out of range: %d (length %d)", i.Value, len(array.Elements))
+			}
+		}
+	}
+	if err := vm.execIndexOperation(left, index); err != nil {
+		return nil, err
+	}
+	if err := vm.push(value); err != nil {
+		return nil, err
+	}
+	if err := vm.execBinaryOperation(op); err != nil {
+		return nil, err
+	}
+	return vm.pop(), nil
+}
+
 func (vm *VM) execStringIndex(str, index object.Object) error {
-	strVal := str.(*object.String).Value
-	i := index.(*object.Integer).Value
-	max := int64(len(strVal) - 1)
-	if i > max {
+	char, ok := str.(*object.String).At(index.(*object.Integer).Value)
+	if !ok {
 		return vm.push(global.Null)
-	} else if i < 0 {
-		strObj := &object.String{Value: string(strVal[max+i+1])}
-		return vm.push(strObj)
 	}
-	strObj := &object.String{Value: string(strVal[i])}
-	return vm.push(strObj)
+	return vm.push(char)
 }
 
 func (vm *VM) execArrayIndex(array, index object.Object) error {
 	arrayObj := array.(*object.Array)
-	i := index.(*object.Integer).Value
-	max := int64(len(arrayObj.Elements) - 1)
-	if i > max {
+	pos, ok := arrayObj.Position(index.(*object.Integer).Value)
+	if !ok {
 		return vm.push(global.Null)
-	} else if i < 0 {
-		return vm.push(arrayObj.Elements[max+i+1])
 	}
-	return vm.push(arrayObj.Elements[i])
+	return vm.push(arrayObj.Elements[pos])
+}
+
+func (vm *VM) execErrorIndex(errObj, index object.Object) error {
+	field, ok := errObj.(*object.Error).Field(index.(*object.String).Value)
+	if !ok {
+		return vm.push(global.Null)
+	}
+	return vm.push(field)
 }
 
 func (vm *VM) execHashIndex(hash, index object.Object) error {
@@ -394,21 +800,39 @@ func (vm *VM) executeBangOperation() error {
 
 func (vm *VM) executeMinusOperation() error {
 	operand := vm.pop()
-	if operand.Type() != object.INTEGER_OBJ {
+	switch operand := operand.(type) {
+	case *object.Integer, *object.BigInteger:
+		return vm.push(object.NegateInteger(operand))
+	case *object.Float:
+		return vm.push(&object.Float{Value: -operand.Value})
+	default:
 		return fmt.Errorf("unsupported object type for negation: %s", operand.Type())
 	}
-	value := operand.(*object.Integer).Value
-	return vm.push(&object.Integer{Value: -value})
 }
 
 func (vm *VM) executeComparison(op code.Opcode) error {
 	right := vm.pop()
 	left := vm.pop()
 
-	if left.Type() == object.INTEGER_OBJ || right.Type() == object.INTEGER_OBJ {
+	if object.IsInteger(left) && object.IsInteger(right) {
 		return vm.executeIntegerComparison(op, left, right)
 	}
 
+	if object.IsNumber(left) && object.IsNumber(right) {
+		return vm.executeFloatComparison(op, object.ToFloat(left), object.ToFloat(right))
+	}
+
+	// enum values are only ever equal to the same variant, never to
+	// a string that happens to read the same
+	if left.Type() == object.ENUM_OBJ || right.Type() == object.ENUM_OBJ {
+		switch op {
+		case code.OpEqual:
+			return vm.push(nativeBoolToBooleanObject(matchValue(left, right)))
+		case code.OpUnEqual:
+			return vm.push(nativeBoolToBooleanObject(!matchValue(left, right)))
+		}
+	}
+
 	switch op {
 	case code.OpEqual:
 		return vm.push(nativeBoolToBooleanObject(right.Inspect() == left.Inspect()))
@@ -420,8 +844,22 @@ func (vm *VM) executeComparison(op code.Opcode) error {
 }
 
 func (vm *VM) executeIntegerComparison(op code.Opcode, left, right object.Object) error {
-	leftValue := left.(*object.Integer).Value
-	rightValue := right.(*object.Integer).Value
+	cmp := object.CompareIntegers(left, right)
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp == 0))
+	case code.OpUnEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp != 0))
+	case code.OpGreater:
+		return vm.push(nativeBoolToBooleanObject(cmp > 0))
+	case code.OpGreaterEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp >= 0))
+	default:
+		return fmt.Errorf("unknown operator: %d", op)
+	}
+}
+
+func (vm *VM) executeFloatComparison(op code.Opcode, leftValue, rightValue float64) error {
 	switch op {
 	case code.OpEqual:
 		return vm.push(nativeBoolToBooleanObject(rightValue == leftValue))
@@ -429,6 +867,8 @@ func (vm *VM) executeIntegerComparison(op code.Opcode, left, right object.Object
 		return vm.push(nativeBoolToBooleanObject(rightValue != leftValue))
 	case code.OpGreater:
 		return vm.push(nativeBoolToBooleanObject(leftValue > rightValue))
+	case code.OpGreaterEqual:
+		return vm.push(nativeBoolToBooleanObject(leftValue >= rightValue))
 	default:
 		return fmt.Errorf("unknown operator: %d", op)
 	}
@@ -446,6 +886,64 @@ func (vm *VM) buildArray(startIndex, endIndex int) object.Object {
 	return &object.Array{Elements: elements}
 }
 
+// buildString joins what Inspect returns for each value on the stack
+// between startIndex and endIndex, it backs interpolated strings
+func (vm *VM) buildString(startIndex, endIndex int) object.Object {
+	var out strings.Builder
+	for i := startIndex; i < endIndex; i++ {
+		part := vm.stack[i]
+		decrypted, err := mutil.DecryptObject(part, vm.inslen)
+		if err == nil {
+			part = decrypted
+		}
+		out.WriteString(part.Inspect())
+	}
+	return &object.String{Value: out.String()}
+}
+
+// buildStruct builds a struct out of the field names and values
+// between startIndex and endIndex, the struct type sits right below them
+func (vm *VM) buildStruct(startIndex, endIndex int) (object.Object, error) {
+	def := vm.stack[startIndex-1]
+	if dec, err := mutil.DecryptObject(def, vm.inslen); err == nil {
+		def = dec
+	}
+	structType, ok := def.(*object.StructType)
+	if !ok {
+		return nil, fmt.Errorf("not a struct: %s", def.Type())
+	}
+
+	fields := []string{}
+	values := []object.Object{}
+	for i := startIndex; i < endIndex; i += 2 {
+		field := vm.stack[i]
+		if dec, err := mutil.DecryptObject(field, vm.inslen); err == nil {
+			field = dec
+		}
+		value := vm.stack[i+1]
+		if dec, err := mutil.DecryptObject(value, vm.inslen); err == nil {
+			value = dec
+		}
+		fields = append(fields, field.(*object.String).Value)
+		values = append(values, value)
+	}
+	return structType.New(fields, values, global.Null)
+}
+
+func (vm *VM) getField(left, field object.Object) (object.Object, error) {
+	name := field.(*object.String).Value
+	switch left := left.(type) {
+	case *object.Struct:
+		return left.Get(name)
+	case *object.EnumType:
+		return left.Variant(name)
+	case *object.EnumValue:
+		return left.Get(name)
+	default:
+		return nil, fmt.Errorf("field access not supported: %s", left.Type())
+	}
+}
+
 func (vm *VM) buildHash(startIndex, endIndex int) (object.Object, error) {
 	hashedPairs := make(map[object.HashKey]object.HashPair)
 	for i := startIndex; i < endIndex; i += 2 {
@@ -473,16 +971,22 @@ func (vm *VM) buildHash(startIndex, endIndex int) (object.Object, error) {
 }
 
 func (vm *VM) currentFrame() *Frame { return vm.frames[vm.frameIndex-1] }
-func (vm *VM) pushFrame(f *Frame) {
+func (vm *VM) pushFrame(f *Frame) error {
+	if vm.frameIndex >= global.MaxFrames {
+		return errMaxCallDepth
+	}
 	vm.frames[vm.frameIndex] = f
 	vm.frameIndex++
+	return nil
 }
 func (vm *VM) popFrame() *Frame {
 	vm.frameIndex--
 	return vm.frames[vm.frameIndex]
 }
 
-func (vm *VM) executeCall(numArgs int) error {
+// executeCall calls the function found below its numArgs arguments,
+// names holds the parameter names of the trailing named arguments
+func (vm *VM) executeCall(numArgs int, names []object.Object) error {
 	var callee object.Object
 	if vm.stack[vm.stackPointer-1-numArgs].Type() == object.CLOSURE_OBJ || vm.stack[vm.stackPointer-1-numArgs].Type() == object.BUILTIN_OBJ {
 		callee = vm.stack[vm.stackPointer-1-numArgs]
@@ -492,8 +996,16 @@ func (vm *VM) executeCall(numArgs int) error {
 
 	switch calleeType := callee.(type) {
 	case *object.Closure:
-		return vm.callClosure(calleeType, numArgs)
+		return vm.callClosure(calleeType, numArgs, names)
 	case *builtin.BuiltIn:
+		if len(names) > 0 {
+			return fmt.Errorf("builtin functions do not take named arguments")
+		}
+		if calleeType == nextBuiltin && (numArgs == 1 || numArgs == 2) {
+			if gen, ok := vm.stack[vm.stackPointer-numArgs].(*Generator); ok {
+				return vm.callNext(gen, numArgs)
+			}
+		}
 		return vm.callBuiltin(calleeType, numArgs)
 
 	default:
@@ -501,17 +1013,50 @@ func (vm *VM) executeCall(numArgs int) error {
 	}
 }
 
-func (vm *VM) callClosure(cl *object.Closure, numArgs int) error {
-	if numArgs != cl.Fn.NumParams {
-		return fmt.Errorf("wrong number of arguments. want=%d, got=%d", cl.Fn.NumParams, numArgs)
+func (vm *VM) callClosure(cl *object.Closure, numArgs int, names []object.Object) error {
+	bp := vm.stackPointer - numArgs
+	if len(names) > 0 || cl.Fn.Rest || numArgs != cl.Fn.NumParams {
+		params, err := vm.bindArguments(cl.Fn, vm.stack[bp:vm.stackPointer], names)
+		if err != nil {
+			return err
+		}
+		copy(vm.stack[bp:], params)
+	}
+
+	if bp+cl.Fn.NumLocals+callReserve >= global.StackSize {
+		return errMaxCallDepth
 	}
 
-	frame := NewFrame(cl, vm.stackPointer-numArgs)
-	vm.pushFrame(frame)
+	frame := NewFrame(cl, bp)
+	if err := vm.pushFrame(frame); err != nil {
+		return err
+	}
 	vm.stackPointer = frame.bp + cl.Fn.NumLocals
+	if cl.Fn.Generator {
+		return vm.startGenerator()
+	}
 	return nil
 }
 
+// tailCall runs a call in tail position. A closure takes over the frame
+// of the function calling it, its arguments move down over the locals
+// of the caller, so recursion in tail position runs in constant space.
+// Builtins are called as usual, the OpReturnValue after the call hands
+// their result back
+func (vm *VM) tailCall(numArgs int) error {
+	callee := vm.stackPointer - 1 - numArgs
+	if _, ok := vm.stack[callee].(*object.Closure); !ok {
+		return vm.executeCall(numArgs, nil)
+	}
+
+	frame := vm.popFrame()
+	vm.closeUpvalues(frame.bp)
+	base := frame.bp - 1
+	copy(vm.stack[base:], vm.stack[callee:vm.stackPointer])
+	vm.stackPointer = base + 1 + numArgs
+	return vm.executeCall(numArgs, nil)
+}
+
 func (vm *VM) callBuiltin(builtin *builtin.BuiltIn, numArgs int) error {
 	args := vm.stack[vm.stackPointer-numArgs : vm.stackPointer]
 	for i := range args {
@@ -521,6 +1066,9 @@ func (vm *VM) callBuiltin(builtin *builtin.BuiltIn, numArgs int) error {
 		}
 	}
 	result := builtin.Fn(args...)
+	if errObj, ok := result.(*object.Error); ok {
+		return errors.New(errObj.Message)
+	}
 
 	vm.stackPointer = vm.stackPointer - numArgs - 1
 
@@ -533,6 +1081,16 @@ func (vm *VM) callBuiltin(builtin *builtin.BuiltIn, numArgs int) error {
 	return nil
 }
 
+// matchValue reports whether value fits a literal pattern, unlike ==
+// it doesn't let 1 match 1.0 or "1"
+func matchValue(value, pattern object.Object) bool {
+	if value.Type() != pattern.Type() {
+		return false
+	}
+	hashable, ok := value.(object.Hashable)
+	return ok && hashable.HashKey() == pattern.(object.Hashable).HashKey()
+}
+
 func nativeBoolToBooleanObject(native bool) *object.Boolean {
 	if native {
 		return global.True
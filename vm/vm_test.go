@@ -2,9 +2,12 @@ package vm
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"zetsu/ast"
 	"zetsu/compiler"
+	"zetsu/errrs"
+	"zetsu/evaluator"
 	"zetsu/global"
 	"zetsu/lexer"
 	"zetsu/mutil"
@@ -74,6 +77,19 @@ func testIntegerObject(expected int64, actual object.Object) error {
 	return nil
 }
 
+func testFloatObject(expected float64, actual object.Object) error {
+	result, ok := actual.(*object.Float)
+	if !ok {
+		return fmt.Errorf("object is not Float. got=%T (%+v)", actual, actual)
+	}
+
+	if result.Value != expected {
+		return fmt.Errorf("object has wrong value. got=%g, want=%g", result.Value, expected)
+	}
+
+	return nil
+}
+
 func testBooleanObject(expected bool, actual object.Object) error {
 	result, ok := actual.(*object.Boolean)
 	if !ok {
@@ -107,6 +123,10 @@ func testExpectedObject(t *testing.T, expected interface{}, actual object.Object
 		if err := testIntegerObject(int64(expected), actual); err != nil {
 			t.Errorf("testIntegerObject failed: %s", err)
 		}
+	case float64:
+		if err := testFloatObject(expected, actual); err != nil {
+			t.Errorf("testFloatObject failed: %s", err)
+		}
 	case bool:
 		if err := testBooleanObject(bool(expected), actual); err != nil {
 			t.Errorf("testBooleanObject failed: %s", err)
@@ -187,6 +207,54 @@ func TestIntegerArithmatic(t *testing.T) {
 	runVMTests(t, tests)
 }
 
+func TestFloatArithmatic(t *testing.T) {
+	tests := []vmTestCase{
+		{"1.5", 1.5},
+		{"1.5 + 2.25", 3.75},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2.0},
+		{"7 / 2.0", 3.5},
+		{"7 / 2", 3},
+		{"-2.5", -2.5},
+		{"1e3 - 1", 999.0},
+		{"1.5 < 2", true},
+		{"2 > 1.5", true},
+		{"1 == 1.0", true},
+		{"1.0 != 1", false},
+		{"let avg = fn(a, b) { (a + b) / 2.0 }; avg(3, 4)", 3.5},
+		{"{1.5: 10}[1.5]", 10},
+	}
+	runVMTests(t, tests)
+}
+
+func TestComparisonAndLogicalOperators(t *testing.T) {
+	tests := []vmTestCase{
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"7.5 % 2", 1.5},
+		{"let x = 10; x %= 4; x", 2},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{"2.5 >= 2", true},
+		{"1 <= 0.5", false},
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 && 2", true},
+		{"0 < 1 && 2 > 1 || false", true},
+		{"let calls = 0; let f = fn() { calls += 1; true }; false && f(); calls", 0},
+		{"let calls = 0; let f = fn() { calls += 1; true }; true || f(); calls", 0},
+		{"let calls = 0; let f = fn() { calls += 1; true }; true && f(); calls", 1},
+		{"let n = 0; for (let i = 0; i < 20; i += 1) { if (i % 2 == 0 && i >= 10) { n += 1; } }; n", 5},
+	}
+	runVMTests(t, tests)
+}
+
 func TestBooleanExpressions(t *testing.T) {
 	tests := []vmTestCase{
 		{"true", true},
@@ -231,10 +299,135 @@ func TestConditionals(t *testing.T) {
 		{"if (1 > 2) { 10 }", global.Null},
 		{"if (false) { 10 }", global.Null},
 		{"if ((if (false) { 10 })) { 10 } else { 20 }", 20},
+		{"if (true) { }", global.Null},
+		{"if (false) { 10 } else { }", global.Null},
+	}
+	runVMTests(t, tests)
+}
+
+func TestLoops(t *testing.T) {
+	tests := []vmTestCase{
+		{"while (true) { break; }; 1", 1},
+		{"let first = fn(xs) { for x in xs { return x; } }; first([7, 8])", 7},
+		{"let f = fn(xs) { for x in xs { if (x < 3) { continue; } return x; } }; f([1, 2, 3, 4])", 3},
+		{"let f = fn() { for (;;) { if (true) { break; } }; 5 }; f()", 5},
+		{`let f = fn(s) { for c in s { if (c == "c") { return c; } } }; f("abc")`, "c"},
+		{"let i = 0; while (i < 10) { i = i + 1; }; i", 10},
+		{"let i = 0; while (false) { i = i + 1; }; i", 0},
+		{"let sum = 0; for (let i = 1; i < 5; i = i + 1) { sum = sum + i; }; sum", 10},
+		{"let sum = 0; for x in [1, 2, 3] { sum = sum + x; }; sum", 6},
+		{`let s = ""; for c in "abc" { s = c + s; }; s`, "cba"},
+		{`let s = ""; for k in {"b": 2, "a": 1} { s = s + k; }; s`, "ab"},
+		{"let i = 0; while (true) { i = i + 1; if (i == 3) { break; } }; i", 3},
+		{"let n = 0; for (let i = 0; i < 10; i = i + 1) { if (i > 4) { continue; } n = n + 1; }; n", 5},
+		{"let n = 0; for x in [1, 2, 3, 4] { if (x == 2) { continue; } if (x == 4) { break; } n = n + x; }; n", 4},
+		{"let n = 0; for (;;) { n = n + 1; if (n == 7) { break; } }; n", 7},
+		{"let n = 0; for x in [1, 2] { for y in [10, 20] { if (y == 20) { break; } n = n + x * y; } }; n", 30},
+		{"let count = fn(xs) { let n = 0; for x in xs { n = n + 1; }; n }; count([5, 6, 7])", 3},
+		{"let find = fn(xs) { for x in xs { if (x > 1) { return x; } } }; find([1, 2, 3])", 2},
+		{"let f = fn() { let i = 0; while (i < 3) { i = i + 1; } }; f()", global.Null},
+		{"let i = 0; while (i < 100000) { i = i + 1; }; i", 100000},
+	}
+	runVMTests(t, tests)
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []vmTestCase{
+		{"let x = 1; x = 2; x", 2},
+		{"let x = 1; x = x + 1", 2},
+		{"let x = 10; x += 5; x -= 3; x *= 2; x /= 4; x", 6},
+		{"let x = 0; let y = 0; x = y = 3; x + y", 6},
+		{"let x = 1.5; x += 1; x", 2.5},
+		{`let s = "a"; s += "b"; s`, "ab"},
+		{"let x = 1; let x = x + 1; x", 2},
+		{"let f = fn() { let x = 1; x += 41; x }; f()", 42},
+		{"let counter = fn() { let n = 0; fn() { n += 1 } }; let c = counter(); c(); c(); c()", 3},
+		{"let x = 1; let f = fn() { x = 7 }; f(); x", 7},
+		{"let x = 5; match (3) { x => 0 }; x", 5},
+		{"let x = 5; match ([1, 2]) { [x, y] => x + y }; x", 5},
+		{"let x = 5; for x in [1, 2] { }; x", 5},
+		{"let e = 1; try { throw \"x\" } catch (e) { 0 }; e", 1},
+		{"let f = fn() { let x = 5; for x in [1, 2] { x += 10 }; x }; f()", 5},
+		{"let y = 0; for x in [1, 2] { y = x }; y", 2},
+		{"let y = 0; match (4) { x => y = x }; y", 4},
+	}
+	runVMTests(t, tests)
+}
+
+func TestIndexAssignments(t *testing.T) {
+	tests := []vmTestCase{
+		{"let a = [1, 2, 3]; a[1] = 5; a", []int{1, 5, 3}},
+		{"let a = [1, 2, 3]; a[-1] = 9; a", []int{1, 2, 9}},
+		{"let a = [1, 2, 3]; a[0] = 7", 7},
+		{"let a = [1, 2, 3]; a[2] += 10; a[2]", 13},
+		{"let a = [1, 2]; a[-1] += 2; a", []int{1, 4}},
+		{`let a = [1, 2]; try { a[-3] += 2 } catch (e) { e["message"] }`, "index out of range: -3 (length 2)"},
+		{`let h = {}; h["k"] = 1; h["k"]`, 1},
+		{`let h = {"k": 2}; h["k"] *= 21; h["k"]`, 42},
+		{"let a = [[0], [0]]; a[1][0] = 4; a[1]", []int{4}},
+		{"let a = [0]; let b = a; b[0] = 3; a[0]", 3},
+		{"let f = fn(xs) { xs[0] = 1 }; let a = [0]; f(a); a[0]", 1},
+		{
+			"let h = {}; for (let i = 0; i < 10000; i += 1) { h[i] = i * 2; }; h[9999]",
+			19998,
+		},
 	}
 	runVMTests(t, tests)
 }
 
+func TestIndexAssignmentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = [1]; a[1] = 2;", "index out of range: 1 (length 1)"},
+		{"let a = [1]; a[-2] = 2;", "index out of range: -2 (length 1)"},
+		{`let a = [1]; a["x"] = 2;`, "array index must be INTEGER, got STRING"},
+		{`let h = {}; h[[1]] = 2;`, "unusable as hash key: ARRAY"},
+		{`let s = "abc"; s[0] = "x";`, "index assignment not supported: STRING"},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		comp := compiler.New()
+		if err := comp.Compile(program); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(mutil.EncryptByteCode(comp.ByteCode()))
+		err := vm.Run()
+		if err == nil {
+			t.Fatalf("expected VM error for %q but resulted in none.", tt.input)
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong VM error for %q. want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestLoopOverLongString(t *testing.T) {
+	input := `
+	let a = "xxxxxxxx";
+	let b = a + a + a + a + a + a + a + a;
+	let c = b + b + b + b + b + b + b + b;
+	let d = c + c + c + c + c + c + c + c;
+	let e = d + d + d + d + d + d + d + d;
+	let f = e + e + e + e + e + e + e + e;
+	let find = fn(xs) { for x in xs { if (x == "y") { return x; } } };
+	find(f + "y")`
+	runVMTests(t, []vmTestCase{{input, "y"}})
+}
+
+func TestLoopOverLargeCollection(t *testing.T) {
+	input := `
+	let s = "x";
+	for (let i = 0; i < 17; i = i + 1) { s = s + s; }
+	let n = 0;
+	for c in s { n = n + 1; }
+	n`
+	runVMTests(t, []vmTestCase{{input, 131072}})
+}
+
 func TestGlobalLetStatements(t *testing.T) {
 	tests := []vmTestCase{
 		{"let one = 1; one", 1},
@@ -253,6 +446,29 @@ func TestStringExpressions(t *testing.T) {
 	runVMTests(t, tests)
 }
 
+func TestStringEscapes(t *testing.T) {
+	tests := []vmTestCase{
+		{`"a\tb"`, "a\tb"},
+		{`"line\n" + "next"`, "line\nnext"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"\u{48}\u{49}"`, "HI"},
+		{"`C:\\dir\\n`", `C:\dir\n`},
+		{"`two\nlines`", "two\nlines"},
+	}
+	runVMTests(t, tests)
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []vmTestCase{
+		{`let count = 3; let price = 5; "total: ${count * price}"`, "total: 15"},
+		{`"${1.5} ${true} ${"nested ${1 + 1}"}"`, "1.5 true nested 2"},
+		{`"${[1, 2]}"`, "[1, 2]"},
+		{`let f = fn(name) { "hi ${name}!" }; f("you")`, "hi you!"},
+		{`"cost: \${x}"`, "cost: ${x}"},
+	}
+	runVMTests(t, tests)
+}
+
 func TestArrayLiterals(t *testing.T) {
 	tests := []vmTestCase{
 		{"[]", []int{}},
@@ -291,6 +507,7 @@ func TestIndexExpressions(t *testing.T) {
 		{"[][0]", global.Null},
 		{"[1, 2, 3][99]", global.Null},
 		{"[1][-1]", 1},
+		{"[1, 2][-5]", global.Null},
 		{"{1: 1, 2: 2}[1]", 1},
 		{"{1: 1, 2: 2}[2]", 2},
 		{"{1: 1}[0]", global.Null},
@@ -301,6 +518,112 @@ func TestIndexExpressions(t *testing.T) {
 	runVMTests(t, tests)
 }
 
+func TestSliceAndRangeExpressions(t *testing.T) {
+	tests := []vmTestCase{
+		{"[1, 2, 3, 4][1:3]", []int{2, 3}},
+		{"[1, 2, 3, 4][:2]", []int{1, 2}},
+		{"[1, 2, 3, 4][2:]", []int{3, 4}},
+		{"[1, 2, 3, 4][-3:-1]", []int{2, 3}},
+		{"[1, 2, 3, 4][3:1]", []int{}},
+		{"let a = [1, 2, 3]; let b = a[:]; b[0] = 9; a[0]", 1},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[-3:]`, "llo"},
+		{"let n = 2; [1, 2, 3][n:][0]", 3},
+		{"len(1..10)", 9},
+		{"(1..10)[2]", 3},
+		{"(1..10)[-1]", 9},
+		{"(1..10)[20]", global.Null},
+		{`"${1..4}"`, "1..4"},
+		{`"${range(0, 10, 3)}"`, "range(0, 10, 3)"},
+		{`"${(0..10)[2:5]}"`, "2..5"},
+		{"let s = 0; for i in 1..5 { s += i }; s", 10},
+		{"let s = 0; for i in range(10, 0, -2) { s += i }; s", 30},
+		{"let s = 0; for i in range(4) { s += i }; s", 6},
+		{"let n = 3; let s = 0; for i in 0..n + 1 { s += i }; s", 6},
+		{"let s = 0; for i in 0..1000000000 { if (i == 3) { break }; s += i }; s", 3},
+		{"try { [1][\"a\":] } catch (e) { e[\"message\"] }", "slice bounds must be INTEGER, got STRING"},
+		{"try { 1[1:] } catch (e) { e[\"message\"] }", "slice operator not supported: INTEGER"},
+		{"try { 1..\"a\" } catch (e) { e[\"message\"] }", "range bounds must be INTEGER, got STRING"},
+		{"try { range(1, 2, 0) } catch (e) { e[\"message\"] }", "range step cannot be zero"},
+	}
+	runVMTests(t, tests)
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	tests := []vmTestCase{
+		{`len("héllo")`, 5},
+		{`len("日本語")`, 3},
+		{`len("😀")`, 1},
+		{`"日本語"[1]`, "本"},
+		{`"日本語"[-1]`, "語"},
+		{`"日本語"[3]`, global.Null},
+		{`"日本語"[-4]`, global.Null},
+		{`"héllo wörld"[1:4]`, "éll"},
+		{`"héllo wörld"[-5:]`, "wörld"},
+		{`let s = ""; for c in "añb" { s = c + s }; s`, "bña"},
+		{`let 名前 = "Zoë"; "こんにちは ${名前}!"`, "こんにちは Zoë!"},
+		{`{"ключ": 1}["ключ"]`, 1},
+		{`"\u{1F600}" == "😀"`, true},
+	}
+	runVMTests(t, tests)
+}
+
+func TestConstants(t *testing.T) {
+	tests := []vmTestCase{
+		{`const x = 5; x * 2`, 10},
+		{`const s = "a"; fn() { s + "b" }()`, "ab"},
+		{`const neg = -1.5; neg * 2`, -3.0},
+		{`const f = fn(n) { n + 1 }; f(1)`, 2},
+		{`const x = 1; let g = fn() { let x = 2; x }; g() + x`, 3},
+		{`let make = fn() { const xs = [1, 2]; fn() { xs } }; make()()[1]`, 2},
+	}
+	runVMTests(t, tests)
+}
+
+func TestEnums(t *testing.T) {
+	tests := []vmTestCase{
+		{`enum Status { Pending, Done }; "${Status.Done}"`, "Status.Done"},
+		{`enum Status { Pending, Done }; "${Status}"`, "enum Status { Pending, Done }"},
+		{`enum Status { Pending, Done }; Status.Done == Status.Done`, true},
+		{`enum Status { Pending, Done }; Status.Done != Status.Pending`, true},
+		{`enum Status { Pending, Done }; Status.Done == "Status.Done"`, false},
+		{`enum Status { Pending, Done }; Status.Done.ordinal`, 1},
+		{`enum Status { Pending, Done }; Status.Pending.name`, "Pending"},
+		{`enum Status { Pending, Done }; let s = Status; s.Done == Status.Done`, true},
+		{`enum Status { Pending, Done }; {Status.Pending: "p", Status.Done: "d"}[Status.Done]`, "d"},
+		{`enum Status { Pending, Done }; let f = fn(s) { match (s) { Status.Pending => 1, Status.Done => 2 } }; f(Status.Done)`, 2},
+		{`enum Status { Pending, Done }; let s = Status; try { s.Lost } catch (e) { e["message"] }`, "Status has no variant Lost"},
+	}
+	runVMTests(t, tests)
+}
+
+func TestBigIntegers(t *testing.T) {
+	tests := []vmTestCase{
+		{`"${9223372036854775807 + 1}"`, "9223372036854775808"},
+		{`"${-9223372036854775807 - 2}"`, "-9223372036854775809"},
+		{`"${4294967296 * 4294967296 * 4294967296}"`, "79228162514264337593543950336"},
+		{`let m = -9223372036854775807 - 1; "${-m}"`, "9223372036854775808"},
+		{`let m = -9223372036854775807 - 1; "${m / -1}"`, "9223372036854775808"},
+		{`9223372036854775807 + 1 - 1`, 9223372036854775807},
+		{`(9223372036854775807 + 10) % 7`, 3},
+		{`9223372036854775807 + 1 > 9223372036854775807`, true},
+		{`9223372036854775807 + 1 == 9223372036854775807 + 1`, true},
+		{`9223372036854775807 + 1 != 9223372036854775807`, true},
+		{`(9223372036854775807 + 1) * 0.5`, 4611686018427387904.0},
+		{`let h = {9223372036854775807 + 1: "big"}; h[9223372036854775807 + 1]`, "big"},
+		{`try { 1 / 0 } catch (e) { e["message"] }`, "division by zero"},
+		{`try { 7 % 0 } catch (e) { e["message"] }`, "division by zero"},
+		{`try { (9223372036854775807 + 1) / 0 } catch (e) { e["message"] }`, "division by zero"},
+		{`let n = 99999999999999999999; "${n}"`, "99999999999999999999"},
+		{`99999999999999999999 - 99999999999999999998`, 1},
+		{`-9223372036854775808`, -9223372036854775808},
+		{`const n = 99999999999999999999; "${n + 1}"`, "100000000000000000000"},
+		{`const n = -9223372036854775808; n - 1 < n`, true},
+		{`18446744073709551616 == 9223372036854775807 * 2 + 2`, true},
+	}
+	runVMTests(t, tests)
+}
+
 func TestCallingFunctionsWithoutArgument(t *testing.T) {
 	tests := []vmTestCase{
 		{
@@ -446,11 +769,35 @@ func TestCallingFunctionsWithArgumentsAndBindings(t *testing.T) {
 	runVMTests(t, tests)
 }
 
+func TestFunctionArguments(t *testing.T) {
+	tests := []vmTestCase{
+		{"let add = fn(a, b = 10) { a + b }; add(1)", 11},
+		{"let add = fn(a, b = 10) { a + b }; add(1, 2)", 3},
+		{"let add = fn(a, b = 10) { a + b }; add(1, b: 3)", 4},
+		{"let add = fn(a, b = 10) { a + b }; add(b: 3, a: 2)", 5},
+		{"let f = fn(a, b = a * 2) { b }; f(4)", 8},
+		{"let f = fn(a = 1, b = 2, c = 3) { a * 100 + b * 10 + c }; f(c: 9)", 129},
+		{"let f = fn(a, ...rest) { rest }; f(1, 2, 3)", []int{2, 3}},
+		{"let f = fn(a, ...rest) { len(rest) }; f(1)", 0},
+		{"let f = fn(...xs) { let s = 0; for x in xs { s += x }; s }; f(1, 2, 3, 4)", 10},
+		{"let f = fn(a, b = 2, ...rest) { a + b + len(rest) }; f(1, b: 5)", 6},
+		{"let n = 5; let f = fn(x = n) { x }; f()", 5},
+		{"let g = fn() { let f = fn(a, b = a + 1) { a + b }; f(1) + f(1, 1) }; g()", 5},
+		{"let count = fn(n, acc = 0) { if (n == 0) { return acc }; count(n - 1, acc: acc + 1) }; count(20)", 20},
+	}
+	runVMTests(t, tests)
+}
+
 func TestCallingFunctionsWithWrongArguments(t *testing.T) {
 	tests := []vmTestCase{
-		{input: "fn() { 1; }(1);", expected: "wrong number of arguments. want=0, got=1"},
-		{input: "fn(a) { a; }();", expected: "wrong number of arguments. want=1, got=0"},
-		{input: "fn(a, b) { a + b; }(1);", expected: "wrong number of arguments. want=2, got=1"},
+		{input: "fn() { 1; }(1);", expected: "wrong number of arguments to fn. want=0, got=1"},
+		{input: "fn(a) { a; }();", expected: "fn is missing an argument for parameter a"},
+		{input: "fn(a, b) { a + b; }(1);", expected: "fn is missing an argument for parameter b"},
+		{input: "let add = fn(a, b = 1) { a + b; }; add(1, 2, 3);", expected: "wrong number of arguments to add. want=2, got=3"},
+		{input: "let add = fn(a, b = 1) { a + b; }; add(b: 2);", expected: "add is missing an argument for parameter a"},
+		{input: "let add = fn(a, b = 1) { a + b; }; add(1, c: 2);", expected: "add has no parameter named c"},
+		{input: "let add = fn(a, b = 1) { a + b; }; add(1, a: 2);", expected: "add got more than one value for parameter a"},
+		{input: "len(a: [1]);", expected: "builtin functions do not take named arguments"},
 	}
 
 	for _, tt := range tests {
@@ -481,20 +828,20 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
-		{`len(1)`, &object.Error{Message: "argument to `len` not supported, got INTEGER"}},
-		{`len("one", "two")`, &object.Error{Message: "wrong number of arguments. got=2, want=1"}},
+		{`try { len(1) } catch (e) { e }`, &object.Error{Message: "argument to `len` not supported, got INTEGER"}},
+		{`try { len("one", "two") } catch (e) { e }`, &object.Error{Message: "wrong number of arguments. got=2, want=1"}},
 		{`len([])`, 0},
 		{`puts("hello", "world!")`, global.Null},
 		{`first([1, 2, 3])`, 1},
 		{`first([])`, global.Null},
-		{`first(1)`, &object.Error{Message: "argument to `first` must be ARRAY, got INTEGER"}},
+		{`try { first(1) } catch (e) { e }`, &object.Error{Message: "argument to `first` must be ARRAY, got INTEGER"}},
 		{`last([1, 2, 3])`, 3},
 		{`last([])`, global.Null},
-		{`last(1)`, &object.Error{Message: "argument to `last` must be ARRAY, got INTEGER"}},
+		{`try { last(1) } catch (e) { e }`, &object.Error{Message: "argument to `last` must be ARRAY, got INTEGER"}},
 		{`rest([1, 2, 3])`, []int{2, 3}},
 		{`rest([])`, global.Null},
 		{`push([], 1)`, []int{1}},
-		{`push(1, 1)`, &object.Error{Message: "argument to `push` must be ARRAY, got=INTEGER"}},
+		{`try { push(1, 1) } catch (e) { e }`, &object.Error{Message: "argument to `push` must be ARRAY, got=INTEGER"}},
 		{`puts("four")`, global.Null},
 		{`putln("four")`, global.Null},
 	}
@@ -533,6 +880,53 @@ func TestClosures(t *testing.T) {
 	runVMTests(t, tests)
 }
 
+func TestUpvalues(t *testing.T) {
+	tests := []vmTestCase{
+		// the function that defines a variable sees the closure update it
+		{"let f = fn() { let n = 0; let inc = fn() { n += 1 }; inc(); inc(); n }; f()", 2},
+		{"let f = fn() { let n = 0; let get = fn() { n }; n = 5; get() }; f()", 5},
+		// closures capturing the same variable share it, before and after
+		// the function defining it returns
+		{`
+		let pair = fn() { let n = 0; [fn() { n += 1 }, fn() { n }] };
+		let p = pair(); p[0](); p[0](); p[0](); p[1]()`, 3},
+		{"let f = fn() { let n = 1; let g = fn() { fn() { n *= 10 } }; g()(); g()(); n }; f()", 100},
+		{"let counter = fn() { let n = 0; fn() { n += 1 } }; let a = counter(); let b = counter(); a(); a(); b(); [a(), b()]", []int{3, 2}},
+		// every iteration of a loop gets variables of its own
+		{`
+		let make = fn() { let fns = []; for i in 0..3 { fns = push(fns, fn() { i }) }; fns };
+		let fns = make(); [fns[0](), fns[1](), fns[2]()]`, []int{0, 1, 2}},
+		{`
+		let make = fn() { let fns = []; let i = 0; while (i < 3) { let j = i; fns = push(fns, fn() { j * 10 }); i += 1 }; fns };
+		let fns = make(); [fns[0](), fns[1](), fns[2]()]`, []int{0, 10, 20}},
+		{`
+		let make = fn() { let fns = []; for (let i = 0; i < 3; i += 1) { let j = i; if (j == 1) { continue }; fns = push(fns, fn() { j }) }; fns };
+		let fns = make(); [fns[0](), fns[1]()]`, []int{0, 2}},
+		// at global scope too
+		{"let fs = []; for i in 0..3 { fs = push(fs, fn() { i }) }; [fs[0](), fs[1](), fs[2]()]", []int{0, 1, 2}},
+		{"let fs = []; for i in 0..2 { fs = push(fs, fn() { i }); i *= 10 }; [fs[0](), fs[1]()]", []int{0, 10}},
+		{"let fs = []; for i in 0..2 { let inc = fn() { i += 5 }; inc(); fs = push(fs, fn() { i }) }; [fs[0](), fs[1]()]", []int{5, 6}},
+		{"let fs = []; for v in [1, 2] { match (v) { x => fs = push(fs, fn() { x }) } }; [fs[0](), fs[1]()]", []int{1, 2}},
+		{`let fs = []; for v in [1, 2] { try { throw v } catch (e) { fs = push(fs, fn() { e["message"] }) } }; fs[0]() + fs[1]()`, "12"},
+		// locals are closed when a try unwinds their frame
+		{`
+		let f = fn() { let n = 1; let g = fn() { n }; try { let h = fn() { let m = 2; g = fn() { m + n }; throw "x" }; h() } catch (e) { g() } }; f()`, 3},
+		// a tail call closes the variables of the caller before reusing its slots
+		{"let id = fn(x) { x }; let f = fn(a) { let g = fn() { a }; id(g) }; f(7)()", 7},
+		// the variables of a generator are shared across yields
+		{`
+		let g = fn*() { let n = 0; let inc = fn() { n += 1 }; yield inc; yield n };
+		let it = g(); let inc = next(it); inc(); inc(); next(it)`, 2},
+		{`
+		let g = fn*() { let n = 0; yield fn() { n }; n = 9; yield 0 };
+		let it = g(); let get = next(it); next(it); get()`, 9},
+		// a spawned closure gets a copy of what it captured
+		{"let f = fn() { let n = 1; let t = spawn fn() { n += 1; n }; [wait(t), n] }; f()", []int{2, 1}},
+	}
+
+	runVMTests(t, tests)
+}
+
 func TestRecursiveFunctions(t *testing.T) {
 	tests := []vmTestCase{
 		{input: "let countDown = fn(x) { if (x == 0) { return 0; } else { countDown(x - 1); } }; countDown(1);", expected: 0},
@@ -567,6 +961,139 @@ func TestRecursiveFunctions(t *testing.T) {
 	runVMTests(t, tests)
 }
 
+func TestTailCalls(t *testing.T) {
+	tests := []vmTestCase{
+		{"let count = fn(n, acc) { if (n == 0) { return acc }; count(n - 1, acc + 1) }; count(100000, 0)", 100000},
+		{"let count = fn(n, acc) { if (n == 0) { acc } else { count(n - 1, acc + 1) } }; count(100000, 0)", 100000},
+		{"let count = fn(n) { match (n) { 0 => \"done\", _ => count(n - 1) } }; count(100000)", "done"},
+		{`
+		let odd = 0;
+		let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } };
+		odd = fn(n) { if (n == 0) { false } else { even(n - 1) } };
+		even(100001)
+		`, false},
+		{"let sum = fn(xs, i = 0, acc = 0) { if (i == len(xs)) { return acc }; sum(xs, i + 1, acc + xs[i]) }; sum([1, 2, 3])", 6},
+		{"let f = fn(x) { len(x) }; f([1, 2])", 2},
+		{"let deep = fn(n) { if (n == 0) { 0 } else { 1 + deep(n - 1) } }; try { deep(100000) } catch (e) { e[\"message\"] }", "maximum call depth exceeded"},
+	}
+	runVMTests(t, tests)
+}
+
+func TestGenerators(t *testing.T) {
+	tests := []vmTestCase{
+		{"let g = fn*() { yield 1; yield 2 }; let it = g(); next(it) + next(it)", 3},
+		{"let it = fn*() { yield 1 }(); next(it); next(it)", global.Null},
+		{"let it = fn*() { yield 1; return 5 }(); next(it); next(it); next(it)", global.Null},
+		{"let x = 0; let g = fn*() { x = 1; yield 2 }; let it = g(); x", 0},
+		{"let g = fn*(a, b = 2) { yield a + b }; next(g(1))", 3},
+		{"let count = fn*(n) { let i = 0; while (i < n) { yield i; i += 1 } }; let s = 0; for x in count(5) { s += x }; s", 10},
+		{"let nat = fn*() { let i = 0; while (true) { yield i; i += 1 } }; let s = 0; for n in nat() { if (n > 3) { break }; s += n }; s", 6},
+		{"let nat = fn*() { let i = 0; while (true) { yield i; i += 1 } }; let s = 0; for n in nat() { if (n == 100000) { break }; s += n }; s", 4999950000},
+		{"let acc = fn*() { let total = 0; while (true) { let v = yield total; total += v } }; let g = acc(); next(g); next(g, 5); next(g, 10)", 15},
+		{"let g = fn*() { yield 100 + (yield 1) }; let it = g(); next(it); next(it, 5)", 105},
+		{`let it = fn*() { yield 1 }(); "${it}"`, "Generator[suspended]"},
+		{`let it = fn*() { yield 1 }(); next(it); "${it}"`, "Generator[suspended]"},
+		{`let it = fn*() { yield 1 }(); next(it); next(it); "${it}"`, "Generator[done]"},
+		{`let it = 0; let g = fn*() { yield "${it}" }; it = g(); next(it)`, "Generator[running]"},
+		{"let inner = fn*() { yield 1; yield 2 }; let outer = fn*() { for x in inner() { yield x * 10 } }; let s = 0; for y in outer() { s += y }; s", 30},
+		{`let g = fn*() { try { yield 1; throw "boom" } catch (e) { yield e["message"] } }; let it = g(); next(it); let f = fn(a, b, c) { next(it) }; f(1, 2, 3)`, "boom"},
+		{`let g = fn*() { yield 1; throw "x"; yield 2 }; let it = g(); next(it); try { next(it) } catch (e) { 0 }; next(it)`, global.Null},
+		{`let it = 0; let g = fn*() { yield next(it) }; it = g(); try { next(it) } catch (e) { e["message"] }`, "generator is already running"},
+		{`try { next([1]) } catch (e) { e["message"] }`, "argument to `next` must be GENERATOR, got ARRAY"},
+	}
+	runVMTests(t, tests)
+}
+
+func TestSpawn(t *testing.T) {
+	tests := []vmTestCase{
+		{"let t = spawn fn() { 1 + 2 }; wait(t)", 3},
+		{"let add = fn(a, b) { a + b }; wait(spawn add(1, 2))", 3},
+		{"wait(spawn len([1, 2, 3]))", 3},
+		{"let ch = channel(); spawn fn() { send(ch, 5) }; receive(ch)", 5},
+		{"let ch = channel(2); send(ch, 1); send(ch, 2); close(ch); [receive(ch), receive(ch)]", []int{1, 2}},
+		{"let ch = channel(1); close(ch); receive(ch)", global.Null},
+		{`
+		let work = fn(id, out) { send(out, id * id) };
+		let out = channel();
+		for i in 0..8 { spawn work(i, out) };
+		let total = 0;
+		for i in 0..8 { total += receive(out) };
+		total`, 140},
+		{`
+		let ch = channel();
+		spawn fn() { for i in 1..4 { send(ch, i) }; close(ch) };
+		let total = 0;
+		for x in ch { total += x };
+		total`, 6},
+		{`
+		let fib = fn(n) { if (n < 2) { return n }; fib(n - 1) + fib(n - 2) };
+		let tasks = [];
+		for i in 0..4 { tasks = push(tasks, spawn fib(15)) };
+		let total = 0;
+		for t in tasks { total += wait(t) };
+		total`, 2440},
+		{"let x = 1; let t = spawn fn() { x = 2; x }; [wait(t), x]", []int{2, 1}},
+		{"let x = 1; let t = spawn fn() { x }; x = 5; wait(t)", 1},
+		{"let t = spawn fn() { let y = 3; wait(spawn fn() { y * 2 }) }; wait(t)", 6},
+		{"let a = [0]; let t = spawn fn() { a[0] = 1; a[0] }; [wait(t), a[0]]", []int{1, 0}},
+		{"let f = fn(b) { b[0] = 2; b[0] }; let a = [1]; [wait(spawn f(a)), a[0]]", []int{2, 1}},
+		{"let a = [1]; let h = {\"x\": a}; wait(spawn fn() { a[0] = 5; h[\"x\"][0] })", 5},
+		{"let a = [0]; a[0] = a; wait(spawn fn() { len(a[0][0]) })", 1},
+		{`
+		let h = {};
+		let ts = [];
+		for i in 0..8 { ts = push(ts, spawn fn() { for j in 0..20000 { h[j] = i } }) };
+		for t in ts { wait(t) };
+		h[0]`, global.Null},
+		{"let a = channel(); let b = channel(1); send(b, 7); select([a, b])", []int{1, 7}},
+		{"let a = channel(); close(a); select([a])[1]", global.Null},
+		{`let t = spawn fn() { throw "boom" }; try { wait(t) } catch (e) { e["message"] }`, "boom"},
+		{`let t = spawn fn() { 1 / 0 }; try { wait(t) } catch (e) { e["message"] }`, "division by zero"},
+		{`let ch = channel(); close(ch); try { send(ch, 1) } catch (e) { e["message"] }`, "send on closed channel"},
+		{`let ch = channel(); close(ch); try { close(ch) } catch (e) { e["message"] }`, "close of closed channel"},
+		{`try { spawn 1 } catch (e) { e["message"] }`, "cannot spawn INTEGER"},
+		{`try { channel(-1) } catch (e) { e["message"] }`, "channel size cannot be negative"},
+		{`try { select([1]) } catch (e) { e["message"] }`, "select can only wait on CHANNEL, got INTEGER"},
+		{`try { wait(1) } catch (e) { e["message"] }`, "argument to `wait` must be TASK, got INTEGER"},
+		{`try { receive(channel()) } catch (e) { e["message"] }`, "deadlock: every task is blocked"},
+		{`try { send(channel(), 1) } catch (e) { e["message"] }`, "deadlock: every task is blocked"},
+		{`try { select([channel(), channel()]) } catch (e) { e["message"] }`, "deadlock: every task is blocked"},
+		{`try { for x in channel() { x } } catch (e) { e["message"] }`, "deadlock: every task is blocked"},
+		{`
+		let t = spawn fn() { try { receive(channel()) } catch (e) { e["message"] } };
+		try { wait(t) } catch (e) { wait(t) }`, "deadlock: every task is blocked"},
+	}
+	runVMTests(t, tests)
+}
+
+// repeat joins format filled in with 0 to n-1, sep goes between them
+func repeat(n int, format, sep string) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = fmt.Sprintf(format, i)
+	}
+	return strings.Join(parts, sep)
+}
+
+func TestWideOperands(t *testing.T) {
+	locals := repeat(300, "let v%[1]d = %[1]d", "; ")
+	// every statement adds a constant, so there are more than 65536
+	// of them and more than 64KB of instructions to jump over
+	block := repeat(70000, "x = %d", "; ")
+
+	tests := []vmTestCase{
+		{"fn() { " + locals + "; v0 + v150 + v299 }()", 449},
+		{"fn() { " + locals + "; fn() { " + repeat(300, "v%d", " + ") + " } }()()", 44850},
+		{"fn(" + repeat(300, "a%d", ", ") + ") { a299 - a1 }(" + repeat(300, "%d", ", ") + ")", 298},
+		{"let x = 0; if (x == 0) { " + block + " }; x", 69999},
+		{"let x = 0; if (x == 1) { " + block + " } else { x = -1 }; x", -1},
+		{"let f = fn(n) { let x = 0; let i = 0; while (i < n) { " + block + "; i += 1 }; x + i }; f(2)", 70001},
+		{"let g = fn(x) { x * 2 }; let f = fn(x) { if (x > 0) { " + block + " }; g(x) }; f(1)", 139998},
+		{`let x = 0; try { ` + block + `; throw "far" } catch (e) { e["message"] }`, "far"},
+	}
+	runVMTests(t, tests)
+}
+
 func TestRecursiveFibonacci(t *testing.T) {
 	tests := []vmTestCase{
 		{
@@ -590,3 +1117,236 @@ func TestRecursiveFibonacci(t *testing.T) {
 
 	runVMTests(t, tests)
 }
+
+func TestErrorLocations(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"let a = 1;\nlet b = a + \"x\";", 2, 9},
+		{"let f = fn(a) { a };\n\n  f(1, 2);", 3, 3},
+		{"let f = fn() { -\"x\" };\nf();", 1, 16},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		comp := compiler.New()
+		if err := comp.Compile(program); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		byteCode := mutil.EncryptByteCode(comp.ByteCode())
+		vm := New(byteCode)
+
+		err := vm.Run()
+		if err == nil {
+			t.Fatalf("expected VM error but resulted in none.")
+		}
+
+		diag, ok := err.(*errrs.Diagnostic)
+		if !ok {
+			t.Fatalf("VM error is not a diagnostic. got=%T (%s)", err, err)
+		}
+
+		start := diag.Span.Start
+		if start.Line != tt.line || start.Column != tt.column {
+			t.Errorf("wrong error position for %q. want=%d:%d, got=%s", tt.input, tt.line, tt.column, start)
+		}
+	}
+}
+
+func TestMacros(t *testing.T) {
+	tests := []vmTestCase{
+		{`let double = macro(x) { quote(unquote(x) * 2) }; double(5 + 1)`, 12},
+		{`
+		let unless = macro(cond, cons, alt) {
+			quote(if (!(unquote(cond))) { unquote(cons) } else { unquote(alt) })
+		};
+		unless(10 > 5, "not greater", "greater")
+		`, "greater"},
+		{`let swap = macro(a, b) { quote(unquote(b) - unquote(a)) }; let f = fn(x) { swap(x, 10) }; f(3)`, 7},
+		{`let m = macro() { quote(unquote("a" + "b")) }; m()`, "ab"},
+		{`let m = macro() { quote(unquote([1, 2, 3])[1]) }; m()`, 2},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+
+		macroEnv := object.NewEnvironment()
+		evaluator.DefineMacros(program, macroEnv)
+		expanded, err := evaluator.ExpandMacros(program, macroEnv)
+		if err != nil {
+			t.Fatalf("macro error: %s", err)
+		}
+
+		comp := compiler.New()
+		if err := comp.Compile(expanded); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(mutil.EncryptByteCode(comp.ByteCode()))
+		if err := vm.Run(); err != nil {
+			t.Fatalf("vm error: %s", err)
+		}
+
+		testExpectedObject(t, tt.expected, vm.LastPoppedStackElement())
+	}
+}
+
+func TestModules(t *testing.T) {
+	lib := parser.New(lexer.NewFile(`
+	let prefix = "<";
+	let wrap = fn(s) { prefix + s + ">" };
+	export let greet = fn(name) { wrap("hi " + name) };
+	export let fail = fn() { 1 + "a" };
+	export const limit = 3;
+	`, "lib.zeta")).ParseProgram()
+
+	tests := []vmTestCase{
+		{`import "lib.zeta" as l; let prefix = "main"; l.greet("you")`, "<hi you>"},
+		{`import "lib.zeta" as l; let f = fn() { l.greet }; f()("x")`, "<hi x>"},
+		{`import "lib.zeta" as l; l.limit * 2`, 6},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		modules := []*compiler.Module{
+			{Path: "lib.zeta", Program: lib},
+			{Program: parse(tt.input), Imports: map[string]string{"lib.zeta": "lib.zeta"}},
+		}
+		for _, m := range modules {
+			if err := comp.CompileModule(m); err != nil {
+				t.Fatalf("compiler error: %s", err)
+			}
+		}
+
+		vm := New(mutil.EncryptByteCode(comp.ByteCode()))
+		if err := vm.Run(); err != nil {
+			t.Fatalf("vm error: %s", err)
+		}
+		testExpectedObject(t, tt.expected, vm.LastPoppedStackElement())
+	}
+
+	comp := compiler.New()
+	comp.CompileModule(&compiler.Module{Path: "lib.zeta", Program: lib})
+	comp.CompileModule(&compiler.Module{Program: parse(`import "lib.zeta" as l; l.fail()`), Imports: map[string]string{"lib.zeta": "lib.zeta"}})
+
+	err := New(mutil.EncryptByteCode(comp.ByteCode())).Run()
+	diag, ok := err.(*errrs.Diagnostic)
+	if !ok {
+		t.Fatalf("VM error is not a diagnostic. got=%T (%v)", err, err)
+	}
+	if diag.Span.File != "lib.zeta" || diag.Span.Start.Line != 5 {
+		t.Errorf("wrong error location. got=%s:%s", diag.Span.File, diag.Span.Start)
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	tests := []vmTestCase{
+		{`try { 1 } catch (e) { 2 }`, 1},
+		{`try { throw "boom"; 1 } catch (e) { e["message"] }`, "boom"},
+		{`try { throw 42 } catch (e) { e["message"] }`, "42"},
+		{`try { throw "boom" } catch { 2 }`, 2},
+		{`try { [1, 2]["a"] } catch (e) { e["message"] }`, "index operator not supported: ARRAY"},
+		{`try { [1, 2][-5]; "ok" } catch (e) { e["message"] }`, "ok"},
+		{`let f = fn(a) { a }; try { f(1, 2) } catch (e) { e["message"] }`, "wrong number of arguments to f. want=1, got=2"},
+		{`try { len(1) } catch (e) { e["message"] }`, "argument to `len` not supported, got INTEGER"},
+		{`let inner = fn() { 1 + "a" }; let outer = fn() { inner() + 1 }; try { outer() } catch (e) { len(e["stack"]) }`, 3},
+		{`let inner = fn() { 1 + "a" }; let outer = fn() { inner() + 1 }; try { outer() } catch (e) { "${e["stack"]}" }`, "[inner at 1:20, outer at 1:50, <main> at 1:71]"},
+		{`let f = fn() { throw "x" }; let g = fn() { try { f() } catch (e) { len(e["stack"]) } }; g()`, 3},
+		{`try { throw "x" } catch (e) { "${e["stack"]}" }`, "[<main> at 1:7]"},
+		{`let f = fn() { throw "deep" }; let g = fn() { let x = try { f() } catch (e) { e["message"] }; x + "!" }; g()`, "deep!"},
+		{`let a = 0; try { a = 1 } finally { a = a + 10 }; a`, 11},
+		{`let a = 0; try { throw "x" } catch { a = 1 } finally { a = a + 10 }; a`, 11},
+		{`let a = 0; let r = try { try { throw "x" } finally { a = 5 } } catch (e) { e["message"] }; r + "${a}"`, "x5"},
+		{`try { try { throw "in" } catch (e) { throw e } } catch (e) { e["message"] }`, "in"},
+		{`let a = 0; try { try { throw "x" } catch { throw "y" } finally { a = 1 } } catch (e) { e["message"] + "${a}" }`, "y1"},
+		{`let f = fn() { try { return 1 } finally { puts("") }; 2 }; f()`, 1},
+		{`let n = 0; let i = 0; while (i < 3) { i = i + 1; try { if (i == 2) { continue }; n = n + i } finally { n = n + 10 } }; n`, 34},
+		{`let i = 0; while (true) { try { break } finally { i = 7 } }; try { throw "after" } catch (e) { e["message"] + "${i}" }`, "after7"},
+	}
+	runVMTests(t, tests)
+}
+
+func TestUncaughtErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		line     int
+	}{
+		{"let f = fn() {\n  throw \"boom\"\n};\nf()", "uncaught error: boom", 2},
+		{"try { 1 } catch (e) { 2 };\nlen(1)", "argument to `len` not supported, got INTEGER", 2},
+		{"let f = fn() { try { 1 } finally { 2 } };\nf() + \"a\"", "Unsupported types for binary operation: INTEGER, STRING", 2},
+		{"let f = fn() { f() + 1 };\nf()", "maximum call depth exceeded", 1},
+		{"let x = 0;\n10 / x", "division by zero", 2},
+		{"spawn fn() {\n  throw \"boom\"\n};\n1", "boom", 2},
+		{"let c = channel();\nreceive(c)", "deadlock: every task is blocked", 2},
+		{"spawn fn() {\n  receive(channel())\n};\n1", "deadlock: every task is blocked", 2},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		if err := comp.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		err := New(mutil.EncryptByteCode(comp.ByteCode())).Run()
+		diag, ok := err.(*errrs.Diagnostic)
+		if !ok {
+			t.Fatalf("VM error is not a diagnostic. got=%T (%v)", err, err)
+		}
+		if diag.Msg != tt.expected {
+			t.Errorf("wrong error message. want=%q, got=%q", tt.expected, diag.Msg)
+		}
+		if diag.Span.Start.Line != tt.line {
+			t.Errorf("wrong error line for %q. want=%d, got=%d", tt.input, tt.line, diag.Span.Start.Line)
+		}
+	}
+}
+
+func TestStructs(t *testing.T) {
+	tests := []vmTestCase{
+		{`struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x + p.y`, 3},
+		{`struct Point { x, y }; let p = Point{y: 2, x: 1}; "${p}"`, "Point{x: 1, y: 2}"},
+		{`struct Point { x, y }; Point{x: 1}.y`, global.Null},
+		{`struct Point { x, y }; let p = Point{x: 1, y: 2}; p.x = 10; p.y += 5; [p.x, p.y]`, []int{10, 7}},
+		{`struct Point { x, y }; let p = Point{x: 1, y: 2}; let q = p; q.x = 5; p.x`, 5},
+		{`struct Line { a, b }; struct P { x }; let l = Line{a: P{x: 1}, b: P{x: 2}}; l.b.x = 3; l.a.x + l.b.x`, 4},
+		{`let f = fn() { struct P { v }; P{v: "local"} }; f().v`, "local"},
+		{`struct P { x }; P{x: 1} == P{x: 1}`, true},
+		{`struct P { x }; try { P{z: 1} } catch (e) { e["message"] }`, "P has no field z"},
+		{`struct P { x }; try { P{x: 1}.z } catch (e) { e["message"] }`, "P has no field z"},
+		{`try { let a = [1]; a.x } catch (e) { e["message"] }`, "field access not supported: ARRAY"},
+		{`try { let a = 1; a{x: 1} } catch (e) { e["message"] }`, "not a struct: INTEGER"},
+	}
+	runVMTests(t, tests)
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []vmTestCase{
+		{`match (1) { 1 => "one", 2 => "two", _ => "many" }`, "one"},
+		{`match (5) { 1 => "one", 2 => "two", _ => "many" }`, "many"},
+		{`match ("a") { 1 => "int", "a" => "string", _ => "other" }`, "string"},
+		{`match (1) { "1" => "string", 1.0 => "float", 1 => "int" }`, "int"},
+		{`match (-2) { -2 => true, _ => false }`, true},
+		{`match (99999999999999999999) { 99999999999999999999 => 1, _ => 0 }`, 1},
+		{`match (99999999999999999998 + 1) { 99999999999999999998 => 1, 99999999999999999999 => 2, _ => 0 }`, 2},
+		{`match (-99999999999999999999) { 99999999999999999999 => 1, -99999999999999999999 => 2, _ => 0 }`, 2},
+		{`match (-9223372036854775807 - 1) { -9223372036854775808 => 1, _ => 0 }`, 1},
+		{`match (true) { false => 0, true => 1 }`, 1},
+		{`match ([1, 2]) { [x] => x, [x, y] => x + y, _ => 0 }`, 3},
+		{`match ([1, [2, 3]]) { [a, [b, c]] => a + b + c, _ => 0 }`, 6},
+		{`match ([1, 2]) { [1, x] => x * 10, _ => 0 }`, 20},
+		{`match ([3, 2]) { [1, x] => x * 10, _ => 0 }`, 0},
+		{`match ({"k": 5, "j": 1}) { {"k": v} => v, _ => 0 }`, 5},
+		{`match ({"j": 1}) { {"k": v} => v, _ => 0 }`, 0},
+		{`match ({"p": [1, 2]}) { {"p": [_, y]} => y }`, 2},
+		{`match (1) { 2 => 2 }`, global.Null},
+		{`match (7) { n => n * 2 }`, 14},
+		{`let x = 1; match ({"a": 2, "b": 3}) { {"a": x, "b": 4} => x, _ => x }`, 1},
+		{`match (3) { 3 => { let a = 1; a + 1 } _ => 0 }`, 2},
+		{`let f = fn(v) { match (v) { [h, t] => h + f(t), _ => 0 } }; f([1, [2, [3, 0]]])`, 6},
+	}
+	runVMTests(t, tests)
+}